@@ -3,9 +3,14 @@ package api
 import (
 	"essy_travel/api/handler"
 	"essy_travel/config"
+	"essy_travel/pkg/auth"
+	"essy_travel/pkg/blob"
+	"essy_travel/pkg/metrics"
+	"essy_travel/pkg/ratelimit"
 	"essy_travel/storage"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	_ "essy_travel/api/docs"
 
@@ -13,33 +18,289 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger" // gin-swagger middleware
 )
 
-func SetUpApi(r *gin.Engine, cfg *config.Config, strg storage.StorageI) {
+// @description The API is served under /v1. The unversioned routes it
+// @description replaces still answer, with a Deprecation header and a Link to
+// @description their successor.
 
-	handler := handler.NewHandler(cfg, strg)
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Type "Bearer" followed by the access token.
+
+// SetUpApi registers the routes. Health checks, metrics, the docs and
+// signing in are open; reads need the reader role unless AUTH_PUBLIC_READS
+// is set, writes the editor role and the administration the admin role.
+// Reads, writes and uploads are rate limited apart.
+func SetUpApi(r *gin.Engine, cfg *config.Config, strg storage.StorageI, files blob.Storage) {
+
+	var tokens *auth.Tokens
+	if len(cfg.JWTSecret) > 0 {
+		tokens = auth.NewTokens(cfg.JWTSecret, cfg.JWTIssuer, cfg.JWTAccessTTL, cfg.JWTRefreshTTL)
+	}
+
+	handler := handler.NewHandler(cfg, strg, files, tokens)
+
+	var (
+		limiter = ratelimit.NewMemory()
+
+		m = middlewares{
+			authenticate: Authenticate(strg, tokens),
+			ipLimit:      rateLimit(cfg, limiter, "ip", cfg.RateLimitIPPerMinute, cfg.RateLimitIPBurst),
+			readLimit:    rateLimit(cfg, limiter, "read", cfg.RateLimitReadPerMinute, cfg.RateLimitReadBurst),
+			writeLimit:   rateLimit(cfg, limiter, "write", cfg.RateLimitWritePerMinute, cfg.RateLimitWriteBurst),
+			uploadLimit:  rateLimit(cfg, limiter, "upload", cfg.RateLimitUploadPerMinute, cfg.RateLimitUploadBurst),
+			publicReads:  cfg.AuthPublicReads,
+		}
+	)
+
+	// Health
+	r.GET("/healthz", handler.Healthz)
+	r.GET("/readyz", handler.Readyz)
+	r.GET("/metrics", gin.WrapH(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})))
+
+	root := newGroups(&r.RouterGroup, m)
+	root.admin.GET("/debug/info", handler.DebugInfo)
+
+	setUpV1(newGroups(r.Group("/v1"), m), handler)
+	setUpDeprecated(root, handler)
+
+	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+}
+
+// middlewares are shared by the groups of every version of the API, so that
+// a client has one rate limit whichever routes it calls.
+type middlewares struct {
+	authenticate gin.HandlerFunc
+	ipLimit      gin.HandlerFunc
+	readLimit    gin.HandlerFunc
+	writeLimit   gin.HandlerFunc
+	uploadLimit  gin.HandlerFunc
+	publicReads  bool
+}
+
+// groups sort the routes under a prefix by who may call them.
+type groups struct {
+	public *gin.RouterGroup
+	read   *gin.RouterGroup
+	signed *gin.RouterGroup
+	write  *gin.RouterGroup
+	upload *gin.RouterGroup
+	admin  *gin.RouterGroup
+
+	m middlewares
+}
+
+// newGroups builds the groups under base. Callers are limited by IP before
+// they are authenticated, so that guessing keys or flooding with bad ones
+// costs a token and not only a database lookup, and then by who they are.
+func newGroups(base *gin.RouterGroup, m middlewares) groups {
+	g := groups{
+		public: base.Group("/", m.writeLimit),
+		read:   base.Group("/", m.ipLimit, m.authenticate, m.readLimit),
+		signed: base.Group("/", m.ipLimit, m.authenticate, Require(auth.RoleReader)),
+		write:  base.Group("/", m.ipLimit, m.authenticate, m.writeLimit, Require(auth.RoleEditor)),
+		upload: base.Group("/", m.ipLimit, m.authenticate, m.uploadLimit, Require(auth.RoleEditor)),
+		admin:  base.Group("/", m.ipLimit, m.authenticate, m.writeLimit, Require(auth.RoleAdmin)),
+
+		m: m,
+	}
+	if !m.publicReads {
+		g.read.Use(Require(auth.RoleReader))
+	}
+
+	return g
+}
+
+// setUpV1 registers the resource oriented routes: collections are plural
+// nouns and the id of a row is in the path.
+func setUpV1(g groups, handler *handler.Handler) {
+
+	// User
+	g.public.POST("/auth/register", handler.Register)
+	g.public.POST("/auth/login", handler.Login)
+	g.public.POST("/auth/refresh", handler.Refresh)
+	g.signed.GET("/users/me", g.m.readLimit, handler.UserMe)
+	g.signed.PUT("/users/me", g.m.writeLimit, handler.UserUpdateMe)
+	g.signed.PUT("/users/me/password", g.m.writeLimit, handler.UserChangePassword)
+
+	// Administration
+	g.admin.POST("/admin/api-keys", handler.CreateApiKey)
+	g.admin.GET("/admin/api-keys/:id", handler.ApiKeyGetById)
+	g.admin.GET("/admin/api-keys", handler.ApiKeyGetList)
+	g.admin.DELETE("/admin/api-keys/:id", handler.ApiKeyRevoke)
+	g.admin.GET("/admin/users/:id", handler.UserGetById)
+	g.admin.GET("/admin/users", handler.UserGetList)
+	g.admin.PUT("/admin/users/:id/role", handler.UserUpdateRole)
+
+	// City
+	g.write.POST("/cities", handler.CreateCity)
+	g.read.GET("/cities/:id", handler.CityGetById)
+	g.read.GET("/cities", handler.CityGetList)
+	g.write.PUT("/cities/:id", handler.CityUpdate)
+	g.write.PATCH("/cities/:id", handler.CityPatch)
+	g.write.DELETE("/cities/:id", handler.CityDelete)
+	g.upload.POST("/cities/import", handler.CityUpload)
+
+	// Country
+	g.write.POST("/countries", handler.CreateCountry)
+	g.read.GET("/countries/:id", handler.CountryGetById)
+	g.read.GET("/countries", handler.CountryGetList)
+	g.write.PUT("/countries/:id", handler.CountryUpdate)
+	g.write.PATCH("/countries/:id", handler.CountryPatch)
+	g.write.DELETE("/countries/:id", handler.CountryDelete)
+	g.upload.POST("/countries/import", handler.CountryUpload)
+
+	// Airport
+	g.write.POST("/airports", handler.CreateAirport)
+	g.read.GET("/airports/:id", handler.AirportGetById)
+	g.read.GET("/airports", handler.AirportGetList)
+	g.write.PUT("/airports/:id", handler.AirportUpdate)
+	g.write.PATCH("/airports/:id", handler.AirportPatch)
+	g.write.DELETE("/airports/:id", handler.AirportDelete)
+	g.upload.POST("/airports/import", handler.AirportUpload)
+	g.read.GET("/airports/:id/destinations", handler.AirportDestinations)
+	g.upload.POST("/airports/:id/image", handler.AirportImageUpload)
+	g.read.GET("/airports/:id/image", handler.AirportImage)
+
+	// Route
+	g.write.POST("/routes", handler.CreateRoute)
+	g.read.GET("/routes/:id", handler.RouteGetById)
+	g.read.GET("/routes", handler.RouteGetList)
+	g.write.PUT("/routes/:id", handler.RouteUpdate)
+	g.write.PATCH("/routes/:id", handler.RoutePatch)
+	g.write.DELETE("/routes/:id", handler.RouteDelete)
+	g.upload.POST("/routes/import", handler.RouteUpload)
+
+	// Airline
+	g.write.POST("/airlines", handler.CreateAirline)
+	g.read.GET("/airlines/:id", handler.AirlineGetById)
+	g.read.GET("/airlines", handler.AirlineGetList)
+	g.write.PUT("/airlines/:id", handler.AirlineUpdate)
+	g.write.PATCH("/airlines/:id", handler.AirlinePatch)
+	g.write.DELETE("/airlines/:id", handler.AirlineDelete)
+	g.upload.POST("/airlines/import", handler.AirlineUpload)
+	g.read.GET("/airlines/:id/airports", handler.AirlineGetAirports)
+	g.write.POST("/airlines/:id/airports", handler.AirlineAddAirport)
+	g.write.DELETE("/airlines/:id/airports/:airport_id", handler.AirlineRemoveAirport)
+
+	// Translation
+	g.write.POST("/translations", handler.CreateTranslation)
+	g.read.GET("/translations/:id", handler.TranslationGetById)
+	g.read.GET("/translations", handler.TranslationGetList)
+	g.write.PUT("/translations/:id", handler.TranslationUpdate)
+	g.write.PATCH("/translations/:id", handler.TranslationPatch)
+	g.write.DELETE("/translations/:id", handler.TranslationDelete)
+
+	// Alias
+	g.write.POST("/aliases", handler.CreateAlias)
+	g.read.GET("/aliases/:id", handler.AliasGetById)
+	g.read.GET("/aliases", handler.AliasGetList)
+	g.write.DELETE("/aliases/:id", handler.AliasDelete)
+}
+
+// setUpDeprecated keeps the routes from before /v1 for the clients still
+// calling them. Updates and deletes take the id in the body there, and the
+// upload routes match any last segment.
+func setUpDeprecated(g groups, handler *handler.Handler) {
+	var d = Deprecated
+
+	// Api key
+	g.admin.POST("/admin/api-key", d("/v1/admin/api-keys"), handler.CreateApiKey)
+	g.admin.GET("/admin/api-key/:id", d("/v1/admin/api-keys/:id"), handler.ApiKeyGetById)
+	g.admin.GET("/admin/api-key", d("/v1/admin/api-keys"), handler.ApiKeyGetList)
+	g.admin.DELETE("/admin/api-key", d("/v1/admin/api-keys"), handler.ApiKeyRevoke)
+
+	// User
+	g.public.POST("/auth/register", d("/v1/auth/register"), handler.Register)
+	g.public.POST("/auth/login", d("/v1/auth/login"), handler.Login)
+	g.public.POST("/auth/refresh", d("/v1/auth/refresh"), handler.Refresh)
+	g.signed.GET("/user/me", g.m.readLimit, d("/v1/users/me"), handler.UserMe)
+	g.signed.PUT("/user/me", g.m.writeLimit, d("/v1/users/me"), handler.UserUpdateMe)
+	g.signed.PUT("/user/me/password", g.m.writeLimit, d("/v1/users/me/password"), handler.UserChangePassword)
+	g.admin.GET("/admin/user/:id", d("/v1/admin/users/:id"), handler.UserGetById)
+	g.admin.GET("/admin/user", d("/v1/admin/users"), handler.UserGetList)
+	g.admin.PUT("/admin/user/role", d("/v1/admin/users"), handler.UserUpdateRole)
 
 	// City ...
-	r.POST("/city", handler.CreateCity)
-	r.GET("/city/:id", handler.CityGetById)
-	r.GET("/city", handler.CityGetList)
-	r.PUT("/city", handler.CityUpdate)
-	r.DELETE("/city", handler.CityDelete)
-	r.POST("/city/:upload", handler.CityUpload)
+	g.write.POST("/city", d("/v1/cities"), handler.CreateCity)
+	g.read.GET("/city/:id", d("/v1/cities/:id"), handler.CityGetById)
+	g.read.GET("/city", d("/v1/cities"), handler.CityGetList)
+	g.write.PUT("/city", d("/v1/cities"), handler.CityUpdate)
+	g.write.PATCH("/city/:id", d("/v1/cities/:id"), handler.CityPatch)
+	g.write.DELETE("/city", d("/v1/cities"), handler.CityDelete)
+	g.upload.POST("/city/:upload", d("/v1/cities/import"), handler.CityUpload)
 
 	// Country
-	r.POST("/country", handler.CreateCountry)
-	r.GET("/country/:id", handler.CountryGetById)
-	r.GET("/country", handler.CountryGetList)
-	r.PUT("/country", handler.CountryUpdate)
-	r.DELETE("/country", handler.CountryDelete)
-	r.POST("/country/:upload", handler.CountryUpload)
+	g.write.POST("/country", d("/v1/countries"), handler.CreateCountry)
+	g.read.GET("/country/:id", d("/v1/countries/:id"), handler.CountryGetById)
+	g.read.GET("/country", d("/v1/countries"), handler.CountryGetList)
+	g.write.PUT("/country", d("/v1/countries"), handler.CountryUpdate)
+	g.write.PATCH("/country/:id", d("/v1/countries/:id"), handler.CountryPatch)
+	g.write.DELETE("/country", d("/v1/countries"), handler.CountryDelete)
+	g.upload.POST("/country/:upload", d("/v1/countries/import"), handler.CountryUpload)
 
 	// Airport
-	r.POST("/airport", handler.CreateAirport)
-	r.GET("/airport/:id", handler.AirportGetById)
-	r.GET("/airport", handler.AirportGetList)
-	r.PUT("/airport", handler.AirportUpdate)
-	r.DELETE("/airport", handler.AirportDelete)
-	r.POST("/airport/:upload", handler.AirportUpload)
+	g.write.POST("/airport", d("/v1/airports"), handler.CreateAirport)
+	g.read.GET("/airport/:id", d("/v1/airports/:id"), handler.AirportGetById)
+	g.read.GET("/airport", d("/v1/airports"), handler.AirportGetList)
+	g.write.PUT("/airport", d("/v1/airports"), handler.AirportUpdate)
+	g.write.PATCH("/airport/:id", d("/v1/airports/:id"), handler.AirportPatch)
+	g.write.DELETE("/airport", d("/v1/airports"), handler.AirportDelete)
+	// gin allows a single wildcard name per path segment, so the upload
+	// route shares ":id" with the image routes; the segment is not read.
+	g.upload.POST("/airport/:id", d("/v1/airports/import"), handler.AirportUpload)
+	g.read.GET("/airport/:id/destinations", d("/v1/airports/:id/destinations"), handler.AirportDestinations)
+	g.upload.POST("/airport/:id/image", d("/v1/airports/:id/image"), handler.AirportImageUpload)
+	g.read.GET("/airport/:id/image", d("/v1/airports/:id/image"), handler.AirportImage)
 
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+	// Route
+	g.write.POST("/route", d("/v1/routes"), handler.CreateRoute)
+	g.read.GET("/route/:id", d("/v1/routes/:id"), handler.RouteGetById)
+	g.read.GET("/route", d("/v1/routes"), handler.RouteGetList)
+	g.write.PUT("/route", d("/v1/routes"), handler.RouteUpdate)
+	g.write.PATCH("/route/:id", d("/v1/routes/:id"), handler.RoutePatch)
+	g.write.DELETE("/route", d("/v1/routes"), handler.RouteDelete)
+	g.upload.POST("/route/:upload", d("/v1/routes/import"), handler.RouteUpload)
+
+	// Airline
+	g.write.POST("/airline", d("/v1/airlines"), handler.CreateAirline)
+	g.read.GET("/airline/:id", d("/v1/airlines/:id"), handler.AirlineGetById)
+	g.read.GET("/airline", d("/v1/airlines"), handler.AirlineGetList)
+	g.write.PUT("/airline", d("/v1/airlines"), handler.AirlineUpdate)
+	g.write.PATCH("/airline/:id", d("/v1/airlines/:id"), handler.AirlinePatch)
+	g.write.DELETE("/airline", d("/v1/airlines"), handler.AirlineDelete)
+	g.upload.POST("/airline/:upload", d("/v1/airlines/import"), handler.AirlineUpload)
+	g.read.GET("/airline/:id/airports", d("/v1/airlines/:id/airports"), handler.AirlineGetAirports)
+	g.write.POST("/airline/airport", d("/v1/airlines"), handler.AirlineAddAirport)
+	g.write.DELETE("/airline/airport", d("/v1/airlines"), handler.AirlineRemoveAirport)
+
+	// Translation
+	g.write.POST("/translation", d("/v1/translations"), handler.CreateTranslation)
+	g.read.GET("/translation/:id", d("/v1/translations/:id"), handler.TranslationGetById)
+	g.read.GET("/translation", d("/v1/translations"), handler.TranslationGetList)
+	g.write.PUT("/translation", d("/v1/translations"), handler.TranslationUpdate)
+	g.write.PATCH("/translation/:id", d("/v1/translations/:id"), handler.TranslationPatch)
+	g.write.DELETE("/translation", d("/v1/translations"), handler.TranslationDelete)
+
+	// Alias
+	g.write.POST("/alias", d("/v1/aliases"), handler.CreateAlias)
+	g.read.GET("/alias/:id", d("/v1/aliases/:id"), handler.AliasGetById)
+	g.read.GET("/alias", d("/v1/aliases"), handler.AliasGetList)
+	g.write.DELETE("/alias", d("/v1/aliases"), handler.AliasDelete)
+}
+
+// rateLimit returns the middleware limiting a class of requests to
+// perMinute, or one that lets everything through when the limit is off.
+func rateLimit(cfg *config.Config, limiter ratelimit.Limiter, class string, perMinute float64, burst int) gin.HandlerFunc {
+	if !cfg.RateLimitEnabled || perMinute == 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return RateLimit(limiter, class, ratelimit.PerMinute(perMinute, burst))
 }
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// encoder is a pooled gzip or brotli writer.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoders = map[string]*sync.Pool{
+	"br": {New: func() interface{} {
+		return brotli.NewWriterLevel(io.Discard, brotli.DefaultCompression)
+	}},
+	"gzip": {New: func() interface{} {
+		return gzip.NewWriter(io.Discard)
+	}},
+}
+
+// Compress encodes JSON responses of at least minSize bytes with brotli or
+// gzip, whichever the client prefers, brotli on a tie. Smaller responses
+// are not worth it and other content, such as images, is already
+// compressed.
+func Compress(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := acceptEncoding(c.GetHeader("Accept-Encoding"))
+		if len(encoding) == 0 || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: minSize}
+		c.Writer = w
+		// Recovery writes its response after this returns, so the writer
+		// is put back whatever happens.
+		defer func() {
+			w.close()
+			c.Writer = w.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// acceptEncoding picks br or gzip from an Accept-Encoding header, or ""
+// when the client takes neither.
+func acceptEncoding(header string) string {
+	var (
+		best     string
+		bestQ    float64
+		wildcard = -1.0
+		q        = map[string]float64{}
+	)
+
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		weight := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			weight = f
+		}
+
+		if name == "*" {
+			wildcard = weight
+		} else {
+			q[name] = weight
+		}
+	}
+
+	for _, name := range []string{"br", "gzip"} {
+		weight, ok := q[name]
+		if !ok {
+			weight = wildcard
+		}
+		if weight > bestQ {
+			best, bestQ = name, weight
+		}
+	}
+
+	return best
+}
+
+// compressWriter holds back the start of the body until it knows whether
+// the response is worth compressing, then writes it either through an
+// encoder or as it is.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+
+	buf     []byte
+	decided bool
+	enc     encoder
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, p...)
+		if len(w.buf) < w.minSize {
+			return len(p), nil
+		}
+
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	if w.enc != nil {
+		return w.enc.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide starts the body, compressed when it is JSON. The headers may
+// still change, gin only sends them with the first byte of the body.
+func (w *compressWriter) decide() error {
+	w.decided = true
+
+	header := w.Header()
+	if strings.HasPrefix(header.Get("Content-Type"), "application/json") {
+		header.Add("Vary", "Accept-Encoding")
+
+		if len(w.buf) >= w.minSize && len(header.Get("Content-Encoding")) == 0 {
+			header.Set("Content-Encoding", w.encoding)
+			header.Del("Content-Length")
+			// The bytes differ from the uncompressed ones, so the tag of
+			// the response only matches weakly.
+			if etag := header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+				header.Set("ETag", "W/"+etag)
+			}
+
+			w.enc = encoders[w.encoding].Get().(encoder)
+			w.enc.Reset(w.ResponseWriter)
+		}
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if w.enc != nil {
+		_, err := w.enc.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// close writes what is held back and ends the encoding.
+func (w *compressWriter) close() {
+	if !w.decided {
+		if len(w.buf) == 0 {
+			return
+		}
+		_ = w.decide()
+	}
+
+	if w.enc != nil {
+		_ = w.enc.Close()
+		w.enc.Reset(io.Discard)
+		encoders[w.encoding].Put(w.enc)
+		w.enc = nil
+	}
+}
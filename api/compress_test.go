@@ -0,0 +1,131 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAcceptEncoding(t *testing.T) {
+	for _, tt := range []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"GZIP", "gzip"},
+		{"br", "br"},
+		{"gzip, br", "br"},
+		{"gzip;q=0.8, br;q=0.8", "br"},
+		{"gzip;q=1, br;q=0.5", "gzip"},
+		{"gzip;q=0", ""},
+		{"gzip;q=0, br", "br"},
+		{"gzip;q=bad", ""},
+		{"*", "br"},
+		{"*;q=0.5, gzip", "gzip"},
+		{"*, br;q=0", "gzip"},
+		{"*;q=0", ""},
+		{"deflate, *;q=0", ""},
+	} {
+		if got := acceptEncoding(tt.header); got != tt.want {
+			t.Errorf("acceptEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	big := strings.Repeat("essy travel ", 100)
+
+	router := gin.New()
+	router.Use(Recovery(), Compress(256))
+	router.GET("/big", func(c *gin.Context) {
+		c.Header("ETag", `"tag"`)
+		c.JSON(http.StatusOK, big)
+	})
+	router.GET("/small", func(c *gin.Context) {
+		c.Header("ETag", `"tag"`)
+		c.JSON(http.StatusOK, "small")
+	})
+	router.GET("/image", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", []byte(big))
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	for _, tt := range []struct {
+		name     string
+		path     string
+		accept   string
+		encoding string
+		etag     string
+		vary     bool
+		body     string
+	}{
+		{"gzip", "/big", "gzip", "gzip", `W/"tag"`, true, `"` + big + `"`},
+		{"brotli", "/big", "gzip, br", "br", `W/"tag"`, true, `"` + big + `"`},
+		{"not accepted", "/big", "identity", "", `"tag"`, false, `"` + big + `"`},
+		{"below min size", "/small", "gzip", "", `"tag"`, true, `"small"`},
+		{"not json", "/image", "gzip", "", "", false, big},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Accept-Encoding", tt.accept)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d, want 200", w.Code)
+			}
+			if got := w.Header().Get("Content-Encoding"); got != tt.encoding {
+				t.Errorf("Content-Encoding %q, want %q", got, tt.encoding)
+			}
+			if got := w.Header().Get("ETag"); got != tt.etag {
+				t.Errorf("ETag %q, want %q", got, tt.etag)
+			}
+			if got := w.Header().Get("Vary") == "Accept-Encoding"; got != tt.vary {
+				t.Errorf("Vary %q, want Accept-Encoding: %v", w.Header().Get("Vary"), tt.vary)
+			}
+
+			var body io.Reader = w.Body
+			switch tt.encoding {
+			case "gzip":
+				r, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = r
+			case "br":
+				body = brotli.NewReader(w.Body)
+			}
+
+			got, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.body {
+				t.Errorf("body %q, want %q", got, tt.body)
+			}
+		})
+	}
+
+	t.Run("panic", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError || len(w.Header().Get("Content-Encoding")) > 0 {
+			t.Errorf("status %d, Content-Encoding %q; want 500 and none", w.Code, w.Header().Get("Content-Encoding"))
+		}
+	})
+}
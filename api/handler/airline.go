@@ -0,0 +1,396 @@
+package handler
+
+import (
+	"essy_travel/models"
+	"essy_travel/pkg/helpers"
+	"essy_travel/pkg/metrics"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAirline godoc
+// @ID create_airline
+// @Router /v1/airlines [POST]
+// @Summary Create Airline
+// @Description Create Airline
+// @Tags Airline
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param object body models.CreateAirline true "CreateAirlineRequestBody"
+// @Success 200 {object} Response{data=models.Airline} "AirlineBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) CreateAirline(c *gin.Context) {
+	var airline = models.CreateAirline{}
+	err := c.ShouldBindJSON(&airline)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	resp, err := h.strg.Airline().Create(c.Request.Context(), airline)
+	if err != nil {
+		handleResponse(c, storageStatus(err, http.StatusBadRequest), "Does not create"+err.Error())
+		return
+	}
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// GetByIdAirline godoc
+// @ID get_by_id_airline
+// @Router /v1/airlines/{id} [GET]
+// @Summary Get By Id Airline
+// @Description Get By Id Airline
+// @Tags Airline
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
+// @Success 200 {object} Response{data=models.Airline} "GetByIdAirlineResponseBody"
+// @Header 200 {string} ETag "Tag of the data, for If-None-Match"
+// @Response 304 "Not Modified"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AirlineGetById(c *gin.Context) {
+	var guid = c.Param("id")
+	if !helpers.IsValidUUID(guid) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	resp, err := h.strg.Airline().GetById(c.Request.Context(), models.AirlinePrimaryKey{Guid: guid})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Airline does not exist: "+err.Error())
+		return
+	}
+
+	handleRead(c, resp)
+}
+
+// GetListAirline godoc
+// @ID get_list_airline
+// @Router /v1/airlines [GET]
+// @Summary Get List Airline
+// @Description Get List Airline
+// @Tags Airline
+// @Accept json
+// @Produce json
+// @Param limit query number false "limit"
+// @Param offset query number false "offset"
+// @Param search query string false "search by title, IATA or ICAO code"
+// @Param country_id query string false "country_id"
+// @Param active query boolean false "only active airlines"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
+// @Success 200 {object} Response{data=models.GetListAirlineResponse} "GetListAirlineResponseBody"
+// @Header 200 {string} ETag "Tag of the data, for If-None-Match"
+// @Response 304 "Not Modified"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AirlineGetList(c *gin.Context) {
+	offset, err := h.getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, 400, "invalid offset")
+		return
+	}
+
+	limit, err := h.getIntegerOrDefaultValue(c.Query("limit"), 0)
+	if err != nil {
+		handleResponse(c, 400, "invalid limit")
+		return
+	}
+
+	countryId := c.Query("country_id")
+	if len(countryId) > 0 && !helpers.IsValidUUID(countryId) {
+		handleResponse(c, http.StatusBadRequest, "country_id is not uuid")
+		return
+	}
+
+	resp, err := h.strg.Airline().GetList(c.Request.Context(), models.GetListAirlineRequest{
+		Offset:     int(offset),
+		Limit:      int(limit),
+		Search:     c.Query("search"),
+		CountryId:  countryId,
+		OnlyActive: c.Query("active") == "true",
+	})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Airline does not exist: "+err.Error())
+		return
+	}
+
+	handleRead(c, resp)
+}
+
+// UpdateAirline godoc
+// @ID update_airline
+// @Router /v1/airlines/{id} [PUT]
+// @Summary Update Airline
+// @Description Update Airline
+// @Tags Airline
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param object body models.UpdateAirline true "UpdateAirlineRequestBody"
+// @Success 200 {object} Response{data=models.Airline} "AirlineBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AirlineUpdate(c *gin.Context) {
+	var airline = models.UpdateAirline{}
+	err := c.ShouldBindJSON(&airline)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Error while json decoding"+err.Error())
+		return
+	}
+
+	err = pathId(c, &airline.Guid)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.updateAirline(c, airline)
+}
+
+// updateAirline stores airline for AirlineUpdate and AirlinePatch.
+func (h *Handler) updateAirline(c *gin.Context, airline models.UpdateAirline) {
+	resp, err := h.strg.Airline().Update(c.Request.Context(), airline)
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Airline does not update: "+err.Error())
+		return
+	}
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// PatchAirline godoc
+// @ID patch_airline
+// @Router /v1/airlines/{id} [PATCH]
+// @Summary Patch Airline
+// @Description Change only the fields named by the JSON merge patch (RFC 7396) in the body; null clears a field.
+// @Tags Airline
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "id"
+// @Param object body models.UpdateAirline true "PatchAirlineRequestBody"
+// @Success 200 {object} Response{data=models.Airline} "AirlineBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 404 {object} Response{data=string} "Not Found"
+// @Response 415 {object} Response{data=string} "Unsupported Media Type"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AirlinePatch(c *gin.Context) {
+	var guid = c.Param("id")
+	if !helpers.IsValidUUID(guid) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	current, err := h.strg.Airline().GetById(c.Request.Context(), models.AirlinePrimaryKey{Guid: guid})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Airline does not exist: "+err.Error())
+		return
+	}
+
+	var airline = models.UpdateAirline{}
+	status, err := mergePatch(c, current, &airline)
+	if err != nil {
+		handleResponse(c, status, "Error while applying patch: "+err.Error())
+		return
+	}
+	airline.Guid = guid
+
+	h.updateAirline(c, airline)
+}
+
+// DeleteAirline godoc
+// @ID delete_airline
+// @Router /v1/airlines/{id} [DELETE]
+// @Summary Delete Airline
+// @Description Delete Airline
+// @Tags Airline
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=models.AirlinePrimaryKey} "AirlineBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AirlineDelete(c *gin.Context) {
+	var airline = models.AirlinePrimaryKey{}
+	err := bindId(c, &airline, &airline.Guid)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = h.strg.Airline().Delete(c.Request.Context(), airline)
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Airline does not delete: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusAccepted, "Deleted:")
+}
+
+// UploadAirline godoc
+// @ID upload_airline
+// @Router /v1/airlines/import [POST]
+// @Summary Upload airline
+// @Description Upload Airline
+// @Tags Airline
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param  	file  formData file true "File"
+// @Success 200 {object} Response{data=[]models.CreateAirline} "AirlineBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 413 {object} Response{data=string} "File Too Large"
+// @Response 415 {object} Response{data=string} "Unsupported File Type"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AirlineUpload(c *gin.Context) {
+	var airlines = []models.CreateAirline{}
+	status, err := h.readJSONUpload(c, "airline", &airlines)
+	if err != nil {
+		handleResponse(c, status, err.Error())
+		return
+	}
+
+	err = h.strg.Airline().Upload(c.Request.Context(), airlines)
+	if err != nil {
+		metrics.UploadFailures.WithLabelValues("airline").Inc()
+		handleResponse(c, storageStatus(err, http.StatusNotAcceptable), err.Error())
+		return
+	}
+	metrics.UploadRows.WithLabelValues("airline").Add(float64(len(airlines)))
+	handleResponse(c, http.StatusCreated, nil)
+}
+
+// AddAirlineAirport godoc
+// @ID add_airline_airport
+// @Router /v1/airlines/{id}/airports [POST]
+// @Summary Add Airline Hub Or Base
+// @Description Link an airport to an airline as a hub or base
+// @Tags Airline
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param object body models.CreateAirlineAirport true "CreateAirlineAirportRequestBody"
+// @Success 200 {object} Response{data=models.GetListAirlineAirportResponse} "AirlineAirportBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AirlineAddAirport(c *gin.Context) {
+	var link = models.CreateAirlineAirport{}
+	err := c.ShouldBindJSON(&link)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Error while json decoding"+err.Error())
+		return
+	}
+	err = pathId(c, &link.AirlineId)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !helpers.IsValidUUID(link.AirportId) {
+		handleResponse(c, http.StatusBadRequest, "airport_id must be uuid")
+		return
+	}
+
+	if len(link.Type) == 0 {
+		link.Type = "hub"
+	}
+	if link.Type != "hub" && link.Type != "base" {
+		handleResponse(c, http.StatusBadRequest, "type must be hub or base")
+		return
+	}
+
+	err = h.strg.Airline().AddAirport(c.Request.Context(), link)
+	if err != nil {
+		handleResponse(c, storageStatus(err, http.StatusBadRequest), "Does not create"+err.Error())
+		return
+	}
+
+	resp, err := h.strg.Airline().GetAirports(c.Request.Context(), models.AirlinePrimaryKey{Guid: link.AirlineId})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Airline airports does not exist: "+err.Error())
+		return
+	}
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// RemoveAirlineAirport godoc
+// @ID remove_airline_airport
+// @Router /v1/airlines/{id}/airports/{airport_id} [DELETE]
+// @Summary Remove Airline Hub Or Base
+// @Description Unlink an airport from an airline
+// @Tags Airline
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param airport_id path string true "airport_id"
+// @Success 200 {object} Response{data=string} "AirlineAirportBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AirlineRemoveAirport(c *gin.Context) {
+	var link = models.AirlineAirportPrimaryKey{}
+	err := bindId(c, &link, &link.AirlineId)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if airportId := c.Param("airport_id"); len(airportId) > 0 {
+		link.AirportId = airportId
+	}
+	if !helpers.IsValidUUID(link.AirportId) {
+		handleResponse(c, http.StatusBadRequest, "airport_id must be uuid")
+		return
+	}
+
+	err = h.strg.Airline().RemoveAirport(c.Request.Context(), link)
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Airline airport does not delete: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusAccepted, "Deleted:")
+}
+
+// GetAirlineAirports godoc
+// @ID get_airline_airports
+// @Router /v1/airlines/{id}/airports [GET]
+// @Summary Get Airline Hubs And Bases
+// @Description Get Airline Hubs And Bases
+// @Tags Airline
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
+// @Success 200 {object} Response{data=models.GetListAirlineAirportResponse} "GetListAirlineAirportResponseBody"
+// @Header 200 {string} ETag "Tag of the data, for If-None-Match"
+// @Response 304 "Not Modified"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AirlineGetAirports(c *gin.Context) {
+	var guid = c.Param("id")
+	if !helpers.IsValidUUID(guid) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	resp, err := h.strg.Airline().GetAirports(c.Request.Context(), models.AirlinePrimaryKey{Guid: guid})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Airline airports does not exist: "+err.Error())
+		return
+	}
+
+	handleRead(c, resp)
+}
@@ -1,21 +1,24 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
 	"essy_travel/models"
 	"essy_travel/pkg/helpers"
+	"essy_travel/pkg/metrics"
+	"essy_travel/storage"
 	"net/http"
-	"os"
 
 	"github.com/gin-gonic/gin"
 )
 
 // CreateAirport godoc
 // @ID create_airport
-// @Router /airport [POST]
+// @Router /v1/airports [POST]
 // @Summary Create Airport
 // @Description Create Airport
 // @Tags Airport
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param object body models.CreateAirport true "CreateAirportRequestBody"
@@ -30,9 +33,9 @@ func (h *Handler) CreateAirport(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.strg.Airport().Create(Airport)
+	resp, err := h.strg.Airport().Create(c.Request.Context(), Airport)
 	if err != nil {
-		handleResponse(c, http.StatusBadRequest, "Does not create"+err.Error())
+		handleResponse(c, storageStatus(err, http.StatusBadRequest), "Does not create"+err.Error())
 		return
 	}
 	handleResponse(c, http.StatusCreated, resp)
@@ -40,14 +43,18 @@ func (h *Handler) CreateAirport(c *gin.Context) {
 
 // GetByIdAirport godoc
 // @ID get_by_id_airport
-// @Router /airport/{id} [GET]
+// @Router /v1/airports/{id} [GET]
 // @Summary Get By Id Airport
 // @Description Get By Id Airport
 // @Tags Airport
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
+// @Param Accept-Language header string false "Accept-Language"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
 // @Success 200 {object} Response{data=models.Airport} "GetListAirportResponseBody"
+// @Header 200 {string} ETag "Tag of the data, for If-None-Match"
+// @Response 304 "Not Modified"
 // @Response 400 {object} Response{data=string} "Invalid Argument"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) AirportGetById(c *gin.Context) {
@@ -57,18 +64,22 @@ func (h *Handler) AirportGetById(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.strg.Airport().GetById(models.AirportPrimaryKey{Guid: guid})
+	resp, err := h.strg.Airport().GetById(c.Request.Context(), models.AirportPrimaryKey{Guid: guid})
 	if err != nil {
-		handleResponse(c, 500, "Airport does not exist: "+err.Error())
+		handleResponse(c, storageStatus(err, 500), "Airport does not exist: "+err.Error())
 		return
 	}
 
-	handleResponse(c, http.StatusOK, resp)
+	if title, ok := h.localize(c, "airport", resp.Guid)[resp.Guid]; ok {
+		resp.Title = title
+	}
+
+	handleVersion(c, resp.Version, resp)
 }
 
 // GetListAirport godoc
 // @ID get_list_airport
-// @Router /airport [GET]
+// @Router /v1/airports [GET]
 // @Summary Get List Airport
 // @Description Get List Airport
 // @Tags Airport
@@ -76,7 +87,12 @@ func (h *Handler) AirportGetById(c *gin.Context) {
 // @Produce json
 // @Param limit query number false "limit"
 // @Param offset query number false "offset"
+// @Param search query string false "search by title, code or alias"
+// @Param Accept-Language header string false "Accept-Language"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
 // @Success 200 {object} Response{data=models.GetListAirportResponse} "GetListAirportResponseBody"
+// @Header 200 {string} ETag "Tag of the data, for If-None-Match"
+// @Response 304 "Not Modified"
 // @Response 400 {object} Response{data=string} "Invalid Argument"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) AirportGetList(c *gin.Context) {
@@ -92,29 +108,49 @@ func (h *Handler) AirportGetList(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.strg.Airport().GetList(models.GetListAirportRequest{
+	resp, err := h.strg.Airport().GetList(c.Request.Context(), models.GetListAirportRequest{
 		Offset: int(offset),
 		Limit:  int(limit),
+		Search: c.Query("search"),
 	})
 	if err != nil {
-		handleResponse(c, 500, "Airport does not exist: "+err.Error())
+		handleResponse(c, storageStatus(err, 500), "Airport does not exist: "+err.Error())
 		return
 	}
 
-	handleResponse(c, http.StatusOK, resp)
+	var ids = make([]string, 0, len(resp.Airports))
+	for _, v := range resp.Airports {
+		ids = append(ids, v.Guid)
+	}
+
+	titles := h.localize(c, "airport", ids...)
+	for i, v := range resp.Airports {
+		if title, ok := titles[v.Guid]; ok {
+			resp.Airports[i].Title = title
+		}
+	}
+
+	handleRead(c, resp)
 }
 
 // UpdateAirport godoc
 // @ID update_airport
-// @Router /airport [PUT]
+// @Router /v1/airports/{id} [PUT]
 // @Summary Update Airport
 // @Description Update Airport
 // @Tags Airport
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Accept json
 // @Produce json
+// @Param id path string true "id"
 // @Param object body models.UpdateAirport true "UpdateAirportRequestBody"
+// @Param If-Match header string false "ETag of the record as last read"
 // @Success 200 {object} Response{data=models.Airport} "AirportBody"
+// @Header 200 {string} ETag "Tag of the stored record, for If-Match"
 // @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 409 {object} Response{data=models.Airport} "Changed Since Read, by Version"
+// @Response 412 {object} Response{data=models.Airport} "Changed Since Read, by If-Match"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) AirportUpdate(c *gin.Context) {
 	var Airport = models.UpdateAirport{}
@@ -124,83 +160,151 @@ func (h *Handler) AirportUpdate(c *gin.Context) {
 		handleResponse(c, http.StatusBadRequest, "Error while json decoding"+err.Error())
 		return
 	}
-	_, err = h.strg.Airport().Update(Airport)
+
+	err = pathId(c, &Airport.Guid)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.updateAirport(c, Airport)
+}
+
+// updateAirport stores airport for AirportUpdate and AirportPatch.
+func (h *Handler) updateAirport(c *gin.Context, airport models.UpdateAirport) {
+	fromHeader, err := ifMatch(c, &airport.Version)
+	if err != nil {
+		handleResponse(c, http.StatusPreconditionFailed, err.Error())
+		return
+	}
+
+	resp, err := h.strg.Airport().Update(c.Request.Context(), airport)
+	if errors.Is(err, storage.ErrConflict) {
+		handleConflict(c, fromHeader, resp)
+		return
+	}
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Airport does not update: "+err.Error())
+		return
+	}
+
+	handleUpdated(c, resp.Version, resp)
+}
+
+// PatchAirport godoc
+// @ID patch_airport
+// @Router /v1/airports/{id} [PATCH]
+// @Summary Patch Airport
+// @Description Change only the fields named by the JSON merge patch (RFC 7396) in the body; null clears a field. Without version or If-Match the patch applies to the airport as read just before, and fails with 409 if it changed since.
+// @Tags Airport
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "id"
+// @Param object body models.UpdateAirport true "PatchAirportRequestBody"
+// @Param If-Match header string false "ETag of the record as last read"
+// @Success 200 {object} Response{data=models.Airport} "AirportBody"
+// @Header 200 {string} ETag "Tag of the stored record, for If-Match"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 404 {object} Response{data=string} "Not Found"
+// @Response 409 {object} Response{data=models.Airport} "Changed Since Read, by Version"
+// @Response 412 {object} Response{data=models.Airport} "Changed Since Read, by If-Match"
+// @Response 415 {object} Response{data=string} "Unsupported Media Type"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AirportPatch(c *gin.Context) {
+	var guid = c.Param("id")
+	if !helpers.IsValidUUID(guid) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	current, err := h.strg.Airport().GetById(c.Request.Context(), models.AirportPrimaryKey{Guid: guid})
 	if err != nil {
-		handleResponse(c, 500, "Airport does not update: "+err.Error())
+		handleResponse(c, storageStatus(err, 500), "Airport does not exist: "+err.Error())
 		return
 	}
 
-	handleResponse(c, http.StatusAccepted, "Updated")
+	var airport = models.UpdateAirport{}
+	status, err := mergePatch(c, current, &airport)
+	if err != nil {
+		handleResponse(c, status, "Error while applying patch: "+err.Error())
+		return
+	}
+	airport.Guid = guid
+
+	h.updateAirport(c, airport)
 }
 
 // DeleteAirport godoc
 // @ID delete_airport
-// @Router /airport [DELETE]
+// @Router /v1/airports/{id} [DELETE]
 // @Summary Delete Airport
 // @Description Delete Airport
 // @Tags Airport
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param object body models.AirportPrimaryKey true "DeleteAirportRequestBody"
+// @Param id path string true "id"
 // @Success 200 {object} Response{data=models.UpdateAirport} "AirportBody"
 // @Response 400 {object} Response{data=string} "Invalid Argument"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) AirportDelete(c *gin.Context) {
 	var Airport = models.AirportPrimaryKey{}
-	err := c.ShouldBindJSON(&Airport)
+	err := bindId(c, &Airport, &Airport.Guid)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
 
+	current, err := h.strg.Airport().GetById(c.Request.Context(), Airport)
 	if err != nil {
-		handleResponse(c, http.StatusBadRequest, "Error while json decoding"+err.Error())
+		handleResponse(c, storageStatus(err, 500), "Airport does not exist: "+err.Error())
 		return
 	}
-	_, err = h.strg.Airport().Delete(Airport)
+
+	_, err = h.strg.Airport().Delete(c.Request.Context(), Airport)
 	if err != nil {
-		handleResponse(c, 500, "Airport does not delete: "+err.Error())
+		handleResponse(c, storageStatus(err, 500), "Airport does not delete: "+err.Error())
 		return
 	}
 
+	h.removeImage(c.Request.Context(), current.Guid, current.Image)
+
 	handleResponse(c, http.StatusAccepted, "Deleted:")
 }
 
 // UploadAirport godoc
 // @ID upload_airport
-// @Router /airport/:upload [POST]
+// @Router /v1/airports/import [POST]
 // @Summary Upload airport
 // @Description Upload Airport
 // @Tags Airport
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param  	file  formData file true "File"
 // @Success 200 {object} Response{data=[]models.CreateAirport} "AirportBody"
 // @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 413 {object} Response{data=string} "File Too Large"
+// @Response 415 {object} Response{data=string} "Unsupported File Type"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) AirportUpload(c *gin.Context) {
 	var airports = []models.CreateAirport{}
-	file, err := c.FormFile("file")
-	if err != nil {
-		handleResponse(c, http.StatusBadRequest, "Error while file get "+err.Error())
-		return
-	}
-	dts := file.Filename
-
-	err = c.SaveUploadedFile(file, dts)
-	defer os.Remove(dts)
-
+	status, err := h.readJSONUpload(c, "airport", &airports)
 	if err != nil {
-		handleResponse(c, http.StatusNotAcceptable, "Error while file save "+err.Error())
+		handleResponse(c, status, err.Error())
 		return
 	}
 
-	body, _ := os.ReadFile(dts)
-	err = json.Unmarshal(body, &airports)
-	if err != nil {
-		handleResponse(c, http.StatusNotAcceptable, "Error read file "+err.Error())
-		return
-	}
-	err = h.strg.Airport().Upload(airports)
+	err = h.strg.Airport().Upload(c.Request.Context(), airports)
 	if err != nil {
-		handleResponse(c, http.StatusNotAcceptable, "Error while insert to postgres "+err.Error())
+		metrics.UploadFailures.WithLabelValues("airport").Inc()
+		handleResponse(c, storageStatus(err, http.StatusNotAcceptable), "Error while insert to postgres "+err.Error())
 		return
 	}
+	metrics.UploadRows.WithLabelValues("airport").Add(float64(len(airports)))
 	handleResponse(c, http.StatusCreated, nil)
 }
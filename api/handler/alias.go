@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"essy_travel/models"
+	"essy_travel/pkg/helpers"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAlias godoc
+// @ID create_alias
+// @Router /v1/aliases [POST]
+// @Summary Create Alias
+// @Description Add an alternative name to a city or airport
+// @Tags Alias
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param object body models.CreateAlias true "CreateAliasRequestBody"
+// @Success 200 {object} Response{data=models.Alias} "AliasBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) CreateAlias(c *gin.Context) {
+	var alias = models.CreateAlias{}
+	err := c.ShouldBindJSON(&alias)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if alias.Entity != "city" && alias.Entity != "airport" {
+		handleResponse(c, http.StatusBadRequest, "entity must be city or airport")
+		return
+	}
+
+	if !helpers.IsValidUUID(alias.EntityId) {
+		handleResponse(c, http.StatusBadRequest, "entity_id is not uuid")
+		return
+	}
+
+	if len(alias.Title) == 0 {
+		handleResponse(c, http.StatusBadRequest, "title is required")
+		return
+	}
+
+	resp, err := h.strg.Alias().Create(c.Request.Context(), alias)
+	if err != nil {
+		handleResponse(c, storageStatus(err, http.StatusBadRequest), "Does not create"+err.Error())
+		return
+	}
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// GetByIdAlias godoc
+// @ID get_by_id_alias
+// @Router /v1/aliases/{id} [GET]
+// @Summary Get By Id Alias
+// @Description Get By Id Alias
+// @Tags Alias
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
+// @Success 200 {object} Response{data=models.Alias} "GetByIdAliasResponseBody"
+// @Header 200 {string} ETag "Tag of the data, for If-None-Match"
+// @Response 304 "Not Modified"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AliasGetById(c *gin.Context) {
+	var guid = c.Param("id")
+	if !helpers.IsValidUUID(guid) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	resp, err := h.strg.Alias().GetById(c.Request.Context(), models.AliasPrimaryKey{Guid: guid})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Alias does not exist: "+err.Error())
+		return
+	}
+
+	handleRead(c, resp)
+}
+
+// GetListAlias godoc
+// @ID get_list_alias
+// @Router /v1/aliases [GET]
+// @Summary Get List Alias
+// @Description Get List Alias
+// @Tags Alias
+// @Accept json
+// @Produce json
+// @Param limit query number false "limit"
+// @Param offset query number false "offset"
+// @Param entity query string false "city or airport"
+// @Param entity_id query string false "entity_id"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
+// @Success 200 {object} Response{data=models.GetListAliasResponse} "GetListAliasResponseBody"
+// @Header 200 {string} ETag "Tag of the data, for If-None-Match"
+// @Response 304 "Not Modified"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AliasGetList(c *gin.Context) {
+	offset, err := h.getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, 400, "invalid offset")
+		return
+	}
+
+	limit, err := h.getIntegerOrDefaultValue(c.Query("limit"), 0)
+	if err != nil {
+		handleResponse(c, 400, "invalid limit")
+		return
+	}
+
+	entityId := c.Query("entity_id")
+	if len(entityId) > 0 && !helpers.IsValidUUID(entityId) {
+		handleResponse(c, http.StatusBadRequest, "entity_id is not uuid")
+		return
+	}
+
+	resp, err := h.strg.Alias().GetList(c.Request.Context(), models.GetListAliasRequest{
+		Offset:   int(offset),
+		Limit:    int(limit),
+		Entity:   c.Query("entity"),
+		EntityId: entityId,
+	})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Alias does not exist: "+err.Error())
+		return
+	}
+
+	handleRead(c, resp)
+}
+
+// DeleteAlias godoc
+// @ID delete_alias
+// @Router /v1/aliases/{id} [DELETE]
+// @Summary Delete Alias
+// @Description Delete Alias
+// @Tags Alias
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=models.AliasPrimaryKey} "AliasBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AliasDelete(c *gin.Context) {
+	var alias = models.AliasPrimaryKey{}
+	err := bindId(c, &alias, &alias.Guid)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = h.strg.Alias().Delete(c.Request.Context(), alias)
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Alias does not delete: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusAccepted, "Deleted:")
+}
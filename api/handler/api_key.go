@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"essy_travel/models"
+	"essy_travel/pkg/auth"
+	"essy_travel/pkg/helpers"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateApiKey godoc
+// @ID create_api_key
+// @Router /v1/admin/api-keys [POST]
+// @Summary Create Api Key
+// @Description Issue an API key with the role reader, editor or admin. The key is in the response only; store it, it cannot be shown again.
+// @Tags ApiKey
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param object body models.CreateApiKey true "CreateApiKeyRequestBody"
+// @Success 201 {object} Response{data=models.CreatedApiKey} "ApiKeyBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 401 {object} Response{data=string} "Unauthorized"
+// @Response 403 {object} Response{data=string} "Forbidden"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) CreateApiKey(c *gin.Context) {
+	var apiKey = models.CreateApiKey{}
+	err := c.ShouldBindJSON(&apiKey)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if len(apiKey.Name) == 0 || len(apiKey.Name) > 64 {
+		handleResponse(c, http.StatusBadRequest, "name must be 1 to 64 characters")
+		return
+	}
+
+	_, err = auth.ParseRole(apiKey.Role)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	key, prefix, hash, err := auth.NewKey()
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "generate key: "+err.Error())
+		return
+	}
+	apiKey.Prefix, apiKey.Hash = prefix, hash
+
+	resp, err := h.strg.ApiKey().Create(c.Request.Context(), apiKey)
+	if err != nil {
+		handleResponse(c, storageStatus(err, http.StatusBadRequest), "Does not create"+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, models.CreatedApiKey{ApiKey: *resp, Key: key})
+}
+
+// GetByIdApiKey godoc
+// @ID get_by_id_api_key
+// @Router /v1/admin/api-keys/{id} [GET]
+// @Summary Get By Id Api Key
+// @Description Get By Id Api Key
+// @Tags ApiKey
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=models.ApiKey} "GetByIdApiKeyResponseBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 401 {object} Response{data=string} "Unauthorized"
+// @Response 403 {object} Response{data=string} "Forbidden"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) ApiKeyGetById(c *gin.Context) {
+	var guid = c.Param("id")
+	if !helpers.IsValidUUID(guid) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	resp, err := h.strg.ApiKey().GetById(c.Request.Context(), models.ApiKeyPrimaryKey{Guid: guid})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Api key does not exist: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// GetListApiKey godoc
+// @ID get_list_api_key
+// @Router /v1/admin/api-keys [GET]
+// @Summary Get List Api Key
+// @Description Get List Api Key
+// @Tags ApiKey
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param limit query number false "limit"
+// @Param offset query number false "offset"
+// @Param include_revoked query bool false "include_revoked"
+// @Success 200 {object} Response{data=models.GetListApiKeyResponse} "GetListApiKeyResponseBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 401 {object} Response{data=string} "Unauthorized"
+// @Response 403 {object} Response{data=string} "Forbidden"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) ApiKeyGetList(c *gin.Context) {
+	offset, err := h.getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, 400, "invalid offset")
+		return
+	}
+
+	limit, err := h.getIntegerOrDefaultValue(c.Query("limit"), 0)
+	if err != nil {
+		handleResponse(c, 400, "invalid limit")
+		return
+	}
+
+	var includeRevoked bool
+	if len(c.Query("include_revoked")) > 0 {
+		includeRevoked, err = strconv.ParseBool(c.Query("include_revoked"))
+		if err != nil {
+			handleResponse(c, 400, "invalid include_revoked")
+			return
+		}
+	}
+
+	resp, err := h.strg.ApiKey().GetList(c.Request.Context(), models.GetListApiKeyRequest{
+		Offset:         int(offset),
+		Limit:          int(limit),
+		IncludeRevoked: includeRevoked,
+	})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Api key does not exist: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// RevokeApiKey godoc
+// @ID revoke_api_key
+// @Router /v1/admin/api-keys/{id} [DELETE]
+// @Summary Revoke Api Key
+// @Description Revoke an API key; it is refused from then on
+// @Tags ApiKey
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=models.ApiKey} "ApiKeyBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 401 {object} Response{data=string} "Unauthorized"
+// @Response 403 {object} Response{data=string} "Forbidden"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) ApiKeyRevoke(c *gin.Context) {
+	var apiKey = models.ApiKeyPrimaryKey{}
+	err := bindId(c, &apiKey, &apiKey.Guid)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.strg.ApiKey().Revoke(c.Request.Context(), apiKey)
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Api key does not revoke: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
@@ -1,22 +1,24 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
 	"essy_travel/models"
 	"essy_travel/pkg/helpers"
-	"fmt"
+	"essy_travel/pkg/metrics"
+	"essy_travel/storage"
 	"net/http"
-	"os"
 
 	"github.com/gin-gonic/gin"
 )
 
 // CreateCity godoc
 // @ID create_city
-// @Router /city [POST]
+// @Router /v1/cities [POST]
 // @Summary Create City
 // @Description Create City
 // @Tags City
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param object body models.CreateCity true "CreateCityRequestBody"
@@ -31,9 +33,9 @@ func (h *Handler) CreateCity(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.strg.City().Create(city)
+	resp, err := h.strg.City().Create(c.Request.Context(), city)
 	if err != nil {
-		handleResponse(c, http.StatusBadRequest, "Does not create"+err.Error())
+		handleResponse(c, storageStatus(err, http.StatusBadRequest), "Does not create"+err.Error())
 		return
 	}
 	handleResponse(c, http.StatusCreated, resp)
@@ -41,36 +43,43 @@ func (h *Handler) CreateCity(c *gin.Context) {
 
 // GetByIdCity godoc
 // @ID get_by_id_city
-// @Router /city/{id} [GET]
+// @Router /v1/cities/{id} [GET]
 // @Summary Get By Id City
 // @Description Get By Id City
 // @Tags City
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
+// @Param Accept-Language header string false "Accept-Language"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
 // @Success 200 {object} Response{data=models.City} "GetByIdCityResponseBody"
+// @Header 200 {string} ETag "Tag of the data, for If-None-Match"
+// @Response 304 "Not Modified"
 // @Response 400 {object} Response{data=string} "Invalid Argument"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) CityGetById(c *gin.Context) {
 	var guid = c.Param("id")
-	fmt.Println(guid)
 	if !helpers.IsValidUUID(guid) {
 		handleResponse(c, http.StatusBadRequest, "id is not uuid")
 		return
 	}
 
-	resp, err := h.strg.City().GetById(models.CityPrimaryKey{Guid: guid})
+	resp, err := h.strg.City().GetById(c.Request.Context(), models.CityPrimaryKey{Guid: guid})
 	if err != nil {
-		handleResponse(c, 500, "City does not exist: "+err.Error())
+		handleResponse(c, storageStatus(err, 500), "City does not exist: "+err.Error())
 		return
 	}
 
-	handleResponse(c, http.StatusOK, resp)
+	if title, ok := h.localize(c, "city", resp.Guid)[resp.Guid]; ok {
+		resp.Title = title
+	}
+
+	handleVersion(c, resp.Version, resp)
 }
 
 // GetListCity godoc
 // @ID get_list_city
-// @Router /city/:id [GET]
+// @Router /v1/cities [GET]
 // @Summary Get List City
 // @Description Get List City
 // @Tags City
@@ -78,7 +87,12 @@ func (h *Handler) CityGetById(c *gin.Context) {
 // @Produce json
 // @Param limit query number false "limit"
 // @Param offset query number false "offset"
+// @Param search query string false "search by title, city code or alias"
+// @Param Accept-Language header string false "Accept-Language"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
 // @Success 200 {object} Response{data=models.GetListCityResponse} "GetListCityResponseBody"
+// @Header 200 {string} ETag "Tag of the data, for If-None-Match"
+// @Response 304 "Not Modified"
 // @Response 400 {object} Response{data=string} "Invalid Argument"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) CityGetList(c *gin.Context) {
@@ -94,29 +108,49 @@ func (h *Handler) CityGetList(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.strg.City().GetList(models.GetListCityRequest{
+	resp, err := h.strg.City().GetList(c.Request.Context(), models.GetListCityRequest{
 		Offset: int(offset),
 		Limit:  int(limit),
+		Search: c.Query("search"),
 	})
 	if err != nil {
-		handleResponse(c, 500, "City does not exist: "+err.Error())
+		handleResponse(c, storageStatus(err, 500), "City does not exist: "+err.Error())
 		return
 	}
 
-	handleResponse(c, http.StatusOK, resp)
+	var ids = make([]string, 0, len(resp.Cities))
+	for _, v := range resp.Cities {
+		ids = append(ids, v.Guid)
+	}
+
+	titles := h.localize(c, "city", ids...)
+	for i, v := range resp.Cities {
+		if title, ok := titles[v.Guid]; ok {
+			resp.Cities[i].Title = title
+		}
+	}
+
+	handleRead(c, resp)
 }
 
 // UpdateCity godoc
 // @ID update_city
-// @Router /city [PUT]
+// @Router /v1/cities/{id} [PUT]
 // @Summary Update City
 // @Description Update City
 // @Tags City
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Accept json
 // @Produce json
+// @Param id path string true "id"
 // @Param object body models.UpdateCity true "UpdateCityRequestBody"
+// @Param If-Match header string false "ETag of the record as last read"
 // @Success 200 {object} Response{data=models.City} "CityBody"
+// @Header 200 {string} ETag "Tag of the stored record, for If-Match"
 // @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 409 {object} Response{data=models.City} "Changed Since Read, by Version"
+// @Response 412 {object} Response{data=models.City} "Changed Since Read, by If-Match"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) CityUpdate(c *gin.Context) {
 	var city = models.UpdateCity{}
@@ -125,36 +159,105 @@ func (h *Handler) CityUpdate(c *gin.Context) {
 		handleResponse(c, http.StatusBadRequest, "Error while json decoding"+err.Error())
 		return
 	}
-	resp, err := h.strg.City().Update(city)
+
+	err = pathId(c, &city.Guid)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.updateCity(c, city)
+}
+
+// updateCity stores city for CityUpdate and CityPatch.
+func (h *Handler) updateCity(c *gin.Context, city models.UpdateCity) {
+	fromHeader, err := ifMatch(c, &city.Version)
+	if err != nil {
+		handleResponse(c, http.StatusPreconditionFailed, err.Error())
+		return
+	}
+
+	resp, err := h.strg.City().Update(c.Request.Context(), city)
+	if errors.Is(err, storage.ErrConflict) {
+		handleConflict(c, fromHeader, resp)
+		return
+	}
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "City does not update: "+err.Error())
+		return
+	}
+	handleUpdated(c, resp.Version, resp)
+}
+
+// PatchCity godoc
+// @ID patch_city
+// @Router /v1/cities/{id} [PATCH]
+// @Summary Patch City
+// @Description Change only the fields named by the JSON merge patch (RFC 7396) in the body; null clears a field. Without version or If-Match the patch applies to the city as read just before, and fails with 409 if it changed since.
+// @Tags City
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "id"
+// @Param object body models.UpdateCity true "PatchCityRequestBody"
+// @Param If-Match header string false "ETag of the record as last read"
+// @Success 200 {object} Response{data=models.City} "CityBody"
+// @Header 200 {string} ETag "Tag of the stored record, for If-Match"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 404 {object} Response{data=string} "Not Found"
+// @Response 409 {object} Response{data=models.City} "Changed Since Read, by Version"
+// @Response 412 {object} Response{data=models.City} "Changed Since Read, by If-Match"
+// @Response 415 {object} Response{data=string} "Unsupported Media Type"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) CityPatch(c *gin.Context) {
+	var guid = c.Param("id")
+	if !helpers.IsValidUUID(guid) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	current, err := h.strg.City().GetById(c.Request.Context(), models.CityPrimaryKey{Guid: guid})
 	if err != nil {
-		handleResponse(c, 500, "City does not update: "+err.Error())
+		handleResponse(c, storageStatus(err, 500), "City does not exist: "+err.Error())
 		return
 	}
-	handleResponse(c, http.StatusAccepted, resp)
+
+	var city = models.UpdateCity{}
+	status, err := mergePatch(c, current, &city)
+	if err != nil {
+		handleResponse(c, status, "Error while applying patch: "+err.Error())
+		return
+	}
+	city.Guid = guid
+
+	h.updateCity(c, city)
 }
 
 // DeleteCity godoc
 // @ID delete_city
-// @Router /city [DELETE]
+// @Router /v1/cities/{id} [DELETE]
 // @Summary Delete City
 // @Description Delete City
 // @Tags City
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param object body models.CityPrimaryKey true "DeleteCityRequestBody"
+// @Param id path string true "id"
 // @Success 200 {object} Response{data=models.CityPrimaryKey} "CityBody"
 // @Response 400 {object} Response{data=string} "Invalid Argument"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) CityDelete(c *gin.Context) {
 	var city = models.CityPrimaryKey{}
-	err := c.ShouldBindJSON(&city)
+	err := bindId(c, &city, &city.Guid)
 	if err != nil {
-		handleResponse(c, http.StatusBadRequest, "Error while json decoding"+err.Error())
+		handleResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	_, err = h.strg.City().Delete(city)
+	_, err = h.strg.City().Delete(c.Request.Context(), city)
 	if err != nil {
-		handleResponse(c, 500, "City does not delete: "+err.Error())
+		handleResponse(c, storageStatus(err, 500), "City does not delete: "+err.Error())
 		return
 	}
 
@@ -163,43 +266,34 @@ func (h *Handler) CityDelete(c *gin.Context) {
 
 // UploadCity godoc
 // @ID upload_city
-// @Router /city/:upload [POST]
+// @Router /v1/cities/import [POST]
 // @Summary Upload city
 // @Description Upload City
 // @Tags City
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param  	file  formData file true "File"
 // @Success 200 {object} Response{data=[]models.CreateCity} "CityBody"
 // @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 413 {object} Response{data=string} "File Too Large"
+// @Response 415 {object} Response{data=string} "Unsupported File Type"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) CityUpload(c *gin.Context) {
 	var cities = []models.CreateCity{}
-	file, err := c.FormFile("file")
+	status, err := h.readJSONUpload(c, "city", &cities)
 	if err != nil {
-		handleResponse(c, http.StatusNotAcceptable, err.Error())
+		handleResponse(c, status, err.Error())
 		return
 	}
-	dts := file.Filename
-
-	err = c.SaveUploadedFile(file, dts)
-	defer os.Remove(dts)
 
+	err = h.strg.City().Upload(c.Request.Context(), cities)
 	if err != nil {
-		handleResponse(c, http.StatusNotAcceptable, err.Error())
-		return
-	}
-
-	body, _ := os.ReadFile(dts)
-	err = json.Unmarshal(body, &cities)
-	if err != nil {
-		handleResponse(c, http.StatusNotAcceptable, err.Error())
-		return
-	}
-	err = h.strg.City().Upload(cities)
-	if err != nil {
-		handleResponse(c, http.StatusNotAcceptable, err.Error())
+		metrics.UploadFailures.WithLabelValues("city").Inc()
+		handleResponse(c, storageStatus(err, http.StatusNotAcceptable), err.Error())
 		return
 	}
+	metrics.UploadRows.WithLabelValues("city").Add(float64(len(cities)))
 	handleResponse(c, http.StatusCreated, nil)
 }
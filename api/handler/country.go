@@ -1,21 +1,27 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
 	"essy_travel/models"
 	"essy_travel/pkg/helpers"
+	"essy_travel/pkg/metrics"
+	"essy_travel/pkg/phone"
+	"essy_travel/storage"
+	"fmt"
 	"net/http"
-	"os"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 // CreateCountry godoc
 // @ID create_country
-// @Router /country [POST]
+// @Router /v1/countries [POST]
 // @Summary Create Country
-// @Description Create Country
+// @Description Create Country. When calling_code is left out it is taken from code, if that is an ISO 3166-1 alpha-2 code.
 // @Tags Country
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param object body models.CreateCountry true "CreateCountryRequestBody"
@@ -30,9 +36,15 @@ func (h *Handler) CreateCountry(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.strg.Country().Create(country)
+	country.CallingCode, err = callingCode(country.Code, country.CallingCode)
 	if err != nil {
-		handleResponse(c, http.StatusBadRequest, "Does not create"+err.Error())
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.strg.Country().Create(c.Request.Context(), country)
+	if err != nil {
+		handleResponse(c, storageStatus(err, http.StatusBadRequest), "Does not create"+err.Error())
 		return
 	}
 	handleResponse(c, http.StatusCreated, resp)
@@ -40,35 +52,43 @@ func (h *Handler) CreateCountry(c *gin.Context) {
 
 // GetByIdCountrygodoc
 // @ID get_by_id_country
-// @Router /country/{id} [GET]
+// @Router /v1/countries/{id} [GET]
 // @Summary Get By Id Country
 // @Description Get By Id Country
 // @Tags Country
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
+// @Param Accept-Language header string false "Accept-Language"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
 // @Success 200 {object} Response{data=models.Country} "GetListCountryResponseBody"
+// @Header 200 {string} ETag "Tag of the data, for If-None-Match"
+// @Response 304 "Not Modified"
 // @Response 400 {object} Response{data=string} "Invalid Argument"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) CountryGetById(c *gin.Context) {
-	var guid = c.Param("guid")
+	var guid = c.Param("id")
 	if !helpers.IsValidUUID(guid) {
 		handleResponse(c, http.StatusBadRequest, "id is not uuid")
 		return
 	}
 
-	resp, err := h.strg.Country().GetById(models.CountryPrimaryKey{Guid: guid})
+	resp, err := h.strg.Country().GetById(c.Request.Context(), models.CountryPrimaryKey{Guid: guid})
 	if err != nil {
-		handleResponse(c, 500, "Country does not exist: "+err.Error())
+		handleResponse(c, storageStatus(err, 500), "Country does not exist: "+err.Error())
 		return
 	}
 
-	handleResponse(c, http.StatusOK, resp)
+	if title, ok := h.localize(c, "country", resp.Guid)[resp.Guid]; ok {
+		resp.Title = title
+	}
+
+	handleVersion(c, resp.Version, resp)
 }
 
 // GetListCountrygodoc
 // @ID get_list_country
-// @Router /country [GET]
+// @Router /v1/countries [GET]
 // @Summary Get List Country
 // @Description Get List Country
 // @Tags Country
@@ -76,7 +96,12 @@ func (h *Handler) CountryGetById(c *gin.Context) {
 // @Produce json
 // @Param limit query number false "limit"
 // @Param offset query number false "offset"
+// @Param calling_code query string false "calling_code"
+// @Param Accept-Language header string false "Accept-Language"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
 // @Success 200 {object} Response{data=models.GetListCountryResponse} "GetListCountryResponseBody"
+// @Header 200 {string} ETag "Tag of the data, for If-None-Match"
+// @Response 304 "Not Modified"
 // @Response 400 {object} Response{data=string} "Invalid Argument"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) CountryGetList(c *gin.Context) {
@@ -92,29 +117,49 @@ func (h *Handler) CountryGetList(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.strg.Country().GetList(models.GetListCountryRequest{
-		Offset: int(offset),
-		Limit:  int(limit),
+	resp, err := h.strg.Country().GetList(c.Request.Context(), models.GetListCountryRequest{
+		Offset:      int(offset),
+		Limit:       int(limit),
+		CallingCode: strings.TrimPrefix(strings.TrimSpace(c.Query("calling_code")), "+"),
 	})
 	if err != nil {
-		handleResponse(c, 500, "Country does not exist: "+err.Error())
+		handleResponse(c, storageStatus(err, 500), "Country does not exist: "+err.Error())
 		return
 	}
 
-	handleResponse(c, http.StatusOK, resp)
+	var ids = make([]string, 0, len(resp.Countries))
+	for _, v := range resp.Countries {
+		ids = append(ids, v.Guid)
+	}
+
+	titles := h.localize(c, "country", ids...)
+	for i, v := range resp.Countries {
+		if title, ok := titles[v.Guid]; ok {
+			resp.Countries[i].Title = title
+		}
+	}
+
+	handleRead(c, resp)
 }
 
 // UpdateCountry godoc
 // @ID update_country
-// @Router /country [PUT]
+// @Router /v1/countries/{id} [PUT]
 // @Summary Update Country
-// @Description Update Country
+// @Description Update Country. When calling_code is left out it is taken from code, if that is an ISO 3166-1 alpha-2 code.
 // @Tags Country
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Accept json
 // @Produce json
+// @Param id path string true "id"
 // @Param object body models.UpdateCountry true "UpdateCountryRequestBody"
+// @Param If-Match header string false "ETag of the record as last read"
 // @Success 200 {object} Response{data=models.Country} "CountryBody"
+// @Header 200 {string} ETag "Tag of the stored record, for If-Match"
 // @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 409 {object} Response{data=models.Country} "Changed Since Read, by Version"
+// @Response 412 {object} Response{data=models.Country} "Changed Since Read, by If-Match"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) CountryUpdate(c *gin.Context) {
 	var country = models.UpdateCountry{}
@@ -123,38 +168,113 @@ func (h *Handler) CountryUpdate(c *gin.Context) {
 		handleResponse(c, http.StatusBadRequest, "Error while json decoding"+err.Error())
 		return
 	}
-	_, err = h.strg.Country().Update(country)
+
+	err = pathId(c, &country.Guid)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.updateCountry(c, country)
+}
+
+// updateCountry stores country for CountryUpdate and CountryPatch.
+func (h *Handler) updateCountry(c *gin.Context, country models.UpdateCountry) {
+	var err error
+	country.CallingCode, err = callingCode(country.Code, country.CallingCode)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	fromHeader, err := ifMatch(c, &country.Version)
+	if err != nil {
+		handleResponse(c, http.StatusPreconditionFailed, err.Error())
+		return
+	}
+
+	resp, err := h.strg.Country().Update(c.Request.Context(), country)
+	if errors.Is(err, storage.ErrConflict) {
+		handleConflict(c, fromHeader, resp)
+		return
+	}
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Country does not update: "+err.Error())
+		return
+	}
+
+	handleUpdated(c, resp.Version, resp)
+}
+
+// PatchCountry godoc
+// @ID patch_country
+// @Router /v1/countries/{id} [PATCH]
+// @Summary Patch Country
+// @Description Change only the fields named by the JSON merge patch (RFC 7396) in the body; null clears a field. Without version or If-Match the patch applies to the country as read just before, and fails with 409 if it changed since. A new code keeps the calling_code unless that is sent as null, to take it from the new code.
+// @Tags Country
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "id"
+// @Param object body models.UpdateCountry true "PatchCountryRequestBody"
+// @Param If-Match header string false "ETag of the record as last read"
+// @Success 200 {object} Response{data=models.Country} "CountryBody"
+// @Header 200 {string} ETag "Tag of the stored record, for If-Match"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 404 {object} Response{data=string} "Not Found"
+// @Response 409 {object} Response{data=models.Country} "Changed Since Read, by Version"
+// @Response 412 {object} Response{data=models.Country} "Changed Since Read, by If-Match"
+// @Response 415 {object} Response{data=string} "Unsupported Media Type"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) CountryPatch(c *gin.Context) {
+	var guid = c.Param("id")
+	if !helpers.IsValidUUID(guid) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	current, err := h.strg.Country().GetById(c.Request.Context(), models.CountryPrimaryKey{Guid: guid})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Country does not exist: "+err.Error())
+		return
+	}
+
+	var country = models.UpdateCountry{}
+	status, err := mergePatch(c, current, &country)
 	if err != nil {
-		handleResponse(c, 500, "Country does not update: "+err.Error())
+		handleResponse(c, status, "Error while applying patch: "+err.Error())
 		return
 	}
+	country.Guid = guid
 
-	handleResponse(c, http.StatusAccepted, "Updated")
+	h.updateCountry(c, country)
 }
 
 // DeleteCountry godoc
 // @ID delete_country
-// @Router /country [DELETE]
+// @Router /v1/countries/{id} [DELETE]
 // @Summary Delete Country
 // @Description Delete Country
 // @Tags Country
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param object body models.CountryPrimaryKey true "DeleteCountryRequestBody"
+// @Param id path string true "id"
 // @Success 200 {object} Response{data=models.UpdateCountry} "CountryBody"
 // @Response 400 {object} Response{data=string} "Invalid Argument"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) CountryDelete(c *gin.Context) {
 	var country = models.CountryPrimaryKey{}
-	err := c.ShouldBindJSON(&country)
-
+	err := bindId(c, &country, &country.Guid)
 	if err != nil {
-		handleResponse(c, http.StatusBadRequest, "Error while json decoding"+err.Error())
+		handleResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	_, err = h.strg.Country().Delete(country)
+	_, err = h.strg.Country().Delete(c.Request.Context(), country)
 	if err != nil {
-		handleResponse(c, 500, "Country does not delete: "+err.Error())
+		handleResponse(c, storageStatus(err, 500), "Country does not delete: "+err.Error())
 		return
 	}
 
@@ -163,43 +283,59 @@ func (h *Handler) CountryDelete(c *gin.Context) {
 
 // UploadCountry godoc
 // @ID upload_country
-// @Router /country/:upload [POST]
+// @Router /v1/countries/import [POST]
 // @Summary Upload country
 // @Description Upload Country
 // @Tags Country
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param  	file  formData file true "File"
 // @Success 200 {object} Response{data=[]models.CreateCountry} "CountryBody"
 // @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 413 {object} Response{data=string} "File Too Large"
+// @Response 415 {object} Response{data=string} "Unsupported File Type"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) CountryUpload(c *gin.Context) {
 	var countries = []models.CreateCountry{}
-	file, err := c.FormFile("file")
+	status, err := h.readJSONUpload(c, "country", &countries)
 	if err != nil {
-		handleResponse(c, http.StatusNotAcceptable, err.Error())
+		handleResponse(c, status, err.Error())
 		return
 	}
-	dts := file.Filename
 
-	err = c.SaveUploadedFile(file, dts)
-	defer os.Remove(dts)
+	for i, country := range countries {
+		countries[i].CallingCode, err = callingCode(country.Code, country.CallingCode)
+		if err != nil {
+			metrics.UploadFailures.WithLabelValues("country").Inc()
+			handleResponse(c, http.StatusBadRequest, fmt.Sprintf("country %d: %s", i, err))
+			return
+		}
+	}
 
+	err = h.strg.Country().Upload(c.Request.Context(), countries)
 	if err != nil {
-		handleResponse(c, http.StatusNotAcceptable, err.Error())
+		metrics.UploadFailures.WithLabelValues("country").Inc()
+		handleResponse(c, storageStatus(err, http.StatusNotAcceptable), err.Error())
 		return
 	}
+	metrics.UploadRows.WithLabelValues("country").Add(float64(len(countries)))
+	handleResponse(c, http.StatusCreated, nil)
+}
 
-	body, _ := os.ReadFile(dts)
-	err = json.Unmarshal(body, &countries)
-	if err != nil {
-		handleResponse(c, http.StatusNotAcceptable, err.Error())
-		return
+// callingCode checks the calling code of a country, which may be written
+// with a "+". When it is left out it is taken from the code, if that is an
+// ISO 3166-1 alpha-2 code.
+func callingCode(code, callingCode string) (string, error) {
+	callingCode = strings.TrimPrefix(strings.TrimSpace(callingCode), "+")
+	if len(callingCode) == 0 {
+		return phone.CallingCode(code), nil
 	}
-	err = h.strg.Country().Upload(countries)
-	if err != nil {
-		handleResponse(c, http.StatusNotAcceptable, err.Error())
-		return
+
+	if !phone.IsValidCallingCode(callingCode) {
+		return "", fmt.Errorf("calling_code %q is not 1 to 3 digits", callingCode)
 	}
-	handleResponse(c, http.StatusCreated, nil)
+
+	return callingCode, nil
 }
@@ -1,17 +1,35 @@
 package handler
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"essy_travel/config"
+	"essy_travel/pkg/auth"
+	"essy_travel/pkg/blob"
+	"essy_travel/pkg/helpers"
+	"essy_travel/pkg/logger"
 	"essy_travel/storage"
-	"log"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	cfg  *config.Config
-	strg storage.StorageI
+	cfg   *config.Config
+	strg  storage.StorageI
+	files blob.Storage
+
+	// tokens is nil when JWT_SECRET is not set.
+	tokens *auth.Tokens
+
+	started time.Time
 }
 
 // Response - Json model response
@@ -19,12 +37,17 @@ type Response struct {
 	Status      int         `json:"status"`
 	Description string      `json:"description"`
 	Data        interface{} `json:"data"`
+	RequestId   string      `json:"request_id,omitempty"`
 }
 
-func NewHandler(cfg *config.Config, strg storage.StorageI) *Handler {
+func NewHandler(cfg *config.Config, strg storage.StorageI, files blob.Storage, tokens *auth.Tokens) *Handler {
 	return &Handler{
-		cfg:  cfg,
-		strg: strg,
+		cfg:    cfg,
+		strg:   strg,
+		files:  files,
+		tokens: tokens,
+
+		started: time.Now(),
 	}
 }
 
@@ -38,18 +61,73 @@ func (h *Handler) getIntegerOrDefaultValue(value string, defaultValue int64) (in
 	return int64(number), err
 }
 
+// caller returns the authenticated caller of the request, if any, for
+// permission checks and audit.
+func caller(c *gin.Context) (auth.Principal, bool) {
+	return auth.FromContext(c.Request.Context())
+}
+
+// errInvalidId is returned by bindId and pathId for a key that is not a
+// uuid.
+var errInvalidId = errors.New("id is not uuid")
+
+// bindId reads the request of an update or delete keyed by guid. The /v1
+// routes carry the key as the :id path param and need no body; the
+// deprecated routes send it in the JSON body.
+func bindId(c *gin.Context, req interface{}, guid *string) error {
+	if id := c.Param("id"); len(id) > 0 {
+		*guid = id
+	} else if err := c.ShouldBindJSON(req); err != nil {
+		return fmt.Errorf("error while json decoding: %w", err)
+	}
+
+	if !helpers.IsValidUUID(*guid) {
+		return errInvalidId
+	}
+	return nil
+}
+
+// pathId puts the :id path param of a /v1 route, if any, into guid, over
+// the key a body names, and checks the key is a uuid.
+func pathId(c *gin.Context, guid *string) error {
+	if id := c.Param("id"); len(id) > 0 {
+		*guid = id
+	}
+
+	if !helpers.IsValidUUID(*guid) {
+		return errInvalidId
+	}
+	return nil
+}
+
+// storageStatus returns 404 for a missing row, 504 for a storage timeout
+// and status otherwise.
+func storageStatus(err error, status int) int {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, storage.ErrTimeout):
+		return http.StatusGatewayTimeout
+	}
+
+	return status
+}
+
 func handleResponse(c *gin.Context, status int, data interface{}) {
+	var ctx = c.Request.Context()
+
 	var description string
 	switch code := status; {
 	case code < 400:
 		description = "success"
 	default:
 		description = "error"
-		log.Println(config.Error, "error while:", Response{
-			Status:      status,
-			Description: description,
-			Data:        data,
-		})
+
+		level := slog.LevelWarn
+		if code >= 500 {
+			level = slog.LevelError
+		}
+		slog.Log(ctx, level, "error while handling request", "status", status, "error", data)
 
 		if code == 500 {
 			data = "Internal Server Error"
@@ -60,5 +138,123 @@ func handleResponse(c *gin.Context, status int, data interface{}) {
 		Status:      status,
 		Description: description,
 		Data:        data,
+		RequestId:   logger.RequestID(ctx),
 	})
 }
+
+// handleRead answers a read with data and a strong ETag of it, or with 304
+// Not Modified when If-None-Match names that tag. The tag covers the data
+// as localized, which localize marks as varying with Accept-Language.
+// There is no Last-Modified: titles come from translations and lists lose
+// rows, neither of which moves an updated_at.
+func handleRead(c *gin.Context, data interface{}) {
+	handleVersion(c, 0, data)
+}
+
+// handleVersion is handleRead for a row with a version, which starts its
+// ETag so that If-Match on an update can name it, see ifMatch.
+func handleVersion(c *gin.Context, version int, data interface{}) {
+	etag, err := setETag(c, version, data)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if noneMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, data)
+}
+
+// handleUpdated answers an update of a row with a version with the row as
+// stored and its ETag, so that the next update can be made conditional
+// without reading the row again.
+func handleUpdated(c *gin.Context, version int, data interface{}) {
+	_, err := setETag(c, version, data)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, data)
+}
+
+// setETag sets the ETag of data, and of version when it is above 0, and
+// returns it.
+func setETag(c *gin.Context, version int, data interface{}) (string, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(body)
+	etag := base64.RawURLEncoding.EncodeToString(sum[:16])
+	if version > 0 {
+		etag = strconv.Itoa(version) + "-" + etag
+	}
+	etag = `"` + etag + `"`
+
+	header := c.Writer.Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", "private, no-cache")
+
+	return etag, nil
+}
+
+// noneMatch tells whether an If-None-Match header names etag. Tags compare
+// weakly, so that a tag weakened by compression still matches.
+func noneMatch(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ifMatch reads the version an update is conditional on from If-Match, an
+// ETag sent by handleVersion, into version. Only the version in the tag
+// counts, so a tag weakened by compression or sent with another
+// Accept-Language still names it. It tells whether the header was set;
+// "*" sets no version.
+func ifMatch(c *gin.Context, version *int) (bool, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	switch {
+	case len(header) == 0:
+		return false, nil
+	case header == "*":
+		return true, nil
+	case strings.Contains(header, ","):
+		return true, errors.New("If-Match must name a single version")
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return true, errors.New("If-Match is not an entity tag")
+	}
+
+	v, _, _ := strings.Cut(tag[1:len(tag)-1], "-")
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return true, errors.New("If-Match does not name a version")
+	}
+
+	*version = n
+	return true, nil
+}
+
+// handleConflict answers an update that lost to another one with the row
+// as it is now: 412 when the version it expected came from If-Match, 409
+// when it came from the body.
+func handleConflict(c *gin.Context, fromHeader bool, current interface{}) {
+	status := http.StatusConflict
+	if fromHeader {
+		status = http.StatusPreconditionFailed
+	}
+
+	handleResponse(c, status, current)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"essy_travel/config"
+	"essy_travel/storage"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readyTimeout bounds the database checks of a readiness probe, which has
+// to answer before the probe itself gives up.
+const readyTimeout = 2 * time.Second
+
+// Readiness is the result of the readiness checks.
+type Readiness struct {
+	Database string         `json:"database"`
+	Schema   storage.Schema `json:"schema"`
+}
+
+// Info describes the running service.
+type Info struct {
+	Version   string        `json:"version"`
+	Revision  string        `json:"revision,omitempty"`
+	GoVersion string        `json:"go_version"`
+	StartedAt time.Time     `json:"started_at"`
+	Uptime    string        `json:"uptime"`
+	DB        sql.DBStats   `json:"db" swaggertype:"object"`
+	Config    config.Config `json:"config" swaggertype:"object"`
+}
+
+// Healthz godoc
+// @ID healthz
+// @Router /healthz [GET]
+// @Summary Liveness
+// @Description Reports that the process is up. It does not touch the database
+// @Tags Health
+// @Produce json
+// @Success 200 {object} Response{data=string} "ok"
+func (h *Handler) Healthz(c *gin.Context) {
+	handleResponse(c, http.StatusOK, "ok")
+}
+
+// Readyz godoc
+// @ID readyz
+// @Router /readyz [GET]
+// @Summary Readiness
+// @Description Pings the database and checks that every migration is applied
+// @Tags Health
+// @Produce json
+// @Success 200 {object} Response{data=Readiness} "Ready"
+// @Failure 503 {object} Response{data=string} "Not Ready"
+func (h *Handler) Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyTimeout)
+	defer cancel()
+
+	err := h.strg.Ping(ctx)
+	if err != nil {
+		handleResponse(c, http.StatusServiceUnavailable, "database is unreachable: "+err.Error())
+		return
+	}
+
+	schema, err := h.strg.Schema(ctx)
+	if err != nil {
+		handleResponse(c, http.StatusServiceUnavailable, "migration version is unknown: "+err.Error())
+		return
+	}
+
+	if schema.Dirty {
+		handleResponse(c, http.StatusServiceUnavailable, fmt.Sprintf("migration %d is dirty", schema.Version))
+		return
+	}
+
+	if schema.Version != schema.Latest {
+		handleResponse(c, http.StatusServiceUnavailable, fmt.Sprintf("database is at migration %d, want %d", schema.Version, schema.Latest))
+		return
+	}
+
+	handleResponse(c, http.StatusOK, Readiness{Database: "ok", Schema: schema})
+}
+
+// DebugInfo godoc
+// @ID debug_info
+// @Router /debug/info [GET]
+// @Summary Service Info
+// @Description Build version, uptime, database pool stats and the configuration with secrets redacted
+// @Tags Health
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} Response{data=Info} "Info"
+func (h *Handler) DebugInfo(c *gin.Context) {
+	var info = Info{
+		Version:   config.Version,
+		GoVersion: runtime.Version(),
+		StartedAt: h.started,
+		Uptime:    time.Since(h.started).Round(time.Second).String(),
+		DB:        h.strg.Stats(),
+		Config:    h.cfg.Redacted(),
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			if setting.Key == "vcs.revision" {
+				info.Revision = setting.Value
+			}
+		}
+	}
+
+	handleResponse(c, http.StatusOK, info)
+}
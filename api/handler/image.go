@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"essy_travel/models"
+	"essy_travel/pkg/blob"
+	"essy_travel/pkg/helpers"
+	"essy_travel/storage"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const airportImagePrefix = "airport/"
+
+// maxImagePixels bounds width × height of an uploaded image. A small file
+// may declare a huge image, and decoding allocates all of it.
+const maxImagePixels = 50_000_000
+
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// UploadAirportImage godoc
+// @ID upload_airport_image
+// @Router /v1/airports/{id}/image [POST]
+// @Summary Upload Airport Image
+// @Description Upload a JPEG, PNG or GIF image of the airport, replacing the previous one
+// @Tags Airport
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "id"
+// @Param image formData file true "Image"
+// @Success 201 {object} Response{data=models.Airport} "AirportBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 409 {object} Response{data=models.Airport} "Image Changed By Another Upload"
+// @Response 413 {object} Response{data=string} "Image Too Large"
+// @Response 415 {object} Response{data=string} "Unsupported Image Type"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AirportImageUpload(c *gin.Context) {
+	var guid = c.Param("id")
+	if !helpers.IsValidUUID(guid) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	airport, err := h.strg.Airport().GetById(c.Request.Context(), models.AirportPrimaryKey{Guid: guid})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Airport does not exist: "+err.Error())
+		return
+	}
+
+	body, contentType, status, err := h.readUpload(c, "image", h.cfg.ImageMaxSize)
+	if err != nil {
+		handleResponse(c, status, err.Error())
+		return
+	}
+
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		handleResponse(c, http.StatusUnsupportedMediaType, "image must be jpeg, png or gif")
+		return
+	}
+
+	imageConfig, _, err := image.DecodeConfig(bytes.NewReader(body))
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Error decode image "+err.Error())
+		return
+	}
+
+	if int64(imageConfig.Width)*int64(imageConfig.Height) > maxImagePixels {
+		handleResponse(c, http.StatusBadRequest, "image must have at most "+strconv.Itoa(maxImagePixels)+" pixels")
+		return
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(body))
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Error decode image "+err.Error())
+		return
+	}
+
+	var thumbnail bytes.Buffer
+	err = jpeg.Encode(&thumbnail, helpers.Thumbnail(img, h.cfg.ThumbnailSize), &jpeg.Options{Quality: 85})
+	if err != nil {
+		handleResponse(c, 500, "Error create thumbnail "+err.Error())
+		return
+	}
+
+	key := airportImagePrefix + guid + "/" + uuid.New().String() + ext
+
+	err = h.files.Put(key, bytes.NewReader(body))
+	if err != nil {
+		handleResponse(c, 500, "Error while image save "+err.Error())
+		return
+	}
+
+	err = h.files.Put(thumbnailKey(key), &thumbnail)
+	if err != nil {
+		h.removeImage(c.Request.Context(), guid, key)
+		handleResponse(c, 500, "Error while thumbnail save "+err.Error())
+		return
+	}
+
+	resp, err := h.strg.Airport().UpdateImage(c.Request.Context(), models.UpdateAirportImage{Guid: guid, Image: key, OldImage: airport.Image})
+	if errors.Is(err, storage.ErrConflict) {
+		// Another upload replaced the image since it was read; it
+		// removes the old one, this one only its own.
+		h.removeImage(c.Request.Context(), guid, key)
+		handleResponse(c, http.StatusConflict, resp)
+		return
+	}
+	if err != nil {
+		h.removeImage(c.Request.Context(), guid, key)
+		handleResponse(c, storageStatus(err, 500), "Airport image does not update: "+err.Error())
+		return
+	}
+
+	h.removeImage(c.Request.Context(), guid, airport.Image)
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// GetAirportImage godoc
+// @ID get_airport_image
+// @Router /v1/airports/{id}/image [GET]
+// @Summary Get Airport Image
+// @Description Get the airport image or its thumbnail
+// @Tags Airport
+// @Produce image/jpeg,image/png,image/gif
+// @Param id path string true "id"
+// @Param size query string false "original or thumbnail"
+// @Success 200 {file} file "Image"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 404 {object} Response{data=string} "Not Found"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AirportImage(c *gin.Context) {
+	var guid = c.Param("id")
+	if !helpers.IsValidUUID(guid) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	airport, err := h.strg.Airport().GetById(c.Request.Context(), models.AirportPrimaryKey{Guid: guid})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Airport does not exist: "+err.Error())
+		return
+	}
+
+	if !strings.HasPrefix(airport.Image, airportImagePrefix+guid+"/") {
+		handleResponse(c, http.StatusNotFound, "Airport has no image")
+		return
+	}
+
+	key := airport.Image
+	if c.Query("size") == "thumbnail" {
+		key = thumbnailKey(key)
+	}
+
+	file, err := h.files.Get(key)
+	if errors.Is(err, blob.ErrNotFound) {
+		handleResponse(c, http.StatusNotFound, "Airport image does not exist")
+		return
+	} else if err != nil {
+		handleResponse(c, 500, "Error while image read "+err.Error())
+		return
+	}
+	defer file.Close()
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.DataFromReader(http.StatusOK, -1, mime.TypeByExtension(path.Ext(key)), file, nil)
+}
+
+// thumbnailKey returns the key the thumbnail of the image stored under
+// key is kept at. Thumbnails are always JPEG.
+func thumbnailKey(key string) string {
+	return strings.TrimSuffix(key, path.Ext(key)) + "_thumb.jpg"
+}
+
+// removeImage deletes an image of the airport guid and its thumbnail.
+// Values that are not keys of images uploaded for that airport, such as
+// legacy external URLs, are ignored, so that one airport can never delete
+// the image of another.
+func (h *Handler) removeImage(ctx context.Context, guid, key string) {
+	if !strings.HasPrefix(key, airportImagePrefix+guid+"/") {
+		return
+	}
+
+	for _, k := range []string{key, thumbnailKey(key)} {
+		if err := h.files.Delete(k); err != nil {
+			slog.ErrorContext(ctx, "error while remove image", "key", k, "error", err)
+		}
+	}
+}
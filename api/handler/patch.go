@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"essy_travel/pkg/mergepatch"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errPatchType = errors.New("patch must be sent as application/merge-patch+json or application/json")
+
+// mergePatch applies the JSON merge patch in the request body to current
+// and decodes the result into update, the request of the entity's Update.
+// Fields the patch leaves out keep their value and null clears a field.
+// It returns the status to answer with when the patch cannot be applied.
+func mergePatch(c *gin.Context, current, update interface{}) (int, error) {
+	mediaType, _, err := mime.ParseMediaType(c.ContentType())
+	if err != nil || (mediaType != "application/merge-patch+json" && mediaType != "application/json") {
+		return http.StatusUnsupportedMediaType, errPatchType
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	// A patch that is not an object would replace the whole row.
+	if !bytes.HasPrefix(bytes.TrimSpace(patch), []byte("{")) {
+		return http.StatusBadRequest, errors.New("patch must be a JSON object")
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	merged, err := mergepatch.Apply(doc, patch)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	err = json.Unmarshal(merged, update)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"essy_travel/config"
+	"essy_travel/models"
+	"essy_travel/storage/memory"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestCityPatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	strg := memory.NewStore()
+	country, err := strg.Country().Create(ctx, models.CreateCountry{Title: "Uzbekistan", Code: "UZ", Continent: "Asia"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	city, err := strg.City().Create(ctx, models.CreateCity{
+		Title:      "Tashkent",
+		CountryId:  country.Guid,
+		CityCode:   "TAS",
+		Latitude:   "41.2995",
+		Longitude:  "69.2401",
+		TimezoneId: "Asia/Tashkent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(&config.Config{}, strg, nil, nil)
+	router := gin.New()
+	router.PATCH("/v1/cities/:id", h.CityPatch)
+
+	for _, tt := range []struct {
+		name        string
+		id          string
+		contentType string
+		ifMatch     string
+		patch       string
+		status      int
+	}{
+		{"unsupported media type", city.Guid, "text/plain", "", `{"title":"Toshkent"}`, http.StatusUnsupportedMediaType},
+		{"not an object", city.Guid, "application/merge-patch+json", "", `["Toshkent"]`, http.StatusBadRequest},
+		{"not JSON", city.Guid, "application/merge-patch+json", "", `{"title":`, http.StatusBadRequest},
+		{"wrong type", city.Guid, "application/merge-patch+json", "", `{"title":5}`, http.StatusBadRequest},
+		{"id not uuid", "tashkent", "application/merge-patch+json", "", `{"title":"Toshkent"}`, http.StatusBadRequest},
+		{"not found", uuid.NewString(), "application/merge-patch+json", "", `{"title":"Toshkent"}`, http.StatusNotFound},
+		{"stale If-Match", city.Guid, "application/merge-patch+json", `"stale"`, `{"title":"Toshkent"}`, http.StatusPreconditionFailed},
+		{"stale version", city.Guid, "application/merge-patch+json", "", `{"title":"Toshkent","version":7}`, http.StatusConflict},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			w := patch(router, "/v1/cities/"+tt.id, tt.contentType, tt.ifMatch, tt.patch)
+			if w.Code != tt.status {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		w := patch(router, "/v1/cities/"+city.Guid, "application/merge-patch+json", "", `{"title":"Toshkent","timezone_id":null}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d, want 200: %s", w.Code, w.Body)
+		}
+
+		var resp struct {
+			Data models.City `json:"data"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := resp.Data
+		if got.Title != "Toshkent" || got.TimezoneId != "" || got.CityCode != "TAS" || got.Latitude != "41.2995" || got.Version != city.Version+1 {
+			t.Errorf("patched city %+v, want the new title, no timezone and the rest kept", got)
+		}
+		if len(w.Header().Get("ETag")) == 0 {
+			t.Error("no ETag on the patched city")
+		}
+	})
+}
+
+func patch(router http.Handler, path, contentType, ifMatch, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	if len(ifMatch) > 0 {
+		req.Header.Set("If-Match", ifMatch)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"essy_travel/models"
+	"essy_travel/pkg/phone"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// errInvalidPhone marks a phone number the client has to correct, as
+// opposed to a failure to look up the countries.
+var errInvalidPhone = errors.New("phone is not valid")
+
+// normalizePhone parses an international phone number and checks it
+// against the numbering plan of the countries with its calling code, so
+// that only numbers of known countries are accepted. It returns the number
+// in E.164, the form phone numbers are stored in.
+func (h *Handler) normalizePhone(ctx context.Context, raw string) (string, error) {
+	number, err := phone.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q is not an international number, such as +998901234567", errInvalidPhone, raw)
+	}
+
+	countries, err := h.strg.Country().GetList(ctx, models.GetListCountryRequest{CallingCode: number.CallingCode, Limit: 100})
+	if err != nil {
+		return "", err
+	}
+
+	if len(countries.Countries) == 0 {
+		return "", fmt.Errorf("%w: no country has the calling code +%s", errInvalidPhone, number.CallingCode)
+	}
+
+	var titles []string
+	for _, country := range countries.Countries {
+		if number.ValidFor(country.Code, country.CallingCode) {
+			return number.E164, nil
+		}
+		titles = append(titles, country.Title)
+	}
+
+	return "", fmt.Errorf("%w: %s is not a number of %s", errInvalidPhone, number.E164, strings.Join(titles, ", "))
+}
+
+// optionalPhone normalizes raw unless it is empty.
+func (h *Handler) optionalPhone(ctx context.Context, raw string) (string, error) {
+	if len(strings.TrimSpace(raw)) == 0 {
+		return "", nil
+	}
+	return h.normalizePhone(ctx, raw)
+}
+
+// phoneStatus returns 400 for a phone number the client got wrong and the
+// storage status otherwise.
+func phoneStatus(err error) int {
+	if errors.Is(err, errInvalidPhone) {
+		return http.StatusBadRequest
+	}
+	return storageStatus(err, http.StatusInternalServerError)
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"essy_travel/config"
+	"essy_travel/models"
+	"essy_travel/storage/memory"
+	"testing"
+)
+
+func TestCallingCode(t *testing.T) {
+	for _, tt := range []struct {
+		code        string
+		callingCode string
+		want        string
+		wantErr     bool
+	}{
+		{"UZ", "998", "998", false},
+		{"UZ", "+998", "998", false},
+		{"UZ", " +998 ", "998", false},
+		{"UZ", "", "998", false},
+		{"uz", "", "998", false},
+		{"CA", "", "1", false},
+		{"UZB", "", "", false},
+		{"UZ", "0998", "", true},
+		{"UZ", "9980", "", true},
+		{"UZ", "abc", "", true},
+	} {
+		got, err := callingCode(tt.code, tt.callingCode)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("callingCode(%q, %q) = %q, %v; want %q, error %v", tt.code, tt.callingCode, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	ctx := context.Background()
+	strg := memory.NewStore()
+
+	// The United States and Canada share the calling code +1; a number is
+	// accepted when it is valid for either.
+	for _, country := range []models.CreateCountry{
+		{Title: "Canada", Code: "CA", Continent: "North America", CallingCode: "1"},
+		{Title: "United States", Code: "US", Continent: "North America", CallingCode: "1"},
+		{Title: "Uzbekistan", Code: "UZ", Continent: "Asia", CallingCode: "998"},
+	} {
+		_, err := strg.Country().Create(ctx, country)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := NewHandler(&config.Config{}, strg, nil, nil)
+
+	for _, tt := range []struct {
+		raw  string
+		want string
+	}{
+		{"+998 (90) 123-45-67", "+998901234567"},
+		{"00998901234567", "+998901234567"},
+		{"+1 201 555 0123", "+12015550123"},
+		{"+1 506 234 5678", "+15062345678"},
+	} {
+		got, err := h.normalizePhone(ctx, tt.raw)
+		if err != nil || got != tt.want {
+			t.Errorf("normalizePhone(%q) = %q, %v; want %q", tt.raw, got, err, tt.want)
+		}
+	}
+
+	for _, raw := range []string{
+		"901234567",
+		"+998 12 345",
+		"+7 701 123 4567",
+		"+1 555",
+	} {
+		_, err := h.normalizePhone(ctx, raw)
+		if !errors.Is(err, errInvalidPhone) {
+			t.Errorf("normalizePhone(%q): %v, want errInvalidPhone", raw, err)
+		}
+	}
+
+	got, err := h.optionalPhone(ctx, "  ")
+	if err != nil || len(got) > 0 {
+		t.Errorf("optionalPhone of blanks = %q, %v; want none", got, err)
+	}
+}
@@ -0,0 +1,353 @@
+package handler
+
+import (
+	"essy_travel/models"
+	"essy_travel/pkg/helpers"
+	"essy_travel/pkg/metrics"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateRoute godoc
+// @ID create_route
+// @Router /v1/routes [POST]
+// @Summary Create Route
+// @Description Create Route
+// @Tags Route
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param object body models.CreateRoute true "CreateRouteRequestBody"
+// @Success 200 {object} Response{data=models.Route} "RouteBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) CreateRoute(c *gin.Context) {
+	var route = models.CreateRoute{}
+	err := c.ShouldBindJSON(&route)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if !helpers.IsValidUUID(route.OriginAirportId) || !helpers.IsValidUUID(route.DestinationAirportId) {
+		handleResponse(c, http.StatusBadRequest, "origin_airport_id and destination_airport_id must be uuid")
+		return
+	}
+
+	resp, err := h.strg.Route().Create(c.Request.Context(), route)
+	if err != nil {
+		handleResponse(c, storageStatus(err, http.StatusBadRequest), "Does not create"+err.Error())
+		return
+	}
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// GetByIdRoute godoc
+// @ID get_by_id_route
+// @Router /v1/routes/{id} [GET]
+// @Summary Get By Id Route
+// @Description Get By Id Route
+// @Tags Route
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
+// @Success 200 {object} Response{data=models.Route} "GetByIdRouteResponseBody"
+// @Header 200 {string} ETag "Tag of the data, for If-None-Match"
+// @Response 304 "Not Modified"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) RouteGetById(c *gin.Context) {
+	var guid = c.Param("id")
+	if !helpers.IsValidUUID(guid) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	resp, err := h.strg.Route().GetById(c.Request.Context(), models.RoutePrimaryKey{Guid: guid})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Route does not exist: "+err.Error())
+		return
+	}
+
+	handleRead(c, resp)
+}
+
+// GetListRoute godoc
+// @ID get_list_route
+// @Router /v1/routes [GET]
+// @Summary Get List Route
+// @Description Get List Route. Use origin_city_id and destination_city_id to find routes between two cities.
+// @Tags Route
+// @Accept json
+// @Produce json
+// @Param limit query number false "limit"
+// @Param offset query number false "offset"
+// @Param origin_airport_id query string false "origin_airport_id"
+// @Param destination_airport_id query string false "destination_airport_id"
+// @Param origin_city_id query string false "origin_city_id"
+// @Param destination_city_id query string false "destination_city_id"
+// @Param active query boolean false "only active routes"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
+// @Success 200 {object} Response{data=models.GetListRouteResponse} "GetListRouteResponseBody"
+// @Header 200 {string} ETag "Tag of the data, for If-None-Match"
+// @Response 304 "Not Modified"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) RouteGetList(c *gin.Context) {
+	offset, err := h.getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, 400, "invalid offset")
+		return
+	}
+
+	limit, err := h.getIntegerOrDefaultValue(c.Query("limit"), 0)
+	if err != nil {
+		handleResponse(c, 400, "invalid limit")
+		return
+	}
+
+	var req = models.GetListRouteRequest{
+		Offset:               int(offset),
+		Limit:                int(limit),
+		OriginAirportId:      c.Query("origin_airport_id"),
+		DestinationAirportId: c.Query("destination_airport_id"),
+		OriginCityId:         c.Query("origin_city_id"),
+		DestinationCityId:    c.Query("destination_city_id"),
+		OnlyActive:           c.Query("active") == "true",
+	}
+
+	for _, id := range []string{req.OriginAirportId, req.DestinationAirportId, req.OriginCityId, req.DestinationCityId} {
+		if len(id) > 0 && !helpers.IsValidUUID(id) {
+			handleResponse(c, http.StatusBadRequest, "id is not uuid: "+id)
+			return
+		}
+	}
+
+	resp, err := h.strg.Route().GetList(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Route does not exist: "+err.Error())
+		return
+	}
+
+	handleRead(c, resp)
+}
+
+// GetDestinationsAirport godoc
+// @ID get_destinations_airport
+// @Router /v1/airports/{id}/destinations [GET]
+// @Summary Get Destinations From Airport
+// @Description Get all airports reachable by a direct route from the airport
+// @Tags Route
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param limit query number false "limit"
+// @Param offset query number false "offset"
+// @Param active query boolean false "only active routes"
+// @Param Accept-Language header string false "Accept-Language"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
+// @Success 200 {object} Response{data=models.GetListAirportResponse} "GetListAirportResponseBody"
+// @Header 200 {string} ETag "Tag of the data, for If-None-Match"
+// @Response 304 "Not Modified"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AirportDestinations(c *gin.Context) {
+	var guid = c.Param("id")
+	if !helpers.IsValidUUID(guid) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	offset, err := h.getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, 400, "invalid offset")
+		return
+	}
+
+	limit, err := h.getIntegerOrDefaultValue(c.Query("limit"), 0)
+	if err != nil {
+		handleResponse(c, 400, "invalid limit")
+		return
+	}
+
+	resp, err := h.strg.Route().GetDestinations(c.Request.Context(), models.GetDestinationsRequest{
+		AirportId:  guid,
+		Offset:     int(offset),
+		Limit:      int(limit),
+		OnlyActive: c.Query("active") == "true",
+	})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Destinations does not exist: "+err.Error())
+		return
+	}
+
+	var ids = make([]string, 0, len(resp.Airports))
+	for _, v := range resp.Airports {
+		ids = append(ids, v.Guid)
+	}
+
+	titles := h.localize(c, "airport", ids...)
+	for i, v := range resp.Airports {
+		if title, ok := titles[v.Guid]; ok {
+			resp.Airports[i].Title = title
+		}
+	}
+
+	handleRead(c, resp)
+}
+
+// UpdateRoute godoc
+// @ID update_route
+// @Router /v1/routes/{id} [PUT]
+// @Summary Update Route
+// @Description Update Route
+// @Tags Route
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param object body models.UpdateRoute true "UpdateRouteRequestBody"
+// @Success 200 {object} Response{data=models.Route} "RouteBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) RouteUpdate(c *gin.Context) {
+	var route = models.UpdateRoute{}
+	err := c.ShouldBindJSON(&route)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Error while json decoding"+err.Error())
+		return
+	}
+
+	err = pathId(c, &route.Guid)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.updateRoute(c, route)
+}
+
+// updateRoute stores route for RouteUpdate and RoutePatch. The airports
+// are required, so a patch that clears one is refused like a bad id.
+func (h *Handler) updateRoute(c *gin.Context, route models.UpdateRoute) {
+	if !helpers.IsValidUUID(route.OriginAirportId) || !helpers.IsValidUUID(route.DestinationAirportId) {
+		handleResponse(c, http.StatusBadRequest, "origin_airport_id and destination_airport_id must be uuid")
+		return
+	}
+
+	resp, err := h.strg.Route().Update(c.Request.Context(), route)
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Route does not update: "+err.Error())
+		return
+	}
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// PatchRoute godoc
+// @ID patch_route
+// @Router /v1/routes/{id} [PATCH]
+// @Summary Patch Route
+// @Description Change only the fields named by the JSON merge patch (RFC 7396) in the body; null clears a field. origin_airport_id and destination_airport_id are required and cannot be cleared.
+// @Tags Route
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "id"
+// @Param object body models.UpdateRoute true "PatchRouteRequestBody"
+// @Success 200 {object} Response{data=models.Route} "RouteBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 404 {object} Response{data=string} "Not Found"
+// @Response 415 {object} Response{data=string} "Unsupported Media Type"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) RoutePatch(c *gin.Context) {
+	var guid = c.Param("id")
+	if !helpers.IsValidUUID(guid) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	current, err := h.strg.Route().GetById(c.Request.Context(), models.RoutePrimaryKey{Guid: guid})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Route does not exist: "+err.Error())
+		return
+	}
+
+	var route = models.UpdateRoute{}
+	status, err := mergePatch(c, current, &route)
+	if err != nil {
+		handleResponse(c, status, "Error while applying patch: "+err.Error())
+		return
+	}
+	route.Guid = guid
+
+	h.updateRoute(c, route)
+}
+
+// DeleteRoute godoc
+// @ID delete_route
+// @Router /v1/routes/{id} [DELETE]
+// @Summary Delete Route
+// @Description Delete Route
+// @Tags Route
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=models.RoutePrimaryKey} "RouteBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) RouteDelete(c *gin.Context) {
+	var route = models.RoutePrimaryKey{}
+	err := bindId(c, &route, &route.Guid)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = h.strg.Route().Delete(c.Request.Context(), route)
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Route does not delete: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusAccepted, "Deleted:")
+}
+
+// UploadRoute godoc
+// @ID upload_route
+// @Router /v1/routes/import [POST]
+// @Summary Upload route
+// @Description Upload Route
+// @Tags Route
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param  	file  formData file true "File"
+// @Success 200 {object} Response{data=[]models.CreateRoute} "RouteBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 413 {object} Response{data=string} "File Too Large"
+// @Response 415 {object} Response{data=string} "Unsupported File Type"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) RouteUpload(c *gin.Context) {
+	var routes = []models.CreateRoute{}
+	status, err := h.readJSONUpload(c, "route", &routes)
+	if err != nil {
+		handleResponse(c, status, err.Error())
+		return
+	}
+
+	err = h.strg.Route().Upload(c.Request.Context(), routes)
+	if err != nil {
+		metrics.UploadFailures.WithLabelValues("route").Inc()
+		handleResponse(c, storageStatus(err, http.StatusNotAcceptable), err.Error())
+		return
+	}
+	metrics.UploadRows.WithLabelValues("route").Add(float64(len(routes)))
+	handleResponse(c, http.StatusCreated, nil)
+}
@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"essy_travel/models"
+	"essy_travel/pkg/helpers"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var translatableEntities = map[string]bool{
+	"country": true,
+	"city":    true,
+	"airport": true,
+}
+
+// CreateTranslation godoc
+// @ID create_translation
+// @Router /v1/translations [POST]
+// @Summary Create Translation
+// @Description Create or replace the title of a country, city or airport in a locale
+// @Tags Translation
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param object body models.CreateTranslation true "CreateTranslationRequestBody"
+// @Success 200 {object} Response{data=models.Translation} "TranslationBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) CreateTranslation(c *gin.Context) {
+	var translation = models.CreateTranslation{}
+	err := c.ShouldBindJSON(&translation)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if !translatableEntities[translation.Entity] {
+		handleResponse(c, http.StatusBadRequest, "entity must be country, city or airport")
+		return
+	}
+
+	if !helpers.IsValidUUID(translation.EntityId) {
+		handleResponse(c, http.StatusBadRequest, "entity_id is not uuid")
+		return
+	}
+
+	if !h.isSupportedLanguage(translation.Locale) {
+		handleResponse(c, http.StatusBadRequest, "locale is not supported")
+		return
+	}
+
+	resp, err := h.strg.Translation().Create(c.Request.Context(), translation)
+	if err != nil {
+		handleResponse(c, storageStatus(err, http.StatusBadRequest), "Does not create"+err.Error())
+		return
+	}
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// GetByIdTranslation godoc
+// @ID get_by_id_translation
+// @Router /v1/translations/{id} [GET]
+// @Summary Get By Id Translation
+// @Description Get By Id Translation
+// @Tags Translation
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
+// @Success 200 {object} Response{data=models.Translation} "GetByIdTranslationResponseBody"
+// @Header 200 {string} ETag "Tag of the data, for If-None-Match"
+// @Response 304 "Not Modified"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) TranslationGetById(c *gin.Context) {
+	var guid = c.Param("id")
+	if !helpers.IsValidUUID(guid) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	resp, err := h.strg.Translation().GetById(c.Request.Context(), models.TranslationPrimaryKey{Guid: guid})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Translation does not exist: "+err.Error())
+		return
+	}
+
+	handleRead(c, resp)
+}
+
+// GetListTranslation godoc
+// @ID get_list_translation
+// @Router /v1/translations [GET]
+// @Summary Get List Translation
+// @Description Get List Translation
+// @Tags Translation
+// @Accept json
+// @Produce json
+// @Param limit query number false "limit"
+// @Param offset query number false "offset"
+// @Param entity query string false "country, city or airport"
+// @Param entity_id query string false "entity_id"
+// @Param locale query string false "locale"
+// @Param If-None-Match header string false "ETag of the copy the client holds"
+// @Success 200 {object} Response{data=models.GetListTranslationResponse} "GetListTranslationResponseBody"
+// @Header 200 {string} ETag "Tag of the data, for If-None-Match"
+// @Response 304 "Not Modified"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) TranslationGetList(c *gin.Context) {
+	offset, err := h.getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, 400, "invalid offset")
+		return
+	}
+
+	limit, err := h.getIntegerOrDefaultValue(c.Query("limit"), 0)
+	if err != nil {
+		handleResponse(c, 400, "invalid limit")
+		return
+	}
+
+	entityId := c.Query("entity_id")
+	if len(entityId) > 0 && !helpers.IsValidUUID(entityId) {
+		handleResponse(c, http.StatusBadRequest, "entity_id is not uuid")
+		return
+	}
+
+	resp, err := h.strg.Translation().GetList(c.Request.Context(), models.GetListTranslationRequest{
+		Offset:   int(offset),
+		Limit:    int(limit),
+		Entity:   c.Query("entity"),
+		EntityId: entityId,
+		Locale:   c.Query("locale"),
+	})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Translation does not exist: "+err.Error())
+		return
+	}
+
+	handleRead(c, resp)
+}
+
+// UpdateTranslation godoc
+// @ID update_translation
+// @Router /v1/translations/{id} [PUT]
+// @Summary Update Translation
+// @Description Update Translation
+// @Tags Translation
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param object body models.UpdateTranslation true "UpdateTranslationRequestBody"
+// @Success 200 {object} Response{data=models.Translation} "TranslationBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) TranslationUpdate(c *gin.Context) {
+	var translation = models.UpdateTranslation{}
+	err := c.ShouldBindJSON(&translation)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Error while json decoding"+err.Error())
+		return
+	}
+
+	err = pathId(c, &translation.Guid)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.updateTranslation(c, translation)
+}
+
+// updateTranslation stores translation for TranslationUpdate and TranslationPatch.
+func (h *Handler) updateTranslation(c *gin.Context, translation models.UpdateTranslation) {
+	if !h.isSupportedLanguage(translation.Locale) {
+		handleResponse(c, http.StatusBadRequest, "locale is not supported")
+		return
+	}
+
+	resp, err := h.strg.Translation().Update(c.Request.Context(), translation)
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Translation does not update: "+err.Error())
+		return
+	}
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// PatchTranslation godoc
+// @ID patch_translation
+// @Router /v1/translations/{id} [PATCH]
+// @Summary Patch Translation
+// @Description Change only the fields named by the JSON merge patch (RFC 7396) in the body; null clears a field.
+// @Tags Translation
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "id"
+// @Param object body models.UpdateTranslation true "PatchTranslationRequestBody"
+// @Success 200 {object} Response{data=models.Translation} "TranslationBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 404 {object} Response{data=string} "Not Found"
+// @Response 415 {object} Response{data=string} "Unsupported Media Type"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) TranslationPatch(c *gin.Context) {
+	var guid = c.Param("id")
+	if !helpers.IsValidUUID(guid) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	current, err := h.strg.Translation().GetById(c.Request.Context(), models.TranslationPrimaryKey{Guid: guid})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Translation does not exist: "+err.Error())
+		return
+	}
+
+	var translation = models.UpdateTranslation{}
+	status, err := mergePatch(c, current, &translation)
+	if err != nil {
+		handleResponse(c, status, "Error while applying patch: "+err.Error())
+		return
+	}
+	translation.Guid = guid
+
+	h.updateTranslation(c, translation)
+}
+
+// DeleteTranslation godoc
+// @ID delete_translation
+// @Router /v1/translations/{id} [DELETE]
+// @Summary Delete Translation
+// @Description Delete Translation
+// @Tags Translation
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=models.TranslationPrimaryKey} "TranslationBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) TranslationDelete(c *gin.Context) {
+	var translation = models.TranslationPrimaryKey{}
+	err := bindId(c, &translation, &translation.Guid)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = h.strg.Translation().Delete(c.Request.Context(), translation)
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "Translation does not delete: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusAccepted, "Deleted:")
+}
+
+func (h *Handler) isSupportedLanguage(locale string) bool {
+	for _, l := range h.cfg.Languages {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// locales returns the supported languages asked for in Accept-Language,
+// most preferred first, followed by the default language.
+func (h *Handler) locales(c *gin.Context) []string {
+	var locales = []string{}
+	for _, l := range helpers.ParseAcceptLanguage(c.GetHeader("Accept-Language")) {
+		if h.isSupportedLanguage(l) {
+			locales = append(locales, l)
+		}
+	}
+
+	return helpers.RemoveDuplicatesStrings(append(locales, h.cfg.DefaultLanguage))
+}
+
+// localize returns entity id -> title in the language preferred by the
+// client. Ids without a translation are missing from the map, so callers
+// keep the stored title as the last fallback.
+func (h *Handler) localize(c *gin.Context, entity string, ids ...string) map[string]string {
+	c.Header("Vary", "Accept-Language")
+
+	titles, err := h.strg.Translation().GetTitles(c.Request.Context(), models.GetTitlesRequest{
+		Entity:    entity,
+		EntityIds: ids,
+		Locales:   h.locales(c),
+	})
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "error while localize", "entity", entity, "error", err)
+		return map[string]string{}
+	}
+
+	return titles
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"essy_travel/pkg/metrics"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// multipartOverhead is the room left for multipart headers and boundaries
+// when the request body is capped at the maximum upload size.
+const multipartOverhead = 64 << 10
+
+// readUpload copies the form file named field into a generated file in
+// the upload temp directory and returns its content together with the
+// sniffed content type. On failure the returned status tells the client
+// what went wrong: 400 for a bad request, 413 for a too large file.
+func (h *Handler) readUpload(c *gin.Context, field string, maxSize int64) ([]byte, string, int, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+
+	file, err := c.FormFile(field)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, "", http.StatusRequestEntityTooLarge, fmt.Errorf("file is larger than %d bytes", maxSize)
+		}
+		return nil, "", http.StatusBadRequest, fmt.Errorf("error while file get: %w", err)
+	}
+
+	if name := rawFilename(file); !isSafeFilename(name) {
+		return nil, "", http.StatusBadRequest, fmt.Errorf("invalid file name %q", name)
+	}
+
+	if file.Size > maxSize {
+		return nil, "", http.StatusRequestEntityTooLarge, fmt.Errorf("file is larger than %d bytes", maxSize)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, "", http.StatusBadRequest, fmt.Errorf("error while file open: %w", err)
+	}
+	defer src.Close()
+
+	err = os.MkdirAll(h.cfg.UploadTempDir, 0o700)
+	if err != nil {
+		return nil, "", http.StatusInternalServerError, err
+	}
+
+	tmp, err := os.CreateTemp(h.cfg.UploadTempDir, "upload-*")
+	if err != nil {
+		return nil, "", http.StatusInternalServerError, err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, io.LimitReader(src, maxSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, "", http.StatusInternalServerError, fmt.Errorf("error while file save: %w", err)
+	}
+
+	if written > maxSize {
+		return nil, "", http.StatusRequestEntityTooLarge, fmt.Errorf("file is larger than %d bytes", maxSize)
+	}
+
+	body, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return nil, "", http.StatusInternalServerError, fmt.Errorf("error read file: %w", err)
+	}
+
+	return body, http.DetectContentType(body), http.StatusOK, nil
+}
+
+// readJSONUpload reads the "file" form field of a bulk upload and decodes
+// the JSON array it contains into v. When configured, the original file is
+// kept under the upload directory for audit. Rejected files count as
+// failed uploads of entity.
+func (h *Handler) readJSONUpload(c *gin.Context, entity string, v interface{}) (int, error) {
+	status, err := h.decodeJSONUpload(c, entity, v)
+	if err != nil {
+		metrics.UploadFailures.WithLabelValues(entity).Inc()
+	}
+	return status, err
+}
+
+func (h *Handler) decodeJSONUpload(c *gin.Context, entity string, v interface{}) (int, error) {
+	body, contentType, status, err := h.readUpload(c, "file", h.cfg.UploadMaxSize)
+	if err != nil {
+		return status, err
+	}
+
+	if !strings.HasPrefix(contentType, "text/plain") || !json.Valid(body) {
+		return http.StatusUnsupportedMediaType, errors.New("file must be a JSON array")
+	}
+
+	err = json.Unmarshal(body, v)
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("error read file: %w", err)
+	}
+
+	if h.cfg.UploadKeepOriginals {
+		err = h.keepUpload(entity, body)
+		if err != nil {
+			return http.StatusInternalServerError, fmt.Errorf("error while file keep: %w", err)
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+// keepUpload stores an accepted upload as
+// <UploadDir>/<entity>/<timestamp>-<uuid>.json.
+func (h *Handler) keepUpload(entity string, body []byte) error {
+	dir := filepath.Join(h.cfg.UploadDir, entity)
+
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	name := time.Now().UTC().Format("20060102T150405") + "-" + uuid.New().String() + ".json"
+
+	return os.WriteFile(filepath.Join(dir, name), body, 0o644)
+}
+
+// rawFilename returns the file name exactly as sent by the client;
+// multipart.FileHeader.Filename has any directory part already stripped.
+func rawFilename(file *multipart.FileHeader) string {
+	_, params, err := mime.ParseMediaType(file.Header.Get("Content-Disposition"))
+	if err != nil || len(params["filename"]) == 0 {
+		return file.Filename
+	}
+
+	return params["filename"]
+}
+
+// isSafeFilename rejects client supplied names that try to point outside
+// of the directory they are stored in: a name must be a single path
+// element, and not "." or "..". Dots within a name, as in
+// "report..v2.csv", are fine.
+func isSafeFilename(name string) bool {
+	if len(name) == 0 || name == "." || name == ".." || strings.ContainsAny(name, `\`+"\x00") {
+		return false
+	}
+
+	return filepath.Base(name) == name
+}
@@ -0,0 +1,28 @@
+package handler
+
+import "testing"
+
+func TestIsSafeFilename(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want bool
+	}{
+		{"cities.csv", true},
+		{"report..v2.csv", true},
+		{"..hidden", true},
+		{"archive.tar.gz", true},
+		{"", false},
+		{".", false},
+		{"..", false},
+		{"../cities.csv", false},
+		{"data/cities.csv", false},
+		{"/etc/passwd", false},
+		{"cities/", false},
+		{`..\cities.csv`, false},
+		{"cities\x00.csv", false},
+	} {
+		if got := isSafeFilename(tt.name); got != tt.want {
+			t.Errorf("isSafeFilename(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
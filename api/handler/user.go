@@ -0,0 +1,482 @@
+package handler
+
+import (
+	"errors"
+	"essy_travel/models"
+	"essy_travel/pkg/auth"
+	"essy_travel/pkg/helpers"
+	"essy_travel/storage"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Register godoc
+// @ID register
+// @Router /v1/auth/register [POST]
+// @Summary Register
+// @Description Create a user account with the reader role. The login is 6 to 30 letters, digits or underscores starting with a letter; the password 8 to 72 bytes. The phone is optional, in international form such as +998 90 123 45 67, and is stored in E.164.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param object body models.CreateUser true "RegisterRequestBody"
+// @Success 201 {object} Response{data=models.User} "UserBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) Register(c *gin.Context) {
+	var user = models.CreateUser{}
+	err := c.ShouldBindJSON(&user)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if !helpers.IsValidLogin(user.Login) {
+		handleResponse(c, http.StatusBadRequest, "login is not valid")
+		return
+	}
+
+	user.Email = strings.ToLower(user.Email)
+	if msg := validProfile(user.Email, user.FullName); len(msg) > 0 {
+		handleResponse(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	user.Phone, err = h.optionalPhone(c.Request.Context(), user.Phone)
+	if err != nil {
+		handleResponse(c, phoneStatus(err), err.Error())
+		return
+	}
+
+	if msg := validPassword(user.Password); len(msg) > 0 {
+		handleResponse(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	user.PasswordHash, err = auth.HashPassword(user.Password)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "hash password: "+err.Error())
+		return
+	}
+	user.Password = ""
+	user.Role = string(auth.RoleReader)
+
+	resp, err := h.strg.User().Create(c.Request.Context(), user)
+	if err != nil {
+		handleResponse(c, storageStatus(err, http.StatusBadRequest), "Does not create"+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// Login godoc
+// @ID login
+// @Router /v1/auth/login [POST]
+// @Summary Login
+// @Description Sign in with a login or email and the password, and get an access and a refresh token
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param object body models.Login true "LoginRequestBody"
+// @Success 200 {object} Response{data=models.TokenPair} "TokenPair"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 401 {object} Response{data=string} "Unauthorized"
+// @Response 503 {object} Response{data=string} "Sign In Disabled"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) Login(c *gin.Context) {
+	if h.tokens == nil {
+		handleResponse(c, http.StatusServiceUnavailable, "signing in is disabled, JWT_SECRET is not set")
+		return
+	}
+
+	var login = models.Login{}
+	err := c.ShouldBindJSON(&login)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if strings.Contains(login.Login, "@") {
+		login.Login = strings.ToLower(login.Login)
+	}
+
+	user, err := h.strg.User().GetByLogin(c.Request.Context(), models.UserLoginKey{Login: login.Login})
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		handleResponse(c, storageStatus(err, 500), "User does not exist: "+err.Error())
+		return
+	}
+
+	// A missing user is checked against no hash, which takes as long as a
+	// wrong password.
+	var hash string
+	if user != nil {
+		hash = user.PasswordHash
+	}
+	if !auth.CheckPassword(hash, login.Password) {
+		handleResponse(c, http.StatusUnauthorized, "invalid login or password")
+		return
+	}
+
+	h.issueTokens(c, user)
+}
+
+// Refresh godoc
+// @ID refresh
+// @Router /v1/auth/refresh [POST]
+// @Summary Refresh
+// @Description Exchange a refresh token for a new access and refresh token. The role is read again, so changes to it apply from here on. Refresh tokens issued before the last password change are rejected.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param object body models.RefreshToken true "RefreshRequestBody"
+// @Success 200 {object} Response{data=models.TokenPair} "TokenPair"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 401 {object} Response{data=string} "Unauthorized"
+// @Response 503 {object} Response{data=string} "Sign In Disabled"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) Refresh(c *gin.Context) {
+	if h.tokens == nil {
+		handleResponse(c, http.StatusServiceUnavailable, "signing in is disabled, JWT_SECRET is not set")
+		return
+	}
+
+	var refresh = models.RefreshToken{}
+	err := c.ShouldBindJSON(&refresh)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	principal, err := h.tokens.Parse(refresh.RefreshToken, auth.TokenRefresh)
+	if err != nil {
+		handleResponse(c, http.StatusUnauthorized, "invalid refresh token")
+		return
+	}
+
+	user, err := h.strg.User().GetById(c.Request.Context(), models.UserPrimaryKey{Guid: principal.Subject})
+	if errors.Is(err, storage.ErrNotFound) {
+		handleResponse(c, http.StatusUnauthorized, "invalid refresh token")
+		return
+	} else if err != nil {
+		handleResponse(c, storageStatus(err, 500), "User does not exist: "+err.Error())
+		return
+	}
+
+	// The password changed since the token was issued.
+	if principal.TokenVersion != user.TokenVersion {
+		handleResponse(c, http.StatusUnauthorized, "invalid refresh token")
+		return
+	}
+
+	h.issueTokens(c, user)
+}
+
+func (h *Handler) issueTokens(c *gin.Context, user *models.User) {
+	access, refresh, err := h.tokens.Issue(auth.Principal{
+		Subject: user.Guid,
+		Name:    user.Login,
+		Role:    auth.Role(user.Role),
+
+		TokenVersion: user.TokenVersion,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "issue tokens: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, models.TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(h.tokens.AccessTTL().Seconds()),
+	})
+}
+
+// UserMe godoc
+// @ID user_me
+// @Router /v1/users/me [GET]
+// @Summary Get Profile
+// @Description Get the profile of the signed in user
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} Response{data=models.User} "UserBody"
+// @Response 401 {object} Response{data=string} "Unauthorized"
+// @Response 403 {object} Response{data=string} "Forbidden"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UserMe(c *gin.Context) {
+	principal, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.strg.User().GetById(c.Request.Context(), models.UserPrimaryKey{Guid: principal.Subject})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "User does not exist: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// UserUpdateMe godoc
+// @ID user_update_me
+// @Router /v1/users/me [PUT]
+// @Summary Update Profile
+// @Description Update the email, phone and full name of the signed in user
+// @Tags User
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param object body models.UpdateUser true "UpdateUserRequestBody"
+// @Success 200 {object} Response{data=models.User} "UserBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 401 {object} Response{data=string} "Unauthorized"
+// @Response 403 {object} Response{data=string} "Forbidden"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UserUpdateMe(c *gin.Context) {
+	principal, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	var user = models.UpdateUser{}
+	err := c.ShouldBindJSON(&user)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	user.Email = strings.ToLower(user.Email)
+	if msg := validProfile(user.Email, user.FullName); len(msg) > 0 {
+		handleResponse(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	user.Phone, err = h.optionalPhone(c.Request.Context(), user.Phone)
+	if err != nil {
+		handleResponse(c, phoneStatus(err), err.Error())
+		return
+	}
+	user.Guid = principal.Subject
+
+	resp, err := h.strg.User().Update(c.Request.Context(), user)
+	if err != nil {
+		handleResponse(c, storageStatus(err, http.StatusBadRequest), "User does not update: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// UserChangePassword godoc
+// @ID user_change_password
+// @Router /v1/users/me/password [PUT]
+// @Summary Change Password
+// @Description Change the password of the signed in user, who has to give the old one. Refresh tokens issued before the change stop working; access tokens are not checked against the store and stay valid until they expire.
+// @Tags User
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param object body models.ChangePassword true "ChangePasswordRequestBody"
+// @Success 200 {object} Response{data=models.User} "UserBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 401 {object} Response{data=string} "Unauthorized"
+// @Response 403 {object} Response{data=string} "Forbidden"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UserChangePassword(c *gin.Context) {
+	principal, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	var password = models.ChangePassword{}
+	err := c.ShouldBindJSON(&password)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if msg := validPassword(password.NewPassword); len(msg) > 0 {
+		handleResponse(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	user, err := h.strg.User().GetById(c.Request.Context(), models.UserPrimaryKey{Guid: principal.Subject})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "User does not exist: "+err.Error())
+		return
+	}
+
+	if !auth.CheckPassword(user.PasswordHash, password.OldPassword) {
+		handleResponse(c, http.StatusBadRequest, "old password is wrong")
+		return
+	}
+
+	hash, err := auth.HashPassword(password.NewPassword)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "hash password: "+err.Error())
+		return
+	}
+
+	resp, err := h.strg.User().UpdatePassword(c.Request.Context(), models.UpdateUserPassword{Guid: user.Guid, PasswordHash: hash})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "User does not update: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// GetByIdUser godoc
+// @ID get_by_id_user
+// @Router /v1/admin/users/{id} [GET]
+// @Summary Get By Id User
+// @Description Get By Id User
+// @Tags User
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=models.User} "GetByIdUserResponseBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 401 {object} Response{data=string} "Unauthorized"
+// @Response 403 {object} Response{data=string} "Forbidden"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UserGetById(c *gin.Context) {
+	var guid = c.Param("id")
+	if !helpers.IsValidUUID(guid) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	resp, err := h.strg.User().GetById(c.Request.Context(), models.UserPrimaryKey{Guid: guid})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "User does not exist: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// GetListUser godoc
+// @ID get_list_user
+// @Router /v1/admin/users [GET]
+// @Summary Get List User
+// @Description Get List User
+// @Tags User
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param limit query number false "limit"
+// @Param offset query number false "offset"
+// @Param search query string false "search"
+// @Success 200 {object} Response{data=models.GetListUserResponse} "GetListUserResponseBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 401 {object} Response{data=string} "Unauthorized"
+// @Response 403 {object} Response{data=string} "Forbidden"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UserGetList(c *gin.Context) {
+	offset, err := h.getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, 400, "invalid offset")
+		return
+	}
+
+	limit, err := h.getIntegerOrDefaultValue(c.Query("limit"), 0)
+	if err != nil {
+		handleResponse(c, 400, "invalid limit")
+		return
+	}
+
+	resp, err := h.strg.User().GetList(c.Request.Context(), models.GetListUserRequest{
+		Offset: int(offset),
+		Limit:  int(limit),
+		Search: c.Query("search"),
+	})
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "User does not exist: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// UpdateUserRole godoc
+// @ID update_user_role
+// @Router /v1/admin/users/{id}/role [PUT]
+// @Summary Update User Role
+// @Description Give a user the role reader, editor or admin. Refresh tokens stay valid and the next refresh picks up the new role; access tokens already issued keep the old role until they expire.
+// @Tags User
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param object body models.UpdateUserRole true "UpdateUserRoleRequestBody"
+// @Success 200 {object} Response{data=models.User} "UserBody"
+// @Response 400 {object} Response{data=string} "Invalid Argument"
+// @Response 401 {object} Response{data=string} "Unauthorized"
+// @Response 403 {object} Response{data=string} "Forbidden"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UserUpdateRole(c *gin.Context) {
+	var role = models.UpdateUserRole{}
+	err := c.ShouldBindJSON(&role)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+	err = pathId(c, &role.Guid)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	_, err = auth.ParseRole(role.Role)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.strg.User().UpdateRole(c.Request.Context(), role)
+	if err != nil {
+		handleResponse(c, storageStatus(err, 500), "User does not update: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// user returns the signed in user, or answers 403 when the caller is an
+// API key.
+func (h *Handler) user(c *gin.Context) (auth.Principal, bool) {
+	principal, ok := caller(c)
+	if !ok || !principal.IsUser() {
+		handleResponse(c, http.StatusForbidden, "only users have a profile")
+		return auth.Principal{}, false
+	}
+	return principal, true
+}
+
+// validProfile returns what is wrong with the profile fields of a user,
+// or "". The phone is checked by normalizePhone.
+func validProfile(email, fullName string) string {
+	switch {
+	case !helpers.IsValidEmail(email) || len(email) > 255:
+		return "email is not valid"
+	case len(fullName) > 128:
+		return "full_name is longer than 128 characters"
+	}
+	return ""
+}
+
+func validPassword(password string) string {
+	if len(password) < auth.MinPasswordLength || len(password) > auth.MaxPasswordLength {
+		return "password must be 8 to 72 bytes"
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"essy_travel/config"
+	"essy_travel/models"
+	"essy_travel/pkg/auth"
+	"essy_travel/storage/memory"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRefreshAfterPasswordChange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	strg := memory.NewStore()
+	hash, err := auth.HashPassword("Secret123!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = strg.User().Create(ctx, models.CreateUser{Login: "alisher", Email: "a@example.com", Role: "reader", PasswordHash: hash})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := auth.NewTokens("0123456789abcdef0123456789abcdef", "essy_travel", time.Minute, time.Hour)
+	h := NewHandler(&config.Config{}, strg, nil, tokens)
+
+	router := gin.New()
+	router.POST("/v1/auth/login", h.Login)
+	router.POST("/v1/auth/refresh", h.Refresh)
+	router.PUT("/v1/users/me/password", func(c *gin.Context) {
+		_, token, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+		principal, err := tokens.Parse(token, auth.TokenAccess)
+		if err != nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+	}, h.UserChangePassword)
+
+	before := login(t, router, "Secret123!")
+
+	w := send(router, http.MethodPost, "/v1/auth/refresh", "", models.RefreshToken{RefreshToken: before.RefreshToken})
+	if w.Code != http.StatusOK {
+		t.Fatalf("refresh before the change: status %d, want 200: %s", w.Code, w.Body)
+	}
+
+	w = send(router, http.MethodPut, "/v1/users/me/password", before.AccessToken, models.ChangePassword{OldPassword: "Secret123!", NewPassword: "Other456!x"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("change password: status %d, want 200: %s", w.Code, w.Body)
+	}
+
+	w = send(router, http.MethodPost, "/v1/auth/refresh", "", models.RefreshToken{RefreshToken: before.RefreshToken})
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("refresh with a token from before the change: status %d, want 401", w.Code)
+	}
+
+	after := login(t, router, "Other456!x")
+	w = send(router, http.MethodPost, "/v1/auth/refresh", "", models.RefreshToken{RefreshToken: after.RefreshToken})
+	if w.Code != http.StatusOK {
+		t.Errorf("refresh with a token from after the change: status %d, want 200: %s", w.Code, w.Body)
+	}
+}
+
+func login(t *testing.T, router http.Handler, password string) models.TokenPair {
+	t.Helper()
+
+	w := send(router, http.MethodPost, "/v1/auth/login", "", models.Login{Login: "alisher", Password: password})
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: status %d, want 200: %s", w.Code, w.Body)
+	}
+
+	var resp struct {
+		Data models.TokenPair `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.Data
+}
+
+func send(router http.Handler, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
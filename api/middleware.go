@@ -0,0 +1,277 @@
+package api
+
+import (
+	"errors"
+	"essy_travel/api/handler"
+	"essy_travel/models"
+	"essy_travel/pkg/auth"
+	"essy_travel/pkg/logger"
+	"essy_travel/pkg/metrics"
+	"essy_travel/pkg/ratelimit"
+	"essy_travel/storage"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// RequestID takes the request id from the X-Request-ID header, or makes a
+// new one, puts it into the request context and echoes it in the response.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+
+		ctx := logger.WithRequestID(c.Request.Context(), id)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request_id", id))
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(requestIDHeader, id)
+
+		c.Next()
+	}
+}
+
+// validRequestID accepts ids a client may reasonably send, so that they
+// cannot be used to forge log lines or blow up the header.
+func validRequestID(id string) bool {
+	if len(id) == 0 || len(id) > 128 {
+		return false
+	}
+
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Logger logs one line per request once it is served.
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []interface{}{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"duration", time.Since(start),
+			"client_ip", c.ClientIP(),
+			"size", c.Writer.Size(),
+		}
+		if principal, ok := auth.FromContext(c.Request.Context()); ok {
+			attrs = append(attrs, "caller", principal.Subject)
+		}
+
+		slog.Log(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery turns a panic in a handler into a 500 and logs it.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, err interface{}) {
+		slog.ErrorContext(c.Request.Context(), "panic", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
+
+// Metrics counts requests and observes their latency by route. Requests
+// that match no route share one label so that scanners cannot blow up the
+// number of series.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if len(route) == 0 {
+			route = "unmatched"
+		}
+
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+const apiKeyHeader = "X-API-Key"
+
+// Authenticate identifies the caller by the key in the X-API-Key header or
+// by the user token in "Authorization: Bearer", and puts it into the
+// request context. A request with neither goes on anonymously, one with
+// an unknown or revoked key or a bad token is refused. tokens is nil when
+// users cannot sign in.
+func Authenticate(strg storage.StorageI, tokens *auth.Tokens) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			ctx       = c.Request.Context()
+			principal auth.Principal
+		)
+
+		key := c.GetHeader(apiKeyHeader)
+		scheme, token, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+
+		switch {
+		case len(key) > 0:
+			apiKey, err := strg.ApiKey().GetByHash(ctx, models.ApiKeyHash{Hash: auth.HashKey(key)})
+			if errors.Is(err, storage.ErrNotFound) || (err == nil && len(apiKey.RevokedAt) > 0) {
+				abort(c, http.StatusUnauthorized, "invalid api key")
+				return
+			} else if err != nil {
+				slog.ErrorContext(ctx, "authenticate", "error", err)
+				abort(c, http.StatusInternalServerError, "Internal Server Error")
+				return
+			}
+
+			principal = auth.Principal{
+				Subject: apiKey.Guid,
+				Name:    apiKey.Name,
+				Role:    auth.Role(apiKey.Role),
+				Method:  auth.MethodApiKey,
+			}
+		case strings.EqualFold(scheme, "Bearer") && tokens != nil:
+			var err error
+			principal, err = tokens.Parse(strings.TrimSpace(token), auth.TokenAccess)
+			if err != nil {
+				c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+				abort(c, http.StatusUnauthorized, "invalid token")
+				return
+			}
+		case len(scheme) > 0:
+			abort(c, http.StatusUnauthorized, "unsupported authorization")
+			return
+		default:
+			c.Next()
+			return
+		}
+
+		ctx = auth.WithPrincipal(ctx, principal)
+		trace.SpanFromContext(ctx).SetAttributes(
+			attribute.String("enduser.id", principal.Subject),
+			attribute.String("enduser.role", string(principal.Role)),
+		)
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// Require lets through callers whose role allows role. Anonymous callers
+// get 401 and the others 403.
+func Require(role auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.FromContext(c.Request.Context())
+		if !ok {
+			abort(c, http.StatusUnauthorized, "api key required")
+			return
+		}
+
+		if !principal.Role.Allows(role) {
+			abort(c, http.StatusForbidden, "role "+string(role)+" required")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RateLimit takes a token for every request from the bucket of its caller
+// in class: the API key or the user when authenticated, the client IP
+// otherwise. A refused request gets 429 and Retry-After. When the limiter
+// fails the request is let through, so that a broken shared store does
+// not take the service down with it.
+func RateLimit(limiter ratelimit.Limiter, class string, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		key := class + ":ip:" + c.ClientIP()
+		if principal, ok := auth.FromContext(ctx); ok {
+			key = class + ":" + principal.Method + ":" + principal.Subject
+		}
+
+		result, err := limiter.Allow(ctx, key, limit)
+		if err != nil {
+			slog.WarnContext(ctx, "rate limit", "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			metrics.RateLimited.WithLabelValues(class).Inc()
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			abort(c, http.StatusTooManyRequests, "too many "+class+" requests")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// abort answers with the body the handlers use for errors.
+func abort(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, handler.Response{
+		Status:      status,
+		Description: "error",
+		Data:        message,
+		RequestId:   logger.RequestID(c.Request.Context()),
+	})
+}
+
+// deprecatedSince is when the unversioned routes gave way to /v1.
+var deprecatedSince = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// Deprecated marks a route kept for old clients: the Deprecation header
+// (RFC 9745) tells since when, and the Link header points to successor,
+// the route replacing it, with its ":name" segments filled in from the
+// request.
+func Deprecated(successor string) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(deprecatedSince.Unix(), 10)
+
+	return func(c *gin.Context) {
+		segments := strings.Split(successor, "/")
+		for i, segment := range segments {
+			if name, ok := strings.CutPrefix(segment, ":"); ok {
+				segments[i] = url.PathEscape(c.Param(name))
+			}
+		}
+
+		c.Header("Deprecation", deprecation)
+		c.Header("Link", "<"+strings.Join(segments, "/")+`>; rel="successor-version"`)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"essy_travel/pkg/auth"
+	"essy_travel/pkg/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// limiter answers every Allow with result, or err, and keeps the keys
+// it was asked for.
+type limiter struct {
+	result ratelimit.Result
+	err    error
+	keys   []string
+}
+
+func (l *limiter) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	l.keys = append(l.keys, key)
+	return l.result, l.err
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, tt := range []struct {
+		name    string
+		result  ratelimit.Result
+		err     error
+		status  int
+		headers map[string]string
+	}{
+		{
+			name:   "allowed",
+			result: ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9, Reset: 5500 * time.Millisecond},
+			status: http.StatusOK,
+			headers: map[string]string{
+				"X-RateLimit-Limit":     "10",
+				"X-RateLimit-Remaining": "9",
+				"X-RateLimit-Reset":     "6",
+				"Retry-After":           "",
+			},
+		},
+		{
+			name:   "refused",
+			result: ratelimit.Result{Limit: 10, RetryAfter: 1500 * time.Millisecond, Reset: time.Minute},
+			status: http.StatusTooManyRequests,
+			headers: map[string]string{
+				"X-RateLimit-Limit":     "10",
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     "60",
+				"Retry-After":           "2",
+			},
+		},
+		{
+			name:   "limiter failing",
+			err:    errors.New("store down"),
+			status: http.StatusOK,
+			headers: map[string]string{
+				"X-RateLimit-Limit": "",
+				"Retry-After":       "",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", RateLimit(&limiter{result: tt.result, err: tt.err}, "read", ratelimit.PerMinute(600, 10)), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.status {
+				t.Errorf("status %d, want %d", w.Code, tt.status)
+			}
+			for name, want := range tt.headers {
+				if got := w.Header().Get(name); got != want {
+					t.Errorf("%s %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	l := &limiter{result: ratelimit.Result{Allowed: true}}
+	router := gin.New()
+	router.GET("/", RateLimit(l, "read", ratelimit.PerMinute(600, 10)), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	principal := auth.Principal{Subject: "user-guid", Method: auth.MethodBearer}
+	req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := []string{"read:ip:192.0.2.1", "read:" + auth.MethodBearer + ":user-guid"}
+	if len(l.keys) != 2 || l.keys[0] != want[0] || l.keys[1] != want[1] {
+		t.Errorf("keys %q, want %q", l.keys, want)
+	}
+}
+
+func TestRateLimitMemory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/", RateLimit(ratelimit.NewMemory(), "read", ratelimit.PerMinute(1, 2)), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	var statuses []int
+	for _, addr := range []string{"192.0.2.1:1", "192.0.2.1:2", "192.0.2.1:3", "192.0.2.2:1"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = addr
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		statuses = append(statuses, w.Code)
+	}
+
+	want := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Errorf("statuses %v, want %v", statuses, want)
+			break
+		}
+	}
+}
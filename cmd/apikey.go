@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"essy_travel/config"
+	"essy_travel/models"
+	"essy_travel/pkg/auth"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+const apiKeyUsage = "usage: apikey create <name> <role> | list | revoke <guid>"
+
+// apiKey runs the apikey subcommand, which manages the keys without going
+// through the API, for instance to issue the first admin key.
+func apiKey(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(apiKeyUsage)
+	}
+
+	if cfg.StorageDriver == "memory" {
+		return errors.New("the memory storage keeps no api keys between runs")
+	}
+
+	strg, err := newStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer strg.Close()
+
+	ctx := context.Background()
+
+	switch {
+	case args[0] == "create" && len(args) == 3:
+		role, err := auth.ParseRole(args[2])
+		if err != nil {
+			return err
+		}
+
+		key, prefix, hash, err := auth.NewKey()
+		if err != nil {
+			return err
+		}
+
+		created, err := strg.ApiKey().Create(ctx, models.CreateApiKey{Name: args[1], Role: string(role), Prefix: prefix, Hash: hash})
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(os.Stderr, "created", created.Guid, "- the key is shown only once:")
+		fmt.Println(key)
+		return nil
+	case args[0] == "list" && len(args) == 1:
+		list, err := strg.ApiKey().GetList(ctx, models.GetListApiKeyRequest{Limit: 1000, IncludeRevoked: true})
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "GUID\tNAME\tROLE\tPREFIX\tCREATED\tREVOKED")
+		for _, k := range list.ApiKeys {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", k.Guid, k.Name, k.Role, k.Prefix, k.CreatedAt, k.RevokedAt)
+		}
+		return w.Flush()
+	case args[0] == "revoke" && len(args) == 2:
+		revoked, err := strg.ApiKey().Revoke(ctx, models.ApiKeyPrimaryKey{Guid: args[1]})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("revoked", revoked.Guid, "at", revoked.RevokedAt)
+		return nil
+	default:
+		return errors.New(apiKeyUsage)
+	}
+}
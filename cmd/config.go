@@ -0,0 +1,22 @@
+package main
+
+import (
+	"errors"
+	"essy_travel/config"
+	"os"
+)
+
+// configCommand runs the config subcommand. "config print" writes the
+// effective configuration, then fails if it does not validate.
+func configCommand(cfg *config.Config, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return errors.New("usage: config print")
+	}
+
+	err := cfg.Print(os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	return cfg.Validate()
+}
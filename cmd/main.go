@@ -1,34 +1,224 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"essy_travel/api"
 	"essy_travel/config"
+	"essy_travel/pkg/blob"
+	"essy_travel/pkg/logger"
+	"essy_travel/pkg/metrics"
+	"essy_travel/pkg/tracing"
+	"essy_travel/storage"
+	"essy_travel/storage/cache"
+	"essy_travel/storage/memory"
+	"essy_travel/storage/observe"
 	"essy_travel/storage/postgres"
-	"log"
+	"essy_travel/storage/sqlite"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
+const usage = `usage: essy_travel [command] [flags] [args]
+
+commands:
+  serve                     run the HTTP server (default)
+  migrate up|down [n]|status|goto <version>
+  config print              show the effective configuration
+  apikey create <name> <role>|list|revoke <guid>
+                            manage the API keys, roles are reader, editor and admin
+
+Run "essy_travel serve -h" for the flags.`
+
 func main() {
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve", "migrate", "config", "apikey":
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg, args, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, "config:", err)
+		os.Exit(2)
+	}
+
+	if command == "config" {
+		if err := configCommand(&cfg, args); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, "invalid config:")
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	log, err := logger.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	slog.SetDefault(log)
 
-	var cfg = config.Load()
+	switch command {
+	case "serve":
+		run(&cfg)
+	case "migrate":
+		if err := migrate(&cfg, args); err != nil {
+			fatal("migrate", err)
+		}
+	case "apikey":
+		if err := apiKey(&cfg, args); err != nil {
+			fatal("apikey", err)
+		}
+	}
+}
+
+// run serves HTTP until SIGINT or SIGTERM.
+func run(cfg *config.Config) {
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		fatal("set up tracing", err)
+	}
+
+	strg, err := newStorage(cfg)
+	if err != nil {
+		fatal("open storage", err)
+	}
+
+	err = metrics.RegisterDBStats(strg)
+	if err != nil {
+		fatal("register metrics", err)
+	}
+	strg = observe.New(strg, tracing.Observer{}, metrics.Observer{})
+	// The cache sits outside observe, so that hits are not counted as
+	// storage calls.
+	if cfg.CacheEnabled {
+		strg = cache.New(strg, cache.NewLRU(cfg.CacheSize), cfg.CacheTTL, metrics.CacheRecorder{})
+	}
 
-	pgStorage, err := postgres.NewConnectionPostgres(&cfg)
+	files, err := blob.NewLocal(cfg.UploadDir)
 	if err != nil {
-		panic(err)
+		fatal("open upload dir", err)
 	}
 
 	gin.SetMode(gin.ReleaseMode)
 
 	r := gin.New()
 
-	r.Use(gin.Logger(), gin.Recovery())
+	// Clients are told apart by IP for rate limiting, so X-Forwarded-For
+	// is only read from the proxies in TRUSTED_PROXIES.
+	err = r.SetTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		fatal("set trusted proxies", err)
+	}
+
+	r.Use(otelgin.Middleware(tracing.ServiceName), api.RequestID(), api.Logger(), api.Metrics(), api.Recovery())
+	if cfg.CompressionEnabled {
+		r.Use(api.Compress(cfg.CompressionMinSize))
+	}
+
+	api.SetUpApi(r, cfg, strg, files)
+
+	server := &http.Server{
+		Addr:         cfg.Addr(),
+		Handler:      r,
+		ReadTimeout:  cfg.HTTPReadTimeout,
+		WriteTimeout: cfg.HTTPWriteTimeout,
+		IdleTimeout:  cfg.HTTPIdleTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	served := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "addr", server.Addr)
+		served <- listen(server, cfg)
+	}()
+
+	select {
+	case err := <-served:
+		strg.Close()
+		fatal("listen and serve", err)
+	case <-ctx.Done():
+	}
+	stop()
+
+	// Shutdown stops accepting connections and waits for the requests in
+	// flight; the storage is closed only once they are done with it.
+	slog.Info("shutting down", "timeout", cfg.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
 
-	api.SetUpApi(r, &cfg, pgStorage)
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("shutdown", "error", err)
+	}
+
+	if err := strg.Close(); err != nil {
+		slog.Error("close storage", "error", err)
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("flush traces", "error", err)
+	}
+
+	slog.Info("stopped")
+}
 
-	log.Println("Listening:", cfg.ServiceHost+cfg.ServiceHTTPPort, "...")
-	if err := r.Run(cfg.ServiceHost + cfg.ServiceHTTPPort); err != nil {
-		panic("Listent and service panic:" + err.Error())
+// listen serves with TLS when a certificate and key are configured.
+func listen(server *http.Server, cfg *config.Config) error {
+	var err error
+	if len(cfg.TLSCertFile) > 0 {
+		err = server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+	} else {
+		err = server.ListenAndServe()
 	}
 
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// newStorage opens the storage backend selected by STORAGE_DRIVER.
+func newStorage(cfg *config.Config) (storage.StorageI, error) {
+	switch cfg.StorageDriver {
+	case "postgres":
+		return postgres.NewConnectionPostgres(cfg)
+	case "sqlite":
+		return sqlite.NewConnectionSQLite(cfg)
+	case "memory":
+		slog.Warn("using in-memory storage, data is lost on exit")
+		return memory.NewStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+	}
+}
+
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"essy_travel/config"
+	"essy_travel/migrations"
+	"essy_travel/storage/postgres"
+	"essy_travel/storage/sqlite"
+	"fmt"
+	"io/fs"
+	"strconv"
+)
+
+const migrateUsage = "usage: migrate up | down [n] | status | goto <version>"
+
+// migrate runs the migrate subcommand against the database selected by
+// STORAGE_DRIVER.
+func migrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	var (
+		db   *sql.DB
+		fsys fs.FS
+		err  error
+	)
+
+	switch cfg.StorageDriver {
+	case "postgres":
+		db, err = postgres.Open(cfg)
+		fsys = migrations.Postgres
+	case "sqlite":
+		db, err = sqlite.Open(cfg)
+		fsys = migrations.SQLite
+	default:
+		return fmt.Errorf("storage driver %q has no migrations", cfg.StorageDriver)
+	}
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	runner, err := migrations.NewRunner(db, fsys)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		err = runner.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		err = runner.Down(ctx, steps)
+	case "goto":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		var version uint64
+		version, err = strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		err = runner.Goto(ctx, uint(version))
+	case "status":
+		return printStatus(ctx, runner)
+	default:
+		return errors.New(migrateUsage)
+	}
+	if err != nil {
+		return err
+	}
+
+	return printStatus(ctx, runner)
+}
+
+func printStatus(ctx context.Context, runner *migrations.Runner) error {
+	version, statuses, err := runner.Status(ctx)
+	if statuses == nil && err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		applied := "pending"
+		if status.Applied {
+			applied = "applied"
+		}
+		fmt.Printf("%02d_%s\t%s\n", status.Version, status.Name, applied)
+	}
+	fmt.Println("version:", version)
+
+	return err
+}
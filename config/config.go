@@ -1,57 +1,279 @@
+// Package config loads the service configuration. Every setting has a
+// name such as POSTGRES_HOST and is looked up, from the highest precedence
+// down, in
+//
+//   - the command line flags, -postgres-host,
+//   - the environment and the .env file, POSTGRES_HOST,
+//   - the YAML file given by -config or CONFIG_FILE, postgres_host,
+//   - the default.
 package config
 
 import (
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
-	"github.com/spf13/cast"
 )
 
-const (
-	Error = "error >>> "
-	Info  = "info >>> "
-	Log   = "log >>> "
-)
+// Version is the build version, set with
+// -ldflags "-X essy_travel/config.Version=v1.2.3".
+var Version = "dev"
 
 type Config struct {
+	StorageDriver string
+
+	// DatabaseURL, when set, is used to connect to Postgres instead of
+	// the Postgres* fields.
+	DatabaseURL string
+
 	PostgresHost     string
 	PostgresUser     string
 	PostgresDatabase string
 	PostgresPassword string
 	PostgresPort     string
+	PostgresSSLMode  string
+
+	SQLitePath string
+
+	AutoMigrate bool
+
+	DBReadTimeout   time.Duration
+	DBWriteTimeout  time.Duration
+	DBUploadTimeout time.Duration
+
+	DBMaxOpenConns    int
+	DBMaxIdleConns    int
+	DBConnMaxLifetime time.Duration
+	DBConnMaxIdleTime time.Duration
 
 	ServiceHost     string
 	ServiceHTTPPort string
+
+	LogLevel  string
+	LogFormat string
+
+	TracingExporter    string
+	TracingEndpoint    string
+	TracingInsecure    bool
+	TracingFile        string
+	TracingSampleRatio float64
+
+	HTTPReadTimeout  time.Duration
+	HTTPWriteTimeout time.Duration
+	HTTPIdleTimeout  time.Duration
+	ShutdownTimeout  time.Duration
+
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// AuthPublicReads lets callers without an API key read; writes always
+	// need one.
+	AuthPublicReads bool
+
+	// JWTSecret signs the tokens of users. Without it users cannot sign
+	// in and only API keys are accepted.
+	JWTSecret     string
+	JWTIssuer     string
+	JWTAccessTTL  time.Duration
+	JWTRefreshTTL time.Duration
+
+	// Each class of request has a budget per client of so many requests a
+	// minute, with bursts of up to the burst size. A rate of 0 lifts the
+	// limit. The IP budget is taken before the caller is authenticated,
+	// so that requests with bad credentials are limited too.
+	RateLimitEnabled         bool
+	RateLimitIPPerMinute     float64
+	RateLimitIPBurst         int
+	RateLimitReadPerMinute   float64
+	RateLimitReadBurst       int
+	RateLimitWritePerMinute  float64
+	RateLimitWriteBurst      int
+	RateLimitUploadPerMinute float64
+	RateLimitUploadBurst     int
+
+	// TrustedProxies are the addresses or CIDRs whose X-Forwarded-For is
+	// believed when telling clients apart by IP.
+	TrustedProxies []string
+
+	// Countries, cities and airports are cached for CacheTTL, up to
+	// CacheSize lookups. Every instance has its own cache, so a write
+	// through one may be seen by the others only after CacheTTL.
+	CacheEnabled bool
+	CacheSize    int
+	CacheTTL     time.Duration
+
+	// JSON responses of at least CompressionMinSize bytes are sent with
+	// brotli or gzip when the client accepts either.
+	CompressionEnabled bool
+	CompressionMinSize int
+
+	DefaultLanguage string
+	Languages       []string
+
+	UploadDir           string
+	UploadTempDir       string
+	UploadMaxSize       int64
+	UploadKeepOriginals bool
+	ImageMaxSize        int64
+	ThumbnailSize       int
+
+	settings []setting
 }
 
-func Load() Config {
+// Load reads the configuration for the command line args, which exclude
+// the program name and any subcommand, and returns the arguments left
+// after the flags. It does not validate the result, see Validate.
+func Load(args []string) (Config, []string, error) {
+	err := godotenv.Load(".env")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Config{}, nil, fmt.Errorf(".env: %w", err)
+	}
+
+	// A first pass collects the settings so that each gets a flag.
+	var discover = &loader{}
+	load(&Config{}, discover)
+
+	var l = &loader{env: os.LookupEnv, flags: map[string]string{}}
 
-	if err := godotenv.Load(".env"); err != nil {
-		log.Println("not found env")
+	flags := flag.NewFlagSet("essy_travel", flag.ContinueOnError)
+	file := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML configuration file, also CONFIG_FILE")
+	for _, s := range discover.settings {
+		flags.Var(flagValue{key: s.key, flags: l.flags}, flagName(s.key), fmt.Sprintf("sets %s (default %q)", s.key, s.value))
 	}
 
-	var cfg Config
+	// Flags may come before, after or between the other arguments.
+	var rest []string
+	for {
+		err = flags.Parse(args)
+		if err != nil {
+			return Config{}, nil, err
+		}
+
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		rest, args = append(rest, args[0]), args[1:]
+	}
 
-	cfg.ServiceHost = cast.ToString(getValueOrDefault("SERVICE_HOST", "localhost"))
-	cfg.ServiceHTTPPort = cast.ToString(getValueOrDefault("SERVICE_HTTP_PORT", ":8080"))
+	if len(*file) > 0 {
+		l.file, err = readFile(*file, discover.settings)
+		if err != nil {
+			return Config{}, nil, err
+		}
+	}
 
-	cfg.PostgresHost = cast.ToString(getValueOrDefault("POSTGRES_HOST", "localhost"))
-	cfg.PostgresUser = cast.ToString(getValueOrDefault("POSTGRES_USER", "zafar"))
-	cfg.PostgresDatabase = cast.ToString(getValueOrDefault("POSTGRES_DATABASE", "essy_travel"))
-	cfg.PostgresPassword = cast.ToString(getValueOrDefault("POSTGRES_PASSWORD", "2605"))
-	cfg.PostgresPort = cast.ToString(getValueOrDefault("POSTGRES_PORT", "5432"))
+	var cfg Config
+	load(&cfg, l)
+	if err = errors.Join(l.errs...); err != nil {
+		return Config{}, nil, err
+	}
+	cfg.settings = l.settings
 
-	return cfg
+	return cfg, rest, nil
 }
 
-func getValueOrDefault(key string, defaultValue interface{}) interface{} {
+func load(cfg *Config, l *loader) {
+	cfg.ServiceHost = l.string("SERVICE_HOST", "localhost")
+	// ":8080", the form older .env files use, is read as "8080".
+	cfg.ServiceHTTPPort = strings.TrimPrefix(l.string("SERVICE_HTTP_PORT", "8080"), ":")
 
-	val, exists := os.LookupEnv(key)
+	cfg.LogLevel = l.string("LOG_LEVEL", "info")
+	cfg.LogFormat = l.string("LOG_FORMAT", "json")
 
-	if exists {
-		return val
-	}
+	cfg.TracingExporter = l.string("TRACING_EXPORTER", "none")
+	cfg.TracingEndpoint = l.string("TRACING_ENDPOINT", "")
+	cfg.TracingInsecure = l.bool("TRACING_INSECURE", false)
+	cfg.TracingFile = l.string("TRACING_FILE", "traces.json")
+	cfg.TracingSampleRatio = l.float64("TRACING_SAMPLE_RATIO", 1.0)
+
+	// Uploads may run for DB_UPLOAD_TIMEOUT, the write timeout leaves
+	// them room to answer.
+	cfg.HTTPReadTimeout = l.duration("HTTP_READ_TIMEOUT", "1m")
+	cfg.HTTPWriteTimeout = l.duration("HTTP_WRITE_TIMEOUT", "3m")
+	cfg.HTTPIdleTimeout = l.duration("HTTP_IDLE_TIMEOUT", "2m")
+	cfg.ShutdownTimeout = l.duration("SHUTDOWN_TIMEOUT", "30s")
+
+	cfg.TLSCertFile = l.string("TLS_CERT_FILE", "")
+	cfg.TLSKeyFile = l.string("TLS_KEY_FILE", "")
+
+	cfg.AuthPublicReads = l.bool("AUTH_PUBLIC_READS", true)
+
+	cfg.JWTSecret = l.secret("JWT_SECRET", "")
+	cfg.JWTIssuer = l.string("JWT_ISSUER", "essy_travel")
+	cfg.JWTAccessTTL = l.duration("JWT_ACCESS_TTL", "15m")
+	cfg.JWTRefreshTTL = l.duration("JWT_REFRESH_TTL", "720h")
+
+	cfg.RateLimitEnabled = l.bool("RATE_LIMIT_ENABLED", true)
+	cfg.RateLimitIPPerMinute = l.float64("RATE_LIMIT_IP_PER_MINUTE", 1200)
+	cfg.RateLimitIPBurst = l.int("RATE_LIMIT_IP_BURST", 200)
+	cfg.RateLimitReadPerMinute = l.float64("RATE_LIMIT_READ_PER_MINUTE", 600)
+	cfg.RateLimitReadBurst = l.int("RATE_LIMIT_READ_BURST", 100)
+	cfg.RateLimitWritePerMinute = l.float64("RATE_LIMIT_WRITE_PER_MINUTE", 60)
+	cfg.RateLimitWriteBurst = l.int("RATE_LIMIT_WRITE_BURST", 20)
+	cfg.RateLimitUploadPerMinute = l.float64("RATE_LIMIT_UPLOAD_PER_MINUTE", 4)
+	cfg.RateLimitUploadBurst = l.int("RATE_LIMIT_UPLOAD_BURST", 2)
+
+	cfg.TrustedProxies = l.list("TRUSTED_PROXIES", "")
+
+	cfg.CacheEnabled = l.bool("CACHE_ENABLED", true)
+	cfg.CacheSize = l.int("CACHE_SIZE", 10000)
+	cfg.CacheTTL = l.duration("CACHE_TTL", "1m")
+
+	cfg.CompressionEnabled = l.bool("COMPRESSION_ENABLED", true)
+	cfg.CompressionMinSize = l.int("COMPRESSION_MIN_SIZE", 1024)
+
+	cfg.DefaultLanguage = l.string("DEFAULT_LANGUAGE", "en")
+	cfg.Languages = l.list("LANGUAGES", "uz,ru,en")
+
+	cfg.UploadDir = l.string("UPLOAD_DIR", "uploads")
+	cfg.UploadTempDir = l.string("UPLOAD_TEMP_DIR", filepath.Join(os.TempDir(), "essy_travel"))
+	cfg.UploadMaxSize = l.int64("UPLOAD_MAX_SIZE", 10<<20)
+	cfg.UploadKeepOriginals = l.bool("UPLOAD_KEEP_ORIGINALS", false)
+	cfg.ImageMaxSize = l.int64("IMAGE_MAX_SIZE", 5<<20)
+	cfg.ThumbnailSize = l.int("THUMBNAIL_SIZE", 256)
+
+	cfg.StorageDriver = l.string("STORAGE_DRIVER", "postgres")
+
+	cfg.DatabaseURL = l.secret("DATABASE_URL", "")
+
+	cfg.PostgresHost = l.string("POSTGRES_HOST", "localhost")
+	cfg.PostgresUser = l.string("POSTGRES_USER", "")
+	cfg.PostgresDatabase = l.string("POSTGRES_DATABASE", "essy_travel")
+	cfg.PostgresPassword = l.secret("POSTGRES_PASSWORD", "")
+	cfg.PostgresPort = l.string("POSTGRES_PORT", "5432")
+	cfg.PostgresSSLMode = l.string("POSTGRES_SSLMODE", "disable")
+
+	cfg.SQLitePath = l.string("SQLITE_PATH", "essy_travel.db")
+
+	cfg.AutoMigrate = l.bool("AUTO_MIGRATE", false)
+
+	cfg.DBReadTimeout = l.duration("DB_READ_TIMEOUT", "5s")
+	cfg.DBWriteTimeout = l.duration("DB_WRITE_TIMEOUT", "10s")
+	cfg.DBUploadTimeout = l.duration("DB_UPLOAD_TIMEOUT", "2m")
+
+	cfg.DBMaxOpenConns = l.int("DB_MAX_OPEN_CONNS", 25)
+	cfg.DBMaxIdleConns = l.int("DB_MAX_IDLE_CONNS", 5)
+	cfg.DBConnMaxLifetime = l.duration("DB_CONN_MAX_LIFETIME", "30m")
+	cfg.DBConnMaxIdleTime = l.duration("DB_CONN_MAX_IDLE_TIME", "5m")
+}
+
+// Redacted returns a copy of cfg that is safe to show, with secrets masked.
+func (cfg Config) Redacted() Config {
+	cfg.PostgresPassword = mask(cfg.PostgresPassword)
+	cfg.DatabaseURL = mask(cfg.DatabaseURL)
+	cfg.JWTSecret = mask(cfg.JWTSecret)
+	cfg.settings = nil
+	return cfg
+}
 
-	return defaultValue
+// Addr is the address the HTTP server listens on.
+func (cfg Config) Addr() string {
+	return cfg.ServiceHost + ":" + cfg.ServiceHTTPPort
 }
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	fromDefault = "default"
+	fromFile    = "file"
+	fromEnv     = "env"
+	fromFlag    = "flag"
+)
+
+// setting is one resolved value together with where it came from.
+type setting struct {
+	key    string
+	value  string
+	from   string
+	secret bool
+}
+
+// loader resolves settings from the flags, the environment and the file,
+// in that order, and records them for Print.
+type loader struct {
+	flags map[string]string
+	env   func(string) (string, bool)
+	file  map[string]string
+
+	settings []setting
+	errs     []error
+}
+
+func (l *loader) lookup(key string, defaultValue interface{}, secret bool) string {
+	var s = setting{key: key, value: cast.ToString(defaultValue), from: fromDefault, secret: secret}
+
+	if val, ok := l.file[key]; ok {
+		s.value, s.from = val, fromFile
+	}
+
+	if l.env != nil {
+		if val, ok := l.env(key); ok {
+			s.value, s.from = val, fromEnv
+		}
+	}
+
+	if val, ok := l.flags[key]; ok {
+		s.value, s.from = val, fromFlag
+	}
+
+	l.settings = append(l.settings, s)
+
+	return s.value
+}
+
+func (l *loader) fail(key, value string, err error) {
+	l.errs = append(l.errs, fmt.Errorf("%s=%q: %w", key, value, err))
+}
+
+func (l *loader) string(key string, defaultValue string) string {
+	return l.lookup(key, defaultValue, false)
+}
+
+// secret is a string that Print and Redacted mask.
+func (l *loader) secret(key string, defaultValue string) string {
+	return l.lookup(key, defaultValue, true)
+}
+
+func (l *loader) list(key string, defaultValue string) []string {
+	var list []string
+	for _, item := range strings.Split(l.lookup(key, defaultValue, false), ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func (l *loader) bool(key string, defaultValue bool) bool {
+	val := l.lookup(key, defaultValue, false)
+	b, err := cast.ToBoolE(val)
+	if err != nil {
+		l.fail(key, val, err)
+	}
+	return b
+}
+
+func (l *loader) int(key string, defaultValue int) int {
+	val := l.lookup(key, defaultValue, false)
+	i, err := cast.ToIntE(val)
+	if err != nil {
+		l.fail(key, val, err)
+	}
+	return i
+}
+
+func (l *loader) int64(key string, defaultValue int64) int64 {
+	val := l.lookup(key, defaultValue, false)
+	i, err := cast.ToInt64E(val)
+	if err != nil {
+		l.fail(key, val, err)
+	}
+	return i
+}
+
+func (l *loader) float64(key string, defaultValue float64) float64 {
+	val := l.lookup(key, defaultValue, false)
+	f, err := cast.ToFloat64E(val)
+	if err != nil {
+		l.fail(key, val, err)
+	}
+	return f
+}
+
+func (l *loader) duration(key string, defaultValue string) time.Duration {
+	val := l.lookup(key, defaultValue, false)
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		l.fail(key, val, err)
+	}
+	return d
+}
+
+// flagName turns POSTGRES_HOST into postgres-host.
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+// flagValue stores the flag of a setting, so that flags left unset do not
+// hide the environment.
+type flagValue struct {
+	key   string
+	flags map[string]string
+}
+
+func (f flagValue) String() string {
+	return f.flags[f.key]
+}
+
+func (f flagValue) Set(val string) error {
+	f.flags[f.key] = val
+	return nil
+}
+
+// readFile reads a YAML file of settings keyed by their lower case names,
+// such as postgres_host. Lists may be YAML sequences.
+func readFile(path string, known []setting) (map[string]string, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	err = yaml.Unmarshal(body, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var keys = map[string]bool{}
+	for _, s := range known {
+		keys[s.key] = true
+	}
+
+	var values = map[string]string{}
+	for name, val := range raw {
+		key := strings.ToUpper(name)
+		if !keys[key] {
+			return nil, fmt.Errorf("%s: unknown setting %q", path, name)
+		}
+
+		switch val := val.(type) {
+		case []interface{}:
+			values[key] = strings.Join(cast.ToStringSlice(val), ",")
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = cast.ToString(val)
+		}
+	}
+
+	return values, nil
+}
+
+// mask hides a secret. Only the password of a URL is hidden, so that a
+// masked DATABASE_URL still tells which database it points at.
+func mask(secret string) string {
+	if len(secret) == 0 {
+		return ""
+	}
+
+	u, err := url.Parse(secret)
+	if err == nil && u.User != nil && len(u.Scheme) > 0 {
+		return u.Redacted()
+	}
+
+	return "*****"
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate reports every setting that is missing or out of range, so that
+// the service fails at startup rather than on the first request.
+func (cfg *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	switch cfg.StorageDriver {
+	case "postgres":
+		if len(cfg.DatabaseURL) == 0 {
+			check(len(cfg.PostgresHost) > 0, "POSTGRES_HOST or DATABASE_URL is required")
+			check(len(cfg.PostgresUser) > 0, "POSTGRES_USER or DATABASE_URL is required")
+			check(len(cfg.PostgresDatabase) > 0, "POSTGRES_DATABASE or DATABASE_URL is required")
+			check(validPort(cfg.PostgresPort), "POSTGRES_PORT %q is not a port", cfg.PostgresPort)
+		}
+	case "sqlite":
+		check(len(cfg.SQLitePath) > 0, "SQLITE_PATH is required")
+	case "memory":
+	default:
+		check(false, "STORAGE_DRIVER %q is not postgres, sqlite or memory", cfg.StorageDriver)
+	}
+
+	check(validPort(cfg.ServiceHTTPPort), "SERVICE_HTTP_PORT %q is not a port", cfg.ServiceHTTPPort)
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(cfg.LogLevel)) == nil, "LOG_LEVEL %q is not debug, info, warn or error", cfg.LogLevel)
+	check(oneOf(cfg.LogFormat, "json", "text"), "LOG_FORMAT %q is not json or text", cfg.LogFormat)
+
+	check(oneOf(cfg.TracingExporter, "", "none", "otlp", "stdout", "file"), "TRACING_EXPORTER %q is not none, otlp, stdout or file", cfg.TracingExporter)
+	check(cfg.TracingExporter != "file" || len(cfg.TracingFile) > 0, "TRACING_FILE is required for the file exporter")
+	check(cfg.TracingSampleRatio >= 0 && cfg.TracingSampleRatio <= 1, "TRACING_SAMPLE_RATIO %v is not between 0 and 1", cfg.TracingSampleRatio)
+
+	check((len(cfg.TLSCertFile) > 0) == (len(cfg.TLSKeyFile) > 0), "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+
+	for _, d := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"DB_READ_TIMEOUT", cfg.DBReadTimeout},
+		{"DB_WRITE_TIMEOUT", cfg.DBWriteTimeout},
+		{"DB_UPLOAD_TIMEOUT", cfg.DBUploadTimeout},
+		{"DB_CONN_MAX_LIFETIME", cfg.DBConnMaxLifetime},
+		{"DB_CONN_MAX_IDLE_TIME", cfg.DBConnMaxIdleTime},
+		{"HTTP_READ_TIMEOUT", cfg.HTTPReadTimeout},
+		{"HTTP_WRITE_TIMEOUT", cfg.HTTPWriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", cfg.HTTPIdleTimeout},
+		{"SHUTDOWN_TIMEOUT", cfg.ShutdownTimeout},
+	} {
+		check(d.value >= 0, "%s must not be negative", d.key)
+	}
+	check(cfg.DBMaxOpenConns >= 0, "DB_MAX_OPEN_CONNS must not be negative")
+	check(cfg.DBMaxIdleConns >= 0, "DB_MAX_IDLE_CONNS must not be negative")
+
+	check(len(cfg.JWTSecret) == 0 || len(cfg.JWTSecret) >= 32, "JWT_SECRET must be at least 32 bytes")
+	check(cfg.JWTAccessTTL > 0, "JWT_ACCESS_TTL must be positive")
+	check(cfg.JWTRefreshTTL > 0, "JWT_REFRESH_TTL must be positive")
+
+	for _, r := range []struct {
+		key       string
+		perMinute float64
+		burst     int
+	}{
+		{"RATE_LIMIT_IP", cfg.RateLimitIPPerMinute, cfg.RateLimitIPBurst},
+		{"RATE_LIMIT_READ", cfg.RateLimitReadPerMinute, cfg.RateLimitReadBurst},
+		{"RATE_LIMIT_WRITE", cfg.RateLimitWritePerMinute, cfg.RateLimitWriteBurst},
+		{"RATE_LIMIT_UPLOAD", cfg.RateLimitUploadPerMinute, cfg.RateLimitUploadBurst},
+	} {
+		check(r.perMinute >= 0, "%s_PER_MINUTE must not be negative", r.key)
+		check(r.perMinute == 0 || r.burst >= 1, "%s_BURST must be at least 1", r.key)
+	}
+
+	check(!cfg.CacheEnabled || cfg.CacheSize > 0, "CACHE_SIZE must be positive")
+	check(!cfg.CacheEnabled || cfg.CacheTTL > 0, "CACHE_TTL must be positive")
+
+	check(cfg.CompressionMinSize >= 0, "COMPRESSION_MIN_SIZE must not be negative")
+
+	check(len(cfg.Languages) > 0, "LANGUAGES is required")
+	check(oneOf(cfg.DefaultLanguage, cfg.Languages...), "DEFAULT_LANGUAGE %q is not one of LANGUAGES", cfg.DefaultLanguage)
+
+	check(len(cfg.UploadDir) > 0, "UPLOAD_DIR is required")
+	check(len(cfg.UploadTempDir) > 0, "UPLOAD_TEMP_DIR is required")
+	check(cfg.UploadMaxSize > 0, "UPLOAD_MAX_SIZE must be positive")
+	check(cfg.ImageMaxSize > 0, "IMAGE_MAX_SIZE must be positive")
+	check(cfg.ThumbnailSize > 0, "THUMBNAIL_SIZE must be positive")
+
+	return errors.Join(errs...)
+}
+
+// Print writes the effective configuration as a YAML file that Load
+// accepts, with secrets masked and the source of every value.
+func (cfg *Config) Print(w io.Writer) error {
+	for _, s := range cfg.settings {
+		value := s.value
+		if s.secret {
+			value = mask(value)
+		}
+
+		_, err := fmt.Fprintf(w, "%s: %s # %s\n", strings.ToLower(s.key), strconv.Quote(value), s.from)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n >= 0 && n <= 65535
+}
+
+func oneOf(val string, set ...string) bool {
+	for _, s := range set {
+		if val == s {
+			return true
+		}
+	}
+	return false
+}
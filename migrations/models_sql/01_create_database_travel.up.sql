@@ -1,2 +0,0 @@
-
-CREATE DATABASE essy_travel;
\ No newline at end of file
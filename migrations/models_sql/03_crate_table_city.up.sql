@@ -11,40 +11,3 @@ CREATE TABLE city(
   "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
   "updated_at" TIMESTAMP
 );
-
-
-
-
-jq -c '.[]' city.json >> your_data.json
-
-
-\copy temp(data) from './mock_data/your_data.json';
-
-
-INSERT INTO city (
-  "title",
-  "country_id",
-  "city_code",
-  "latitude",
-  "longitude",
-  "offset",
-  "timezone_id",
-  "country_name",
-  "updated_at"
-)
-SELECT
-  data ->> 'title',
-  CAST(data ->> 'country_id' AS UUID),
-  data ->> 'city_code',
-  data ->> 'latitude',
-  data ->> 'longitude',
-  data ->> 'offset',
-  CAST(data ->> 'timezone_id' AS UUID),
-  data ->> 'country_name',
-  now()
-FROM
-  temp;
-
-
-DELETE FROM temp WHERE length(data ->> 'country_id') = 2;
-
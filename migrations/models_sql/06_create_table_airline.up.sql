@@ -0,0 +1,18 @@
+CREATE TABLE airline(
+  "guid" UUID PRIMARY KEY,
+  "iata_code" VARCHAR(2),
+  "icao_code" VARCHAR(3),
+  "title" VARCHAR(128),
+  "country_id" UUID REFERENCES country("guid"),
+  "active" BOOLEAN NOT NULL DEFAULT TRUE,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP
+);
+
+CREATE TABLE airline_airport(
+  "airline_id" UUID NOT NULL REFERENCES airline("guid") ON DELETE CASCADE,
+  "airport_id" UUID NOT NULL REFERENCES airport("guid") ON DELETE CASCADE,
+  "type" VARCHAR(8) NOT NULL DEFAULT 'hub' CHECK ("type" IN ('hub', 'base')),
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("airline_id", "airport_id")
+);
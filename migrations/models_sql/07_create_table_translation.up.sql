@@ -0,0 +1,10 @@
+CREATE TABLE translation(
+  "guid" UUID PRIMARY KEY,
+  "entity" VARCHAR(16) NOT NULL CHECK ("entity" IN ('country', 'city', 'airport')),
+  "entity_id" UUID NOT NULL,
+  "locale" VARCHAR(8) NOT NULL,
+  "title" VARCHAR(128) NOT NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP,
+  UNIQUE ("entity", "entity_id", "locale")
+);
@@ -0,0 +1,12 @@
+ALTER TABLE airport ADD COLUMN "search_text" VARCHAR(128);
+
+UPDATE airport SET "search_text" = a."title"
+FROM (
+  SELECT DISTINCT ON ("entity_id") "entity_id", "title"
+  FROM alias
+  WHERE "entity" = 'airport'
+  ORDER BY "entity_id", "created_at"
+) AS a
+WHERE a."entity_id" = airport."guid";
+
+DROP TABLE alias;
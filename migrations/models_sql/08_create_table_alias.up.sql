@@ -0,0 +1,18 @@
+CREATE TABLE alias(
+  "guid" UUID PRIMARY KEY,
+  "entity" VARCHAR(16) NOT NULL CHECK ("entity" IN ('city', 'airport')),
+  "entity_id" UUID NOT NULL,
+  "title" VARCHAR(128) NOT NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE ("entity", "entity_id", "title")
+);
+
+CREATE INDEX alias_title_idx ON alias(LOWER("title"));
+
+INSERT INTO alias("guid", "entity", "entity_id", "title")
+SELECT gen_random_uuid(), 'airport', "guid", "search_text"
+FROM airport
+WHERE COALESCE("search_text", '') <> ''
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE airport DROP COLUMN "search_text";
@@ -0,0 +1,11 @@
+CREATE TABLE users(
+  "guid" UUID PRIMARY KEY,
+  "login" VARCHAR(30) NOT NULL UNIQUE,
+  "email" VARCHAR(255) NOT NULL UNIQUE,
+  "phone" VARCHAR(16),
+  "full_name" VARCHAR(128),
+  "role" VARCHAR(16) NOT NULL DEFAULT 'reader' CHECK ("role" IN ('reader', 'editor', 'admin')),
+  "password_hash" VARCHAR(72) NOT NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP
+);
@@ -0,0 +1,3 @@
+ALTER TABLE country ADD COLUMN "version" INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE city ADD COLUMN "version" INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE airport ADD COLUMN "version" INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,320 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// ErrDirty is returned when a previous migration failed half way on a
+// database that could not run it in a transaction. The schema has to be
+// repaired by hand before migrating again.
+var ErrDirty = errors.New("migrations: database is dirty")
+
+// Runner applies migrations to a database.
+type Runner struct {
+	db         *sql.DB
+	migrations []Migration
+	exists     string
+
+	lockQuery, unlockQuery string
+}
+
+// conn runs the statements of a runner: the pool, or the one connection
+// holding the migration lock.
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Status tells whether a migration has been applied.
+type Status struct {
+	Migration
+	Applied bool
+}
+
+func NewRunner(db *sql.DB, fsys fs.FS) (*Runner, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, unlock := lockQueries(fsys)
+
+	return &Runner{
+		db:         db,
+		migrations: migrations,
+		exists:     existsQuery(fsys),
+
+		lockQuery:   lock,
+		unlockQuery: unlock,
+	}, nil
+}
+
+// existsQuery returns the catalog query telling whether schema_migrations
+// exists in the databases fsys migrates, or "" when it is not known.
+func existsQuery(fsys fs.FS) string {
+	switch fsys {
+	case Postgres:
+		return `SELECT to_regclass('schema_migrations') IS NOT NULL`
+	case SQLite:
+		return `SELECT COUNT(*) > 0 FROM sqlite_master WHERE "type" = 'table' AND "name" = 'schema_migrations'`
+	}
+	return ""
+}
+
+// lockQueries returns the statements taking and releasing the lock that
+// keeps two runners, such as two instances starting with AUTO_MIGRATE,
+// from migrating the same database at once, or "" when there is no such
+// lock. SQLite serializes the transactions of migrations itself.
+func lockQueries(fsys fs.FS) (lock, unlock string) {
+	if fsys == Postgres {
+		// The key is arbitrary; it only has to be the same for every
+		// runner and unlike the keys of other advisory locks.
+		return `SELECT pg_advisory_lock(7327451361982)`, `SELECT pg_advisory_unlock(7327451361982)`
+	}
+	return "", ""
+}
+
+// lock takes the migration lock, waiting for a runner holding it to be
+// done, and returns the connection holding it, on which to migrate so
+// that a pool of one connection does not wait for itself. The lock is
+// held until unlock.
+func (r *Runner) lock(ctx context.Context) (c conn, unlock func(), err error) {
+	if len(r.lockQuery) == 0 {
+		return r.db, func() {}, nil
+	}
+
+	locked, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = locked.ExecContext(ctx, r.lockQuery)
+	if err != nil {
+		locked.Close()
+		return nil, nil, fmt.Errorf("migrations: lock: %w", err)
+	}
+
+	return locked, func() {
+		// The connection goes back to the pool, which would keep the lock
+		// with it.
+		_, _ = locked.ExecContext(context.Background(), r.unlockQuery)
+		locked.Close()
+	}, nil
+}
+
+// Version returns the version the database is at; 0 means no migration
+// has been applied. It only reads, so that readiness probes may call it
+// on a replica or with a read-only role.
+func (r *Runner) Version(ctx context.Context) (uint, bool, error) {
+	return r.version(ctx, r.db)
+}
+
+func (r *Runner) version(ctx context.Context, c conn) (uint, bool, error) {
+	if len(r.exists) > 0 {
+		var exists bool
+		err := c.QueryRowContext(ctx, r.exists).Scan(&exists)
+		if err != nil {
+			return 0, false, err
+		}
+		if !exists {
+			return 0, false, nil
+		}
+	}
+
+	var (
+		version int64
+		dirty   bool
+	)
+
+	err := c.QueryRowContext(ctx, `SELECT "version", "dirty" FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+
+	return uint(version), dirty, nil
+}
+
+// createTable creates schema_migrations before the first migration.
+func (r *Runner) createTable(ctx context.Context, c conn) error {
+	_, err := c.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations(
+			"version" BIGINT NOT NULL PRIMARY KEY,
+			"dirty" BOOLEAN NOT NULL
+		)`)
+	return err
+}
+
+// Latest returns the version of the newest migration.
+func (r *Runner) Latest() uint {
+	if len(r.migrations) == 0 {
+		return 0
+	}
+	return r.migrations[len(r.migrations)-1].Version
+}
+
+// Status lists every known migration and whether it has been applied.
+func (r *Runner) Status(ctx context.Context) (uint, []Status, error) {
+	version, dirty, err := r.Version(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var statuses []Status
+	for _, m := range r.migrations {
+		statuses = append(statuses, Status{Migration: m, Applied: m.Version <= version})
+	}
+
+	if dirty {
+		return version, statuses, fmt.Errorf("%w at version %d", ErrDirty, version)
+	}
+
+	return version, statuses, nil
+}
+
+// Up applies every migration that has not been applied yet.
+func (r *Runner) Up(ctx context.Context) error {
+	if len(r.migrations) == 0 {
+		return nil
+	}
+
+	return r.Goto(ctx, r.Latest())
+}
+
+// Down rolls back the last steps applied migrations.
+func (r *Runner) Down(ctx context.Context, steps int) error {
+	c, unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	version, _, err := r.version(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	target := uint(0)
+	for i := len(r.migrations) - 1; i >= 0; i-- {
+		if r.migrations[i].Version > version {
+			continue
+		}
+
+		if steps == 0 {
+			target = r.migrations[i].Version
+			break
+		}
+		steps--
+	}
+
+	return r.migrate(ctx, c, target)
+}
+
+// Goto migrates up or down until the database is at version. Version 0
+// rolls back every migration.
+func (r *Runner) Goto(ctx context.Context, version uint) error {
+	c, unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return r.migrate(ctx, c, version)
+}
+
+// migrate does the work of Goto with the lock held. The version is read
+// again under the lock, so a runner that waited for another one finds
+// the migrations it applied.
+func (r *Runner) migrate(ctx context.Context, c conn, version uint) error {
+	if version != 0 && r.find(version) < 0 {
+		return fmt.Errorf("migration %d does not exist", version)
+	}
+
+	err := r.createTable(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	current, dirty, err := r.version(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("%w at version %d", ErrDirty, current)
+	}
+
+	if current > 0 && r.find(current) < 0 {
+		return fmt.Errorf("database is at version %d which has no migration", current)
+	}
+
+	for _, m := range r.migrations {
+		if m.Version > current && m.Version <= version {
+			err = r.apply(ctx, c, m.Up, m.Version, fmt.Sprintf("%d_%s up", m.Version, m.Name))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := len(r.migrations) - 1; i >= 0; i-- {
+		m := r.migrations[i]
+		if m.Version <= current && m.Version > version {
+			var previous uint
+			if i > 0 {
+				previous = r.migrations[i-1].Version
+			}
+
+			err = r.apply(ctx, c, m.Down, previous, fmt.Sprintf("%d_%s down", m.Version, m.Name))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// apply runs body and records version in one transaction, so a failing
+// migration leaves the database as it was.
+func (r *Runner) apply(ctx context.Context, c conn, body string, version uint, name string) error {
+	tx, err := c.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, body)
+	if err != nil {
+		return fmt.Errorf("migration %s: %w", name, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations`)
+	if err != nil {
+		return err
+	}
+
+	if version > 0 {
+		_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations("version", "dirty") VALUES($1, $2)`, int64(version), false)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *Runner) find(version uint) int {
+	for i, m := range r.migrations {
+		if m.Version == version {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,8 @@
+CREATE TABLE country(
+  "guid" TEXT PRIMARY KEY,
+  "title" VARCHAR(64),
+  "code" VARCHAR(12),
+  "continent" VARCHAR(12),
+  "created_at" TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+  "updated_at" TEXT
+);
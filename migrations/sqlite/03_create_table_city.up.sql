@@ -0,0 +1,13 @@
+CREATE TABLE city(
+  "guid" TEXT PRIMARY KEY,
+  "title" VARCHAR(48),
+  "country_id" TEXT,
+  "city_code" VARCHAR(120),
+  "latitude" VARCHAR(120),
+  "longitude" VARCHAR(120),
+  "offset" VARCHAR(120),
+  "timezone_id" TEXT,
+  "country_name" VARCHAR(128),
+  "created_at" TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+  "updated_at" TEXT
+);
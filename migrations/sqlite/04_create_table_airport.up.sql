@@ -0,0 +1,19 @@
+CREATE TABLE airport(
+  "guid" TEXT PRIMARY KEY,
+  "title" VARCHAR(32),
+  "country_id" TEXT REFERENCES country("guid"),
+  "city_id" TEXT REFERENCES city("guid"),
+  "latitude" FLOAT,
+  "longitude" FLOAT,
+  "radius" FLOAT,
+  "image" VARCHAR(256),
+  "adress" VARCHAR(256),
+  "timezone_id" TEXT,
+  "country" VARCHAR(128),
+  "city" VARCHAR(64),
+  "code" VARCHAR(32),
+  "product_count" INT,
+  "gmt" VARCHAR(64),
+  "created_at" TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+  "updated_at" TEXT
+);
@@ -0,0 +1,14 @@
+CREATE TABLE route(
+  "guid" TEXT PRIMARY KEY,
+  "origin_airport_id" TEXT NOT NULL REFERENCES airport("guid") ON DELETE CASCADE,
+  "destination_airport_id" TEXT NOT NULL REFERENCES airport("guid") ON DELETE CASCADE,
+  "carrier_code" VARCHAR(8),
+  "distance" FLOAT,
+  "active" BOOLEAN NOT NULL DEFAULT TRUE,
+  "created_at" TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+  "updated_at" TEXT,
+  CHECK ("origin_airport_id" <> "destination_airport_id")
+);
+
+CREATE INDEX route_origin_airport_id_idx ON route("origin_airport_id");
+CREATE INDEX route_destination_airport_id_idx ON route("destination_airport_id");
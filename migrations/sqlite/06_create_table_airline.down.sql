@@ -0,0 +1,2 @@
+DROP TABLE airline_airport;
+DROP TABLE airline;
@@ -0,0 +1,18 @@
+CREATE TABLE airline(
+  "guid" TEXT PRIMARY KEY,
+  "iata_code" VARCHAR(2),
+  "icao_code" VARCHAR(3),
+  "title" VARCHAR(128),
+  "country_id" TEXT REFERENCES country("guid"),
+  "active" BOOLEAN NOT NULL DEFAULT TRUE,
+  "created_at" TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+  "updated_at" TEXT
+);
+
+CREATE TABLE airline_airport(
+  "airline_id" TEXT NOT NULL REFERENCES airline("guid") ON DELETE CASCADE,
+  "airport_id" TEXT NOT NULL REFERENCES airport("guid") ON DELETE CASCADE,
+  "type" VARCHAR(8) NOT NULL DEFAULT 'hub' CHECK ("type" IN ('hub', 'base')),
+  "created_at" TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+  PRIMARY KEY ("airline_id", "airport_id")
+);
@@ -0,0 +1,10 @@
+CREATE TABLE translation(
+  "guid" TEXT PRIMARY KEY,
+  "entity" VARCHAR(16) NOT NULL CHECK ("entity" IN ('country', 'city', 'airport')),
+  "entity_id" TEXT NOT NULL,
+  "locale" VARCHAR(8) NOT NULL,
+  "title" VARCHAR(128) NOT NULL,
+  "created_at" TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+  "updated_at" TEXT,
+  UNIQUE ("entity", "entity_id", "locale")
+);
@@ -0,0 +1,10 @@
+CREATE TABLE alias(
+  "guid" TEXT PRIMARY KEY,
+  "entity" VARCHAR(16) NOT NULL CHECK ("entity" IN ('city', 'airport')),
+  "entity_id" TEXT NOT NULL,
+  "title" VARCHAR(128) NOT NULL,
+  "created_at" TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+  UNIQUE ("entity", "entity_id", "title")
+);
+
+CREATE INDEX alias_title_idx ON alias(LOWER("title"));
@@ -0,0 +1,9 @@
+CREATE TABLE api_key(
+  "guid" TEXT PRIMARY KEY,
+  "name" VARCHAR(64) NOT NULL,
+  "role" VARCHAR(16) NOT NULL CHECK ("role" IN ('reader', 'editor', 'admin')),
+  "prefix" VARCHAR(16) NOT NULL,
+  "hash" CHAR(64) NOT NULL UNIQUE,
+  "created_at" TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+  "revoked_at" TEXT
+);
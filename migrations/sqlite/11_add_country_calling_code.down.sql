@@ -0,0 +1,3 @@
+DROP INDEX country_calling_code_idx;
+
+ALTER TABLE country DROP COLUMN "calling_code";
@@ -0,0 +1,3 @@
+ALTER TABLE airport DROP COLUMN "version";
+ALTER TABLE city DROP COLUMN "version";
+ALTER TABLE country DROP COLUMN "version";
@@ -0,0 +1,71 @@
+package models
+
+type Airline struct {
+	Guid      string `json:"guid"`
+	IataCode  string `json:"iata_code"`
+	IcaoCode  string `json:"icao_code"`
+	Title     string `json:"title"`
+	CountryId string `json:"country_id"`
+	Active    bool   `json:"active"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type CreateAirline struct {
+	IataCode  string `json:"iata_code"`
+	IcaoCode  string `json:"icao_code"`
+	Title     string `json:"title"`
+	CountryId string `json:"country_id"`
+	Active    bool   `json:"active"`
+}
+
+type UpdateAirline struct {
+	Guid      string `json:"guid"`
+	IataCode  string `json:"iata_code"`
+	IcaoCode  string `json:"icao_code"`
+	Title     string `json:"title"`
+	CountryId string `json:"country_id"`
+	Active    bool   `json:"active"`
+}
+
+type AirlinePrimaryKey struct {
+	Guid string `json:"guid"`
+}
+
+type GetListAirlineRequest struct {
+	Offset     int    `json:"offset"`
+	Limit      int    `json:"limit"`
+	Search     string `json:"search"`
+	CountryId  string `json:"country_id"`
+	OnlyActive bool   `json:"only_active"`
+}
+
+type GetListAirlineResponse struct {
+	Count    int       `json:"count"`
+	Airlines []Airline `json:"airlines"`
+}
+
+// AirlineAirport links an airline to one of its hubs or bases.
+type AirlineAirport struct {
+	AirlineId string `json:"airline_id"`
+	AirportId string `json:"airport_id"`
+	Type      string `json:"type"`
+	Airport   string `json:"airport"`
+	CreatedAt string `json:"created_at"`
+}
+
+type CreateAirlineAirport struct {
+	AirlineId string `json:"airline_id"`
+	AirportId string `json:"airport_id"`
+	Type      string `json:"type"`
+}
+
+type AirlineAirportPrimaryKey struct {
+	AirlineId string `json:"airline_id"`
+	AirportId string `json:"airport_id"`
+}
+
+type GetListAirlineAirportResponse struct {
+	Count    int              `json:"count"`
+	Airports []AirlineAirport `json:"airports"`
+}
@@ -13,12 +13,12 @@ type Airport struct {
 	TimezoneId   string  `json:"timezone_id"`
 	Country      string  `json:"country"`
 	City         string  `json:"city"`
-	SearchText   string  `json:"search_text"`
 	Code         string  `json:"code"`
 	ProductCount int     `json:"product_count"`
 	Gmt          string  `json:"gmt"`
 	CreatedAt    string  `json:"created_at"`
 	UpdatedAt    string  `json:"updated_at"`
+	Version      int     `json:"version"`
 }
 
 type CreateAirport struct {
@@ -28,12 +28,10 @@ type CreateAirport struct {
 	Latitude     float64 `json:"latitude"`
 	Longitude    float64 `json:"longitude"`
 	Radius       float64 `json:"radius"`
-	Image        string  `json:"image"`
 	Adress       string  `json:"adress"`
 	TimezoneId   string  `json:"timezone_id"`
 	Country      string  `json:"country"`
 	City         string  `json:"city"`
-	SearchText   string  `json:"search_text"`
 	Code         string  `json:"code"`
 	ProductCount int     `json:"product_count"`
 	Gmt          string  `json:"gmt"`
@@ -47,15 +45,27 @@ type UpdateAirport struct {
 	Latitude     float64 `json:"latitude"`
 	Longitude    float64 `json:"longitude"`
 	Radius       float64 `json:"radius"`
-	Image        string  `json:"image"`
 	Adress       string  `json:"adress"`
 	TimezoneId   string  `json:"timezone_id"`
 	Country      string  `json:"country"`
 	City         string  `json:"city"`
-	SearchText   string  `json:"search_text"`
 	Code         string  `json:"code"`
 	ProductCount int     `json:"product_count"`
 	Gmt          string  `json:"gmt"`
+
+	// Version, when set, must be the stored version or the update fails
+	// with a conflict. 0 updates whatever is stored.
+	Version int `json:"version"`
+}
+
+type UpdateAirportImage struct {
+	Guid  string `json:"guid"`
+	Image string `json:"image"`
+
+	// OldImage must be the stored image, "" for none, or the update fails
+	// with a conflict, so that of two uploads at once only one replaces
+	// the image the other one read.
+	OldImage string `json:"old_image"`
 }
 
 type AirportPrimaryKey struct {
@@ -63,8 +73,9 @@ type AirportPrimaryKey struct {
 }
 
 type GetListAirportRequest struct {
-	Offset int `json:"offset"`
-	Limit  int `json:"limit"`
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+	Search string `json:"search"`
 }
 
 type GetListAirportResponse struct {
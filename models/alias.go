@@ -0,0 +1,33 @@
+package models
+
+// Alias is an alternative name a city or airport is also known by,
+// e.g. "Bombay" for Mumbai or "SVO" for Sheremetyevo.
+type Alias struct {
+	Guid      string `json:"guid"`
+	Entity    string `json:"entity"`
+	EntityId  string `json:"entity_id"`
+	Title     string `json:"title"`
+	CreatedAt string `json:"created_at"`
+}
+
+type CreateAlias struct {
+	Entity   string `json:"entity"`
+	EntityId string `json:"entity_id"`
+	Title    string `json:"title"`
+}
+
+type AliasPrimaryKey struct {
+	Guid string `json:"guid"`
+}
+
+type GetListAliasRequest struct {
+	Offset   int    `json:"offset"`
+	Limit    int    `json:"limit"`
+	Entity   string `json:"entity"`
+	EntityId string `json:"entity_id"`
+}
+
+type GetListAliasResponse struct {
+	Count   int     `json:"count"`
+	Aliases []Alias `json:"aliases"`
+}
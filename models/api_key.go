@@ -0,0 +1,45 @@
+package models
+
+// ApiKey authenticates a client with a role. Only the SHA-256 hash of the
+// key is stored; the key itself is shown once, when it is created.
+type ApiKey struct {
+	Guid      string `json:"guid"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	Prefix    string `json:"prefix"`
+	CreatedAt string `json:"created_at"`
+	RevokedAt string `json:"revoked_at"`
+}
+
+type CreateApiKey struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+
+	Prefix string `json:"-"`
+	Hash   string `json:"-"`
+}
+
+// CreatedApiKey is returned once, on creation, with the plain key.
+type CreatedApiKey struct {
+	ApiKey
+	Key string `json:"key"`
+}
+
+type ApiKeyPrimaryKey struct {
+	Guid string `json:"guid"`
+}
+
+type ApiKeyHash struct {
+	Hash string `json:"-"`
+}
+
+type GetListApiKeyRequest struct {
+	Offset         int  `json:"offset"`
+	Limit          int  `json:"limit"`
+	IncludeRevoked bool `json:"include_revoked"`
+}
+
+type GetListApiKeyResponse struct {
+	Count   int      `json:"count"`
+	ApiKeys []ApiKey `json:"api_keys"`
+}
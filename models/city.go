@@ -12,6 +12,7 @@ type City struct {
 	CountryName string `json:"country_name"`
 	CreatedAt   string `json:"created_at"`
 	UpdatedAt   string `json:"updated_at"`
+	Version     int    `json:"version"`
 }
 
 type CreateCity struct {
@@ -35,6 +36,10 @@ type UpdateCity struct {
 	Offset      string `json:"offset"`
 	TimezoneId  string `json:"timezone_id"`
 	CountryName string `json:"country_name"`
+
+	// Version, when set, must be the stored version or the update fails
+	// with a conflict. 0 updates whatever is stored.
+	Version int `json:"version"`
 }
 
 type CityPrimaryKey struct {
@@ -42,8 +47,9 @@ type CityPrimaryKey struct {
 }
 
 type GetListCityRequest struct {
-	Offset int `json:"offset"`
-	Limit  int `json:"limit"`
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+	Search string `json:"search"`
 }
 
 type GetListCityResponse struct {
@@ -1,25 +1,36 @@
 package models
 
+// Country is a country; CallingCode is its international calling code
+// without the "+", e.g. "998" for Uzbekistan, which phone numbers are
+// checked against.
 type Country struct {
-	Guid      string `json:"guid"`
-	Title     string `json:"title"`
-	Code      string `json:"code"`
-	Continent string `json:"continent"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	Guid        string `json:"guid"`
+	Title       string `json:"title"`
+	Code        string `json:"code"`
+	Continent   string `json:"continent"`
+	CallingCode string `json:"calling_code"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+	Version     int    `json:"version"`
 }
 
 type CreateCountry struct {
-	Title     string `json:"title"`
-	Code      string `json:"code"`
-	Continent string `json:"continent"`
+	Title       string `json:"title"`
+	Code        string `json:"code"`
+	Continent   string `json:"continent"`
+	CallingCode string `json:"calling_code"`
 }
 
 type UpdateCountry struct {
-	Guid      string `json:"guid"`
-	Title     string `json:"title"`
-	Code      string `json:"code"`
-	Continent string `json:"continent"`
+	Guid        string `json:"guid"`
+	Title       string `json:"title"`
+	Code        string `json:"code"`
+	Continent   string `json:"continent"`
+	CallingCode string `json:"calling_code"`
+
+	// Version, when set, must be the stored version or the update fails
+	// with a conflict. 0 updates whatever is stored.
+	Version int `json:"version"`
 }
 
 type CountryPrimaryKey struct {
@@ -27,8 +38,9 @@ type CountryPrimaryKey struct {
 }
 
 type GetListCountryRequest struct {
-	Offset int `json:"offset"`
-	Limit  int `json:"limit"`
+	Offset      int    `json:"offset"`
+	Limit       int    `json:"limit"`
+	CallingCode string `json:"calling_code"`
 }
 
 type GetListCountryResponse struct {
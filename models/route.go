@@ -0,0 +1,57 @@
+package models
+
+type Route struct {
+	Guid                 string  `json:"guid"`
+	OriginAirportId      string  `json:"origin_airport_id"`
+	DestinationAirportId string  `json:"destination_airport_id"`
+	CarrierCode          string  `json:"carrier_code"`
+	Distance             float64 `json:"distance"`
+	Active               bool    `json:"active"`
+	OriginAirport        string  `json:"origin_airport"`
+	DestinationAirport   string  `json:"destination_airport"`
+	CreatedAt            string  `json:"created_at"`
+	UpdatedAt            string  `json:"updated_at"`
+}
+
+type CreateRoute struct {
+	OriginAirportId      string  `json:"origin_airport_id"`
+	DestinationAirportId string  `json:"destination_airport_id"`
+	CarrierCode          string  `json:"carrier_code"`
+	Distance             float64 `json:"distance"`
+	Active               *bool   `json:"active"` // true when left out
+}
+
+type UpdateRoute struct {
+	Guid                 string  `json:"guid"`
+	OriginAirportId      string  `json:"origin_airport_id"`
+	DestinationAirportId string  `json:"destination_airport_id"`
+	CarrierCode          string  `json:"carrier_code"`
+	Distance             float64 `json:"distance"`
+	Active               bool    `json:"active"`
+}
+
+type RoutePrimaryKey struct {
+	Guid string `json:"guid"`
+}
+
+type GetListRouteRequest struct {
+	Offset               int    `json:"offset"`
+	Limit                int    `json:"limit"`
+	OriginAirportId      string `json:"origin_airport_id"`
+	DestinationAirportId string `json:"destination_airport_id"`
+	OriginCityId         string `json:"origin_city_id"`
+	DestinationCityId    string `json:"destination_city_id"`
+	OnlyActive           bool   `json:"only_active"`
+}
+
+type GetListRouteResponse struct {
+	Count  int     `json:"count"`
+	Routes []Route `json:"routes"`
+}
+
+type GetDestinationsRequest struct {
+	AirportId  string `json:"airport_id"`
+	Offset     int    `json:"offset"`
+	Limit      int    `json:"limit"`
+	OnlyActive bool   `json:"only_active"`
+}
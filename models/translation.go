@@ -0,0 +1,50 @@
+package models
+
+// Translation holds the title of a country, city or airport in one locale.
+type Translation struct {
+	Guid      string `json:"guid"`
+	Entity    string `json:"entity"`
+	EntityId  string `json:"entity_id"`
+	Locale    string `json:"locale"`
+	Title     string `json:"title"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type CreateTranslation struct {
+	Entity   string `json:"entity"`
+	EntityId string `json:"entity_id"`
+	Locale   string `json:"locale"`
+	Title    string `json:"title"`
+}
+
+type UpdateTranslation struct {
+	Guid   string `json:"guid"`
+	Locale string `json:"locale"`
+	Title  string `json:"title"`
+}
+
+type TranslationPrimaryKey struct {
+	Guid string `json:"guid"`
+}
+
+type GetListTranslationRequest struct {
+	Offset   int    `json:"offset"`
+	Limit    int    `json:"limit"`
+	Entity   string `json:"entity"`
+	EntityId string `json:"entity_id"`
+	Locale   string `json:"locale"`
+}
+
+type GetListTranslationResponse struct {
+	Count        int           `json:"count"`
+	Translations []Translation `json:"translations"`
+}
+
+// GetTitlesRequest asks for the best translated title of every entity
+// in EntityIds, trying Locales in order.
+type GetTitlesRequest struct {
+	Entity    string   `json:"entity"`
+	EntityIds []string `json:"entity_ids"`
+	Locales   []string `json:"locales"`
+}
@@ -0,0 +1,92 @@
+package models
+
+// User is an account that signs in with a login or email and a password
+// and acts with its role. The password is stored as a bcrypt hash only.
+type User struct {
+	Guid      string `json:"guid"`
+	Login     string `json:"login"`
+	Email     string `json:"email"`
+	Phone     string `json:"phone"`
+	FullName  string `json:"full_name"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+
+	PasswordHash string `json:"-"`
+	// TokenVersion goes up with every password change; refresh tokens
+	// signed for an older version are no longer accepted.
+	TokenVersion int `json:"-"`
+}
+
+type CreateUser struct {
+	Login    string `json:"login"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	FullName string `json:"full_name"`
+	Password string `json:"password"`
+
+	Role         string `json:"-"`
+	PasswordHash string `json:"-"`
+}
+
+// UpdateUser changes the profile of a user; the login, the role and the
+// password have their own requests.
+type UpdateUser struct {
+	Guid     string `json:"-"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	FullName string `json:"full_name"`
+}
+
+type UpdateUserPassword struct {
+	Guid         string `json:"-"`
+	PasswordHash string `json:"-"`
+}
+
+type UpdateUserRole struct {
+	Guid string `json:"guid"`
+	Role string `json:"role"`
+}
+
+type ChangePassword struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+type UserPrimaryKey struct {
+	Guid string `json:"guid"`
+}
+
+// UserLoginKey finds a user by login or by email.
+type UserLoginKey struct {
+	Login string `json:"login"`
+}
+
+type GetListUserRequest struct {
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+	Search string `json:"search"`
+}
+
+type GetListUserResponse struct {
+	Count int    `json:"count"`
+	Users []User `json:"users"`
+}
+
+type Login struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+type RefreshToken struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenPair is returned on login and refresh. ExpiresIn is the lifetime of
+// the access token in seconds.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
@@ -0,0 +1,103 @@
+// Package auth issues the API keys of the service, ranks the roles they
+// grant and carries the authenticated caller through request contexts.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// Role is what a caller may do. Every role may do what the roles below it
+// may.
+type Role string
+
+const (
+	RoleReader Role = "reader"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+var ranks = map[Role]int{
+	RoleReader: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// ParseRole returns the role named s.
+func ParseRole(s string) (Role, error) {
+	if _, ok := ranks[Role(s)]; !ok {
+		return "", fmt.Errorf("role %q is not reader, editor or admin", s)
+	}
+	return Role(s), nil
+}
+
+// Allows reports whether r may do what required may.
+func (r Role) Allows(required Role) bool {
+	rank, ok := ranks[r]
+	return ok && rank >= ranks[required]
+}
+
+// keyPrefix marks the keys of the service, so that a leaked one is easy to
+// recognise.
+const keyPrefix = "et_"
+
+// NewKey returns a random key, the prefix that identifies it in listings
+// and the hash to store. The key itself is shown once and never stored.
+func NewKey() (key, prefix, hash string, err error) {
+	var secret = make([]byte, 32)
+	_, err = rand.Read(secret)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	key = keyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	return key, key[:len(keyPrefix)+8], HashKey(key), nil
+}
+
+// HashKey returns the hash a key is stored and looked up by. Keys are long
+// and random, so a plain SHA-256 is enough and keeps lookups cheap.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// How a caller authenticated.
+const (
+	MethodApiKey = "api_key"
+	MethodBearer = "bearer"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject is the guid of the key or of the user.
+	Subject string
+	Name    string
+	Role    Role
+	Method  string
+	// TokenVersion is the token version of the user a token was signed
+	// for; zero for API keys.
+	TokenVersion int
+}
+
+// IsUser reports whether the caller is a user rather than an API key.
+func (p Principal) IsUser() bool {
+	return p.Method == MethodBearer
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the caller carried by ctx, if it was authenticated.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Passwords longer than bcrypt reads are refused rather than cut short.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+// HashPassword returns the bcrypt hash of password.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// CheckPassword reports whether password matches hash. An empty hash
+// never matches but takes as long to check as a real one, so that a
+// missing user cannot be told apart by the time a login takes.
+func CheckPassword(hash, password string) bool {
+	if len(hash) == 0 {
+		bcrypt.CompareHashAndPassword([]byte(dummyHash()), []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// dummyHash is the hash of a random password at the default cost, made on
+// first use.
+var dummyHash = sync.OnceValue(func() string {
+	key, _, _, _ := NewKey()
+	hash, _ := HashPassword(key[:MaxPasswordLength/2])
+	return hash
+})
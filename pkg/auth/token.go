@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// The kinds of token. A refresh token is only good for getting a new pair
+// of tokens, never for calling the API.
+const (
+	TokenAccess  = "access"
+	TokenRefresh = "refresh"
+)
+
+// ErrInvalidToken is returned for a token that is malformed, badly signed,
+// expired or of the wrong kind.
+var ErrInvalidToken = errors.New("auth: invalid token")
+
+type claims struct {
+	jwt.RegisteredClaims
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+	Kind string `json:"kind"`
+	// Version is the token version of the user when the token was signed.
+	Version int `json:"ver,omitempty"`
+}
+
+// Tokens issues and verifies the JWTs of users, signed with HS256.
+type Tokens struct {
+	secret     []byte
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewTokens(secret, issuer string, accessTTL, refreshTTL time.Duration) *Tokens {
+	return &Tokens{
+		secret:     []byte(secret),
+		issuer:     issuer,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// AccessTTL is how long an access token is valid.
+func (t *Tokens) AccessTTL() time.Duration {
+	return t.accessTTL
+}
+
+// Issue returns an access and a refresh token for p.
+func (t *Tokens) Issue(p Principal) (access, refresh string, err error) {
+	access, err = t.sign(p, TokenAccess, t.accessTTL)
+	if err != nil {
+		return "", "", err
+	}
+
+	refresh, err = t.sign(p, TokenRefresh, t.refreshTTL)
+	if err != nil {
+		return "", "", err
+	}
+
+	return access, refresh, nil
+}
+
+func (t *Tokens) sign(p Principal, kind string, ttl time.Duration) (string, error) {
+	now := time.Now()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    t.issuer,
+			Subject:   p.Subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Name:    p.Name,
+		Role:    p.Role,
+		Kind:    kind,
+		Version: p.TokenVersion,
+	})
+
+	return token.SignedString(t.secret)
+}
+
+// Parse verifies a token of the given kind and returns its user.
+func (t *Tokens) Parse(token, kind string) (Principal, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (interface{}, error) {
+		return t.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(t.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if c.Kind != kind {
+		return Principal{}, fmt.Errorf("%w: %s token, want %s", ErrInvalidToken, c.Kind, kind)
+	}
+
+	return Principal{
+		Subject: c.Subject,
+		Name:    c.Name,
+		Role:    c.Role,
+		Method:  MethodBearer,
+
+		TokenVersion: c.Version,
+	}, nil
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokens(t *testing.T) {
+	tokens := NewTokens("0123456789abcdef0123456789abcdef", "essy_travel", time.Minute, time.Hour)
+	user := Principal{Subject: "guid", Name: "alisher", Role: RoleEditor, TokenVersion: 3}
+
+	access, refresh, err := tokens.Issue(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		token, kind string
+	}{
+		{access, TokenAccess},
+		{refresh, TokenRefresh},
+	} {
+		got, err := tokens.Parse(tt.token, tt.kind)
+		if err != nil {
+			t.Fatalf("Parse of the %s token: %v", tt.kind, err)
+		}
+
+		want := user
+		want.Method = MethodBearer
+		if got != want {
+			t.Errorf("Parse of the %s token = %+v, want %+v", tt.kind, got, want)
+		}
+	}
+
+	_, err = tokens.Parse(refresh, TokenAccess)
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Parse of a refresh token as access: %v, want ErrInvalidToken", err)
+	}
+
+	other := NewTokens("another secret of at least 32 bytes", "essy_travel", time.Minute, time.Hour)
+	_, err = other.Parse(access, TokenAccess)
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Parse with another secret: %v, want ErrInvalidToken", err)
+	}
+
+	expired := NewTokens("0123456789abcdef0123456789abcdef", "essy_travel", -time.Minute, -time.Minute)
+	access, _, err = expired.Issue(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tokens.Parse(access, TokenAccess)
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Parse of an expired token: %v, want ErrInvalidToken", err)
+	}
+}
@@ -0,0 +1,17 @@
+package blob
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned by Get when no object is stored under the key.
+var ErrNotFound = errors.New("blob: not found")
+
+// Storage keeps binary objects, such as uploaded images, under
+// slash-separated keys like "airport/<guid>/<name>.jpg".
+type Storage interface {
+	Put(key string, r io.Reader) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
@@ -2,9 +2,10 @@ package helpers
 
 import "regexp"
 
-// IsValidPhone ...
+// IsValidPhone reports whether phone is written in E.164, a "+" and up to
+// 15 digits. See pkg/phone for parsing and validating numbers by country.
 func IsValidPhone(phone string) bool {
-	r := regexp.MustCompile(`^\+998[0-9]{2}[0-9]{7}$`)
+	r := regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
 	return r.MatchString(phone)
 }
 
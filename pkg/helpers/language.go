@@ -0,0 +1,57 @@
+package helpers
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseAcceptLanguage returns the primary language subtags listed in an
+// Accept-Language header ordered by quality, e.g. "ru-RU,en;q=0.8" gives
+// ["ru", "en"]. Duplicates, "*" and entries with q=0 are dropped.
+func ParseAcceptLanguage(header string) []string {
+	type language struct {
+		tag     string
+		quality float64
+	}
+
+	var languages = []language{}
+	for _, part := range strings.Split(header, ",") {
+		var (
+			fields  = strings.Split(strings.TrimSpace(part), ";")
+			tag     = strings.ToLower(strings.TrimSpace(fields[0]))
+			quality = 1.0
+		)
+
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				q, err := strconv.ParseFloat(param[2:], 64)
+				if err == nil {
+					quality = q
+				}
+			}
+		}
+
+		if i := strings.IndexAny(tag, "-_"); i > 0 {
+			tag = tag[:i]
+		}
+
+		if len(tag) == 0 || tag == "*" || quality <= 0 {
+			continue
+		}
+
+		languages = append(languages, language{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].quality > languages[j].quality
+	})
+
+	var result = []string{}
+	for _, l := range languages {
+		result = append(result, l.tag)
+	}
+
+	return RemoveDuplicatesStrings(result)
+}
@@ -0,0 +1,70 @@
+// Package mergepatch applies JSON merge patches, RFC 7396. A patch is the
+// part of a document to change: members it names replace those of the
+// document, objects merge member by member and null removes a member.
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidPatch is returned when the patch is not JSON.
+var ErrInvalidPatch = errors.New("mergepatch: invalid patch")
+
+// Apply returns doc with patch applied.
+func Apply(doc, patch []byte) ([]byte, error) {
+	var target interface{}
+	err := decode(doc, &target)
+	if err != nil {
+		return nil, fmt.Errorf("mergepatch: invalid document: %w", err)
+	}
+
+	var changes interface{}
+	err = decode(patch, &changes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	return json.Marshal(merge(target, changes))
+}
+
+// decode keeps numbers as they are written, so that large integers do not
+// lose precision through float64.
+func decode(data []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	err := d.Decode(v)
+	if err != nil {
+		return err
+	}
+
+	if d.More() {
+		return errors.New("data after the JSON value")
+	}
+	return nil
+}
+
+func merge(target, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	doc, ok := target.(map[string]interface{})
+	if !ok {
+		doc = map[string]interface{}{}
+	}
+
+	for name, value := range changes {
+		if value == nil {
+			delete(doc, name)
+			continue
+		}
+		doc[name] = merge(doc[name], value)
+	}
+
+	return doc
+}
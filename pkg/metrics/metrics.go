@@ -0,0 +1,169 @@
+// Package metrics holds the Prometheus collectors of the service. They are
+// registered on Registry, which /metrics serves.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"essy_travel/storage"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "essy_travel"
+
+// Registry holds every collector of the service together with the Go
+// runtime and process collectors.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests served, by route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time spent serving HTTP requests, by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	DBDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Time spent in storage calls, by repo method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"repo", "method"})
+
+	DBErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "Storage calls that failed, by repo method and kind of error.",
+	}, []string{"repo", "method", "error"})
+
+	UploadRows = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upload_rows_total",
+		Help:      "Rows stored through bulk uploads, by entity.",
+	}, []string{"entity"})
+
+	UploadFailures = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upload_failures_total",
+		Help:      "Bulk uploads that were rejected or failed, by entity.",
+	}, []string{"entity"})
+
+	RateLimited = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_requests_total",
+		Help:      "Requests refused for exceeding their rate limit, by class.",
+	}, []string{"class"})
+
+	CacheLookups = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "Storage lookups answered by the cache, by repo method and result, hit or miss.",
+	}, []string{"repo", "method", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// RegisterDBStats exports the connection pool statistics of strg.
+func RegisterDBStats(strg storage.StorageI) error {
+	return Registry.Register(dbStatsCollector{stats: strg.Stats})
+}
+
+// Observer records the duration and the errors of every storage call. It
+// is meant for storage/observe.
+type Observer struct{}
+
+func (Observer) Start(ctx context.Context, repo, method string) (context.Context, func(error)) {
+	start := time.Now()
+
+	return ctx, func(err error) {
+		DBDuration.WithLabelValues(repo, method).Observe(time.Since(start).Seconds())
+		if err != nil {
+			DBErrors.WithLabelValues(repo, method, errorKind(err)).Inc()
+		}
+	}
+}
+
+// CacheRecorder counts the hits and misses of storage/cache.
+type CacheRecorder struct{}
+
+func (CacheRecorder) Lookup(repo, method string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	CacheLookups.WithLabelValues(repo, method, result).Inc()
+}
+
+// errorKind keeps the error label to a handful of values.
+func errorKind(err error) string {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, storage.ErrConstraint):
+		return "constraint"
+	case errors.Is(err, storage.ErrTimeout):
+		return "timeout"
+	}
+	return "other"
+}
+
+var (
+	dbOpenDesc      = dbDesc("open_connections", "Established connections, in use and idle.")
+	dbInUseDesc     = dbDesc("in_use_connections", "Connections currently in use.")
+	dbIdleDesc      = dbDesc("idle_connections", "Idle connections.")
+	dbMaxOpenDesc   = dbDesc("max_open_connections", "Maximum number of open connections.")
+	dbWaitCountDesc = dbDesc("wait_count_total", "Connections waited for.")
+	dbWaitDesc      = dbDesc("wait_duration_seconds_total", "Time spent waiting for a connection.")
+	dbClosedDesc    = dbDesc("closed_total", "Connections closed, by reason.", "reason")
+)
+
+func dbDesc(name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, labels, nil)
+}
+
+// dbStatsCollector reads the pool statistics on every scrape.
+type dbStatsCollector struct {
+	stats func() sql.DBStats
+}
+
+func (c dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dbOpenDesc
+	ch <- dbInUseDesc
+	ch <- dbIdleDesc
+	ch <- dbMaxOpenDesc
+	ch <- dbWaitCountDesc
+	ch <- dbWaitDesc
+	ch <- dbClosedDesc
+}
+
+func (c dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats()
+
+	ch <- prometheus.MustNewConstMetric(dbOpenDesc, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(dbInUseDesc, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(dbIdleDesc, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(dbMaxOpenDesc, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(dbWaitCountDesc, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(dbWaitDesc, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(dbClosedDesc, prometheus.CounterValue, float64(stats.MaxIdleClosed), "max_idle")
+	ch <- prometheus.MustNewConstMetric(dbClosedDesc, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed), "max_idle_time")
+	ch <- prometheus.MustNewConstMetric(dbClosedDesc, prometheus.CounterValue, float64(stats.MaxLifetimeClosed), "max_lifetime")
+}
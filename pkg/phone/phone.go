@@ -0,0 +1,91 @@
+// Package phone parses international phone numbers, normalizes them to
+// E.164 and validates them against the numbering plan of a country.
+package phone
+
+import (
+	"errors"
+	"essy_travel/pkg/helpers"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nyaruka/phonenumbers"
+)
+
+// ErrInvalid is returned for a number that cannot be read as an
+// international number.
+var ErrInvalid = errors.New("phone: invalid number")
+
+// Number is a parsed phone number.
+type Number struct {
+	// E164 is the normalized form, e.g. +998901234567.
+	E164 string
+	// CallingCode is the country calling code without the "+", e.g. 998.
+	CallingCode string
+
+	number *phonenumbers.PhoneNumber
+}
+
+// Parse reads an international number written with a leading "+" or
+// "00", ignoring spaces, dots, dashes and parentheses, such as
+// "+998 (90) 123-45-67" or "00998901234567".
+func Parse(raw string) (Number, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "00") {
+		raw = "+" + raw[2:]
+	}
+
+	if !strings.HasPrefix(raw, "+") {
+		return Number{}, ErrInvalid
+	}
+
+	number, err := phonenumbers.Parse(raw, "")
+	if err != nil {
+		return Number{}, ErrInvalid
+	}
+
+	e164 := phonenumbers.Format(number, phonenumbers.E164)
+	if !helpers.IsValidPhone(e164) {
+		return Number{}, ErrInvalid
+	}
+
+	return Number{
+		E164:        e164,
+		CallingCode: strconv.Itoa(int(number.GetCountryCode())),
+		number:      number,
+	}, nil
+}
+
+// ValidFor reports whether n is a valid number of the country with the
+// ISO 3166-1 alpha-2 code region. For a region unknown to the numbering
+// plans only the calling code is checked.
+func (n Number) ValidFor(region, callingCode string) bool {
+	if n.number == nil || n.CallingCode != callingCode {
+		return false
+	}
+
+	region = strings.ToUpper(region)
+	if phonenumbers.GetCountryCodeForRegion(region) == 0 {
+		return phonenumbers.IsValidNumber(n.number)
+	}
+
+	return phonenumbers.IsValidNumberForRegion(n.number, region)
+}
+
+var callingCodePattern = regexp.MustCompile(`^[1-9][0-9]{0,2}$`)
+
+// IsValidCallingCode reports whether code is written like a calling code,
+// one to three digits without the "+".
+func IsValidCallingCode(code string) bool {
+	return callingCodePattern.MatchString(code)
+}
+
+// CallingCode returns the calling code of the country with the ISO 3166-1
+// alpha-2 code region, or "" when region is not one.
+func CallingCode(region string) string {
+	code := phonenumbers.GetCountryCodeForRegion(strings.ToUpper(region))
+	if code == 0 {
+		return ""
+	}
+	return strconv.Itoa(code)
+}
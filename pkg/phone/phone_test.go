@@ -0,0 +1,120 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		raw         string
+		e164        string
+		callingCode string
+	}{
+		{"+998901234567", "+998901234567", "998"},
+		{"+998 (90) 123-45-67", "+998901234567", "998"},
+		{" 00998 90 123 45 67 ", "+998901234567", "998"},
+		{"+1 201.555.0123", "+12015550123", "1"},
+		{"+7 701 123 4567", "+77011234567", "7"},
+		{"+44 7781 123456", "+447781123456", "44"},
+	} {
+		number, err := Parse(tt.raw)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.raw, err)
+			continue
+		}
+		if number.E164 != tt.e164 || number.CallingCode != tt.callingCode {
+			t.Errorf("Parse(%q) = %s, +%s; want %s, +%s", tt.raw, number.E164, number.CallingCode, tt.e164, tt.callingCode)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, raw := range []string{"", "901234567", "998901234567", "+", "+abc", "+0 123 456", "0998901234567", "+99890123456789012345"} {
+		_, err := Parse(raw)
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q): %v, want ErrInvalid", raw, err)
+		}
+	}
+}
+
+func TestValidFor(t *testing.T) {
+	for _, tt := range []struct {
+		raw         string
+		region      string
+		callingCode string
+		want        bool
+	}{
+		{"+998901234567", "UZ", "998", true},
+		{"+998901234567", "uz", "998", true},
+		{"+998901234567", "UZ", "7", false},
+		{"+99812345", "UZ", "998", false},
+		// The United States and Canada share +1, Russia and Kazakhstan
+		// share +7; a number is valid for only one of them.
+		{"+12015550123", "US", "1", true},
+		{"+12015550123", "CA", "1", false},
+		{"+15062345678", "US", "1", false},
+		{"+15062345678", "CA", "1", true},
+		{"+77011234567", "RU", "7", false},
+		{"+77011234567", "KZ", "7", true},
+		{"+79123456789", "RU", "7", true},
+		// A region the numbering plans do not know checks only the
+		// calling code and that the number is valid somewhere.
+		{"+998901234567", "XX", "998", true},
+		{"+998901234567", "XX", "1", false},
+		{"+99812345", "XX", "998", false},
+	} {
+		number, err := Parse(tt.raw)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.raw, err)
+		}
+		if got := number.ValidFor(tt.region, tt.callingCode); got != tt.want {
+			t.Errorf("%s.ValidFor(%q, %q) = %v, want %v", tt.raw, tt.region, tt.callingCode, got, tt.want)
+		}
+	}
+
+	if (Number{}).ValidFor("UZ", "") {
+		t.Error("the zero Number is valid")
+	}
+}
+
+func TestCallingCode(t *testing.T) {
+	for _, tt := range []struct {
+		region string
+		want   string
+	}{
+		{"UZ", "998"},
+		{"uz", "998"},
+		{"US", "1"},
+		{"CA", "1"},
+		{"KZ", "7"},
+		{"XX", ""},
+		{"UZB", ""},
+		{"", ""},
+	} {
+		if got := CallingCode(tt.region); got != tt.want {
+			t.Errorf("CallingCode(%q) = %q, want %q", tt.region, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidCallingCode(t *testing.T) {
+	for _, tt := range []struct {
+		code string
+		want bool
+	}{
+		{"1", true},
+		{"44", true},
+		{"998", true},
+		{"", false},
+		{"0", false},
+		{"044", false},
+		{"1234", false},
+		{"+998", false},
+		{"99a", false},
+	} {
+		if got := IsValidCallingCode(tt.code); got != tt.want {
+			t.Errorf("IsValidCallingCode(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
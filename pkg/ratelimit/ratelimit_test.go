@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// clock is a fake time source moved on by hand.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newMemory() (*Memory, *clock) {
+	c := &clock{now: time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)}
+	m := NewMemory()
+	m.now = c.Now
+	m.lastSweep = c.now
+	return m, c
+}
+
+func TestBurst(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newMemory()
+	limit := Limit{Rate: 1, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		result, err := m.Allow(ctx, "a", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed || result.Limit != 3 || result.Remaining != 2-i {
+			t.Errorf("request %d: %+v, want allowed with %d remaining", i, result, 2-i)
+		}
+	}
+
+	result, err := m.Allow(ctx, "a", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed || result.Remaining != 0 || result.RetryAfter != time.Second || result.Reset != 3*time.Second {
+		t.Errorf("request over the burst: %+v, want refused, retry after 1s, reset in 3s", result)
+	}
+}
+
+func TestRefill(t *testing.T) {
+	ctx := context.Background()
+	m, c := newMemory()
+	limit := PerMinute(60, 2)
+
+	m.Allow(ctx, "a", limit)
+	m.Allow(ctx, "a", limit)
+
+	c.Add(500 * time.Millisecond)
+	result, _ := m.Allow(ctx, "a", limit)
+	if result.Allowed || result.RetryAfter != 500*time.Millisecond {
+		t.Errorf("after half a token: %+v, want refused, retry after 500ms", result)
+	}
+
+	c.Add(500 * time.Millisecond)
+	result, _ = m.Allow(ctx, "a", limit)
+	if !result.Allowed || result.Remaining != 0 {
+		t.Errorf("after a token: %+v, want allowed with 0 remaining", result)
+	}
+
+	// The bucket never holds more than the burst.
+	c.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		if result, _ = m.Allow(ctx, "a", limit); !result.Allowed {
+			t.Errorf("request %d after an hour refused", i)
+		}
+	}
+	if result, _ = m.Allow(ctx, "a", limit); result.Allowed {
+		t.Error("request over the burst after an hour allowed")
+	}
+}
+
+func TestKeys(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newMemory()
+	limit := Limit{Rate: 1, Burst: 1}
+
+	if result, _ := m.Allow(ctx, "a", limit); !result.Allowed {
+		t.Fatal("first request of a refused")
+	}
+	if result, _ := m.Allow(ctx, "a", limit); result.Allowed {
+		t.Error("second request of a allowed")
+	}
+	if result, _ := m.Allow(ctx, "b", limit); !result.Allowed {
+		t.Error("first request of b refused after a ran out")
+	}
+
+	// A new limit for a key starts a new bucket.
+	if result, _ := m.Allow(ctx, "a", Limit{Rate: 1, Burst: 5}); !result.Allowed || result.Remaining != 4 {
+		t.Errorf("request of a under a new limit: %+v, want allowed with 4 remaining", result)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	ctx := context.Background()
+	m, c := newMemory()
+	limit := Limit{Rate: 1, Burst: 2}
+
+	m.Allow(ctx, "a", limit)
+	c.Add(sweepEvery)
+	m.Allow(ctx, "b", limit)
+
+	if _, ok := m.buckets["a"]; ok {
+		t.Error("the full bucket of a was kept")
+	}
+	if _, ok := m.buckets["b"]; !ok {
+		t.Error("the bucket of b, just used, was dropped")
+	}
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+)
+
+type AirportRepo struct {
+	repo  storage.AirportRepoI
+	store *Store
+}
+
+func (a *AirportRepo) Create(ctx context.Context, req models.CreateAirport) (*models.Airport, error) {
+	defer a.store.invalidate(ctx)
+	return a.repo.Create(ctx, req)
+}
+
+func (a *AirportRepo) Update(ctx context.Context, req models.UpdateAirport) (*models.Airport, error) {
+	defer a.store.invalidate(ctx)
+	return a.repo.Update(ctx, req)
+}
+
+func (a *AirportRepo) GetById(ctx context.Context, req models.AirportPrimaryKey) (*models.Airport, error) {
+	return lookup(ctx, a.store, "AirportRepo", "GetById", req, a.repo.GetById)
+}
+
+func (a *AirportRepo) GetList(ctx context.Context, req models.GetListAirportRequest) (*models.GetListAirportResponse, error) {
+	return lookup(ctx, a.store, "AirportRepo", "GetList", req, a.repo.GetList)
+}
+
+func (a *AirportRepo) UpdateImage(ctx context.Context, req models.UpdateAirportImage) (*models.Airport, error) {
+	defer a.store.invalidate(ctx)
+	return a.repo.UpdateImage(ctx, req)
+}
+
+func (a *AirportRepo) Delete(ctx context.Context, req models.AirportPrimaryKey) (string, error) {
+	defer a.store.invalidate(ctx)
+	return a.repo.Delete(ctx, req)
+}
+
+func (a *AirportRepo) Upload(ctx context.Context, req []models.CreateAirport) error {
+	defer a.store.invalidate(ctx)
+	return a.repo.Upload(ctx, req)
+}
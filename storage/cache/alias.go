@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+)
+
+type AliasRepo struct {
+	repo  storage.AliasRepoI
+	store *Store
+}
+
+func (a *AliasRepo) Create(ctx context.Context, req models.CreateAlias) (*models.Alias, error) {
+	defer a.store.invalidate(ctx)
+	return a.repo.Create(ctx, req)
+}
+
+func (a *AliasRepo) GetById(ctx context.Context, req models.AliasPrimaryKey) (*models.Alias, error) {
+	return a.repo.GetById(ctx, req)
+}
+
+func (a *AliasRepo) GetList(ctx context.Context, req models.GetListAliasRequest) (*models.GetListAliasResponse, error) {
+	return a.repo.GetList(ctx, req)
+}
+
+func (a *AliasRepo) Delete(ctx context.Context, req models.AliasPrimaryKey) (string, error) {
+	defer a.store.invalidate(ctx)
+	return a.repo.Delete(ctx, req)
+}
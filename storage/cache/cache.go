@@ -0,0 +1,180 @@
+// Package cache wraps a storage.StorageI so that lookups of countries,
+// cities and airports, which change rarely, are answered from a cache.
+// Any write to them, or to the aliases their searches match, empties the
+// cache. API keys, users and the other repos always reach the storage.
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"essy_travel/storage"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Backend keeps cached values by key until they expire. LRU keeps them in
+// the process; a shared store, such as Redis, lets several instances of the
+// service share one cache.
+type Backend interface {
+	// Get returns the value stored under key and whether there was one.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Clear drops every value.
+	Clear(ctx context.Context) error
+}
+
+// Recorder is told whether every lookup was a hit or a miss.
+type Recorder interface {
+	Lookup(repo, method string, hit bool)
+}
+
+type Store struct {
+	strg     storage.StorageI
+	backend  Backend
+	ttl      time.Duration
+	recorder Recorder
+
+	// generation is part of every key and moves on with every write, so
+	// that a value loaded before a write is never read after it, even
+	// when it is stored after the cache was cleared.
+	generation atomic.Uint64
+
+	country *CountryRepo
+	city    *CityRepo
+	airport *AirportRepo
+	alias   *AliasRepo
+}
+
+// New returns strg with lookups kept in backend for ttl. recorder may be
+// nil.
+func New(strg storage.StorageI, backend Backend, ttl time.Duration, recorder Recorder) storage.StorageI {
+	s := &Store{
+		strg:     strg,
+		backend:  backend,
+		ttl:      ttl,
+		recorder: recorder,
+	}
+	s.country = &CountryRepo{repo: strg.Country(), store: s}
+	s.city = &CityRepo{repo: strg.City(), store: s}
+	s.airport = &AirportRepo{repo: strg.Airport(), store: s}
+	s.alias = &AliasRepo{repo: strg.Alias(), store: s}
+
+	return s
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return s.strg.Ping(ctx)
+}
+
+func (s *Store) Schema(ctx context.Context) (storage.Schema, error) {
+	return s.strg.Schema(ctx)
+}
+
+func (s *Store) Stats() sql.DBStats {
+	return s.strg.Stats()
+}
+
+func (s *Store) Close() error {
+	return s.strg.Close()
+}
+
+func (s *Store) Country() storage.CountryRepoI {
+	return s.country
+}
+
+func (s *Store) City() storage.CityRepoI {
+	return s.city
+}
+
+func (s *Store) Airport() storage.AirportRepoI {
+	return s.airport
+}
+
+func (s *Store) Alias() storage.AliasRepoI {
+	return s.alias
+}
+
+func (s *Store) Route() storage.RouteRepoI {
+	return s.strg.Route()
+}
+
+func (s *Store) Airline() storage.AirlineRepoI {
+	return s.strg.Airline()
+}
+
+func (s *Store) Translation() storage.TranslationRepoI {
+	return s.strg.Translation()
+}
+
+func (s *Store) ApiKey() storage.ApiKeyRepoI {
+	return s.strg.ApiKey()
+}
+
+func (s *Store) User() storage.UserRepoI {
+	return s.strg.User()
+}
+
+// invalidate forgets every cached value once a write is done. Writes are
+// rare and a search may match aliases of another entity, so the whole cache
+// goes rather than the entries of one repo.
+func (s *Store) invalidate(ctx context.Context) {
+	s.generation.Add(1)
+
+	err := s.backend.Clear(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "clear cache", "error", err)
+	}
+}
+
+// lookup answers a read of repo.method with req from the cache, or calls
+// load and caches what it returns. Errors, not found included, are not
+// cached. A failing backend only costs the cache.
+func lookup[Req, Resp any](ctx context.Context, s *Store, repo, method string, req Req, load func(context.Context, Req) (*Resp, error)) (*Resp, error) {
+	generation := s.generation.Load()
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return load(ctx, req)
+	}
+	key := strconv.FormatUint(generation, 10) + ":" + repo + "." + method + ":" + string(params)
+
+	value, ok, err := s.backend.Get(ctx, key)
+	if err != nil {
+		slog.WarnContext(ctx, "read cache", "key", key, "error", err)
+	}
+	if ok {
+		// Every hit is decoded afresh, so that callers may change what
+		// they get back.
+		var resp Resp
+		if err = json.Unmarshal(value, &resp); err == nil {
+			s.record(repo, method, true)
+			return &resp, nil
+		}
+	}
+	s.record(repo, method, false)
+
+	resp, err := load(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	value, err = json.Marshal(resp)
+	if err == nil && s.generation.Load() == generation {
+		err = s.backend.Set(ctx, key, value, s.ttl)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "write cache", "key", key, "error", err)
+	}
+
+	return resp, nil
+}
+
+func (s *Store) record(repo, method string, hit bool) {
+	if s.recorder != nil {
+		s.recorder.Lookup(repo, method, hit)
+	}
+}
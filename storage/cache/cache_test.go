@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+	"essy_travel/storage/memory"
+	"essy_travel/storage/storagetest"
+	"testing"
+	"time"
+)
+
+// lookups counts what a Store tells its Recorder.
+type lookups struct {
+	hits, misses int
+}
+
+func (l *lookups) Lookup(repo, method string, hit bool) {
+	if hit {
+		l.hits++
+	} else {
+		l.misses++
+	}
+}
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.StorageI {
+		return New(memory.NewStore(), NewLRU(100), time.Minute, nil)
+	})
+}
+
+func TestLookup(t *testing.T) {
+	ctx := context.Background()
+	recorder := &lookups{}
+	strg := New(memory.NewStore(), NewLRU(100), time.Minute, recorder)
+
+	country, err := strg.Country().Create(ctx, models.CreateCountry{Title: "Uzbekistan", Code: "UZ", Continent: "Asia"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := models.CountryPrimaryKey{Guid: country.Guid}
+
+	first, err := strg.Country().GetById(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Title = "Changed by the caller"
+
+	second, err := strg.Country().GetById(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Title != "Uzbekistan" {
+		t.Errorf("hit returned title %q, want the stored one", second.Title)
+	}
+	if recorder.hits != 1 || recorder.misses != 1 {
+		t.Errorf("got %d hits and %d misses, want 1 and 1", recorder.hits, recorder.misses)
+	}
+
+	_, err = strg.Country().Update(ctx, models.UpdateCountry{Guid: country.Guid, Title: "O'zbekiston", Code: "UZ", Continent: "Asia"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	third, err := strg.Country().GetById(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Title != "O'zbekiston" {
+		t.Errorf("read after a write returned title %q, want the new one", third.Title)
+	}
+	if recorder.hits != 1 || recorder.misses != 2 {
+		t.Errorf("got %d hits and %d misses after a write, want 1 and 2", recorder.hits, recorder.misses)
+	}
+}
+
+func TestLookupDuringWrite(t *testing.T) {
+	ctx := context.Background()
+	s := New(memory.NewStore(), NewLRU(100), time.Minute, nil).(*Store)
+
+	// A write lands while the value is loaded; what was loaded is stale
+	// and must not be cached.
+	loads := 0
+	load := func(ctx context.Context, req models.CountryPrimaryKey) (*models.Country, error) {
+		loads++
+		if loads == 1 {
+			s.invalidate(ctx)
+		}
+		return &models.Country{Guid: req.Guid}, nil
+	}
+
+	req := models.CountryPrimaryKey{Guid: "a"}
+	for i := 0; i < 2; i++ {
+		_, err := lookup(ctx, s, "CountryRepo", "GetById", req, load)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if loads != 2 {
+		t.Errorf("loaded %d times, want 2", loads)
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	lru := NewLRU(10)
+	lru.now = func() time.Time { return now }
+
+	lru.Set(ctx, "a", []byte("1"), time.Second)
+
+	now = now.Add(time.Second - time.Millisecond)
+	if value, ok, _ := lru.Get(ctx, "a"); !ok || string(value) != "1" {
+		t.Errorf("Get before expiry = %q, %v; want 1, true", value, ok)
+	}
+
+	now = now.Add(time.Millisecond)
+	if _, ok, _ := lru.Get(ctx, "a"); ok {
+		t.Error("Get at expiry found the value")
+	}
+	if lru.Len() != 0 {
+		t.Errorf("Len = %d after expiry, want 0", lru.Len())
+	}
+}
+
+func TestLRUEviction(t *testing.T) {
+	ctx := context.Background()
+	lru := NewLRU(2)
+
+	lru.Set(ctx, "a", []byte("1"), time.Minute)
+	lru.Set(ctx, "b", []byte("2"), time.Minute)
+	lru.Get(ctx, "a")
+	lru.Set(ctx, "c", []byte("3"), time.Minute)
+
+	if lru.Len() != 2 {
+		t.Errorf("Len = %d, want 2", lru.Len())
+	}
+	if _, ok, _ := lru.Get(ctx, "b"); ok {
+		t.Error("b, used least recently, was kept")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := lru.Get(ctx, key); !ok {
+			t.Errorf("%s was evicted", key)
+		}
+	}
+
+	lru.Clear(ctx)
+	if lru.Len() != 0 {
+		t.Errorf("Len = %d after Clear, want 0", lru.Len())
+	}
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+)
+
+type CityRepo struct {
+	repo  storage.CityRepoI
+	store *Store
+}
+
+func (c *CityRepo) Create(ctx context.Context, req models.CreateCity) (*models.City, error) {
+	defer c.store.invalidate(ctx)
+	return c.repo.Create(ctx, req)
+}
+
+func (c *CityRepo) Update(ctx context.Context, req models.UpdateCity) (*models.City, error) {
+	defer c.store.invalidate(ctx)
+	return c.repo.Update(ctx, req)
+}
+
+func (c *CityRepo) GetById(ctx context.Context, req models.CityPrimaryKey) (*models.City, error) {
+	return lookup(ctx, c.store, "CityRepo", "GetById", req, c.repo.GetById)
+}
+
+func (c *CityRepo) GetList(ctx context.Context, req models.GetListCityRequest) (*models.GetListCityResponse, error) {
+	return lookup(ctx, c.store, "CityRepo", "GetList", req, c.repo.GetList)
+}
+
+func (c *CityRepo) Delete(ctx context.Context, req models.CityPrimaryKey) (string, error) {
+	defer c.store.invalidate(ctx)
+	return c.repo.Delete(ctx, req)
+}
+
+func (c *CityRepo) Upload(ctx context.Context, req []models.CreateCity) error {
+	defer c.store.invalidate(ctx)
+	return c.repo.Upload(ctx, req)
+}
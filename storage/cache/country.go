@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+)
+
+type CountryRepo struct {
+	repo  storage.CountryRepoI
+	store *Store
+}
+
+func (c *CountryRepo) Create(ctx context.Context, req models.CreateCountry) (*models.Country, error) {
+	defer c.store.invalidate(ctx)
+	return c.repo.Create(ctx, req)
+}
+
+func (c *CountryRepo) Update(ctx context.Context, req models.UpdateCountry) (*models.Country, error) {
+	defer c.store.invalidate(ctx)
+	return c.repo.Update(ctx, req)
+}
+
+func (c *CountryRepo) GetById(ctx context.Context, req models.CountryPrimaryKey) (*models.Country, error) {
+	return lookup(ctx, c.store, "CountryRepo", "GetById", req, c.repo.GetById)
+}
+
+func (c *CountryRepo) GetList(ctx context.Context, req models.GetListCountryRequest) (*models.GetListCountryResponse, error) {
+	return lookup(ctx, c.store, "CountryRepo", "GetList", req, c.repo.GetList)
+}
+
+func (c *CountryRepo) Delete(ctx context.Context, req models.CountryPrimaryKey) (string, error) {
+	defer c.store.invalidate(ctx)
+	return c.repo.Delete(ctx, req)
+}
+
+func (c *CountryRepo) Upload(ctx context.Context, req []models.CreateCountry) error {
+	defer c.store.invalidate(ctx)
+	return c.repo.Upload(ctx, req)
+}
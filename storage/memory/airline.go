@@ -0,0 +1,256 @@
+package memory
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+type AirlineRepo struct {
+	db *database
+}
+
+func NewAirlineRepo(db *database) *AirlineRepo {
+	return &AirlineRepo{
+		db: db,
+	}
+}
+
+func (a *AirlineRepo) Create(ctx context.Context, req models.CreateAirline) (*models.Airline, error) {
+	if err := contextError(ctx); err != nil {
+		return &models.Airline{}, err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	airline, err := a.insert(req)
+	if err != nil {
+		return &models.Airline{}, err
+	}
+
+	return &airline, nil
+}
+
+func (a *AirlineRepo) insert(req models.CreateAirline) (models.Airline, error) {
+	if len(req.CountryId) > 0 && !a.db.countries.has(req.CountryId) {
+		return models.Airline{}, constraintError("country %s does not exist", req.CountryId)
+	}
+
+	created := now()
+	airline := models.Airline{
+		Guid:      uuid.New().String(),
+		IataCode:  req.IataCode,
+		IcaoCode:  req.IcaoCode,
+		Title:     req.Title,
+		CountryId: req.CountryId,
+		Active:    req.Active,
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+	a.db.airlines.put(airline.Guid, airline)
+
+	return airline, nil
+}
+
+func (a *AirlineRepo) GetById(ctx context.Context, req models.AirlinePrimaryKey) (*models.Airline, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	a.db.mu.RLock()
+	defer a.db.mu.RUnlock()
+
+	airline, ok := a.db.airlines.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return &airline, nil
+}
+
+func (a *AirlineRepo) GetList(ctx context.Context, req models.GetListAirlineRequest) (*models.GetListAirlineResponse, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	a.db.mu.RLock()
+	defer a.db.mu.RUnlock()
+
+	var resp = models.GetListAirlineResponse{}
+
+	airlines := a.db.airlines.all(func(airline models.Airline) bool {
+		if len(req.Search) > 0 && !contains(airline.Title, req.Search) &&
+			!contains(airline.IataCode, req.Search) && !contains(airline.IcaoCode, req.Search) {
+			return false
+		}
+
+		if len(req.CountryId) > 0 && airline.CountryId != req.CountryId {
+			return false
+		}
+
+		return !req.OnlyActive || airline.Active
+	})
+	sort.SliceStable(airlines, func(i, j int) bool {
+		return airlines[i].Title < airlines[j].Title
+	})
+	resp.Airlines, resp.Count = page(airlines, req.Offset, req.Limit)
+
+	return &resp, nil
+}
+
+func (a *AirlineRepo) Update(ctx context.Context, req models.UpdateAirline) (*models.Airline, error) {
+	if err := contextError(ctx); err != nil {
+		return &models.Airline{}, err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	airline, ok := a.db.airlines.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	if len(req.CountryId) > 0 && !a.db.countries.has(req.CountryId) {
+		return &models.Airline{}, constraintError("country %s does not exist", req.CountryId)
+	}
+
+	airline.IataCode = req.IataCode
+	airline.IcaoCode = req.IcaoCode
+	airline.Title = req.Title
+	airline.CountryId = req.CountryId
+	airline.Active = req.Active
+	airline.UpdatedAt = now()
+	a.db.airlines.put(airline.Guid, airline)
+
+	return &airline, nil
+}
+
+// Delete removes the airline together with its hub and base links.
+func (a *AirlineRepo) Delete(ctx context.Context, req models.AirlinePrimaryKey) (string, error) {
+	if err := contextError(ctx); err != nil {
+		return "Does not delete", err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	if !a.db.airlines.delete(req.Guid) {
+		return "Does not delete", storage.ErrNotFound
+	}
+
+	for _, link := range a.db.airlineAirport.all(nil) {
+		if link.AirlineId == req.Guid {
+			a.db.airlineAirport.delete(airlineAirportKey(link.AirlineId, link.AirportId))
+		}
+	}
+
+	return "Deleted", nil
+}
+
+func (a *AirlineRepo) Upload(ctx context.Context, req []models.CreateAirline) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	for _, v := range req {
+		_, err := a.insert(v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *AirlineRepo) AddAirport(ctx context.Context, req models.CreateAirlineAirport) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	if !a.db.airlines.has(req.AirlineId) {
+		return constraintError("airline %s does not exist", req.AirlineId)
+	}
+
+	if !a.db.airports.has(req.AirportId) {
+		return constraintError("airport %s does not exist", req.AirportId)
+	}
+
+	if req.Type != "hub" && req.Type != "base" {
+		return constraintError("airline airport type %q is not hub or base", req.Type)
+	}
+
+	key := airlineAirportKey(req.AirlineId, req.AirportId)
+
+	link, ok := a.db.airlineAirport.get(key)
+	if !ok {
+		link = models.AirlineAirport{
+			AirlineId: req.AirlineId,
+			AirportId: req.AirportId,
+			CreatedAt: now(),
+		}
+	}
+	link.Type = req.Type
+	a.db.airlineAirport.put(key, link)
+
+	return nil
+}
+
+func (a *AirlineRepo) RemoveAirport(ctx context.Context, req models.AirlineAirportPrimaryKey) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	a.db.airlineAirport.delete(airlineAirportKey(req.AirlineId, req.AirportId))
+
+	return nil
+}
+
+func (a *AirlineRepo) GetAirports(ctx context.Context, req models.AirlinePrimaryKey) (*models.GetListAirlineAirportResponse, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	a.db.mu.RLock()
+	defer a.db.mu.RUnlock()
+
+	var resp = models.GetListAirlineAirportResponse{}
+
+	for _, link := range a.db.airlineAirport.all(nil) {
+		if link.AirlineId != req.Guid {
+			continue
+		}
+
+		airport, _ := a.db.airports.get(link.AirportId)
+		link.Airport = airport.Title
+		resp.Airports = append(resp.Airports, link)
+	}
+
+	sort.SliceStable(resp.Airports, func(i, j int) bool {
+		if resp.Airports[i].Type != resp.Airports[j].Type {
+			return resp.Airports[i].Type < resp.Airports[j].Type
+		}
+		return resp.Airports[i].Airport < resp.Airports[j].Airport
+	})
+	resp.Count = len(resp.Airports)
+
+	return &resp, nil
+}
+
+// airlineAirportKey is the primary key of an airline to airport link.
+func airlineAirportKey(airlineId, airportId string) string {
+	return airlineId + "/" + airportId
+}
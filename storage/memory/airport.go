@@ -0,0 +1,232 @@
+package memory
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+
+	"github.com/google/uuid"
+)
+
+type AirportRepo struct {
+	db *database
+}
+
+func NewAirportRepo(db *database) *AirportRepo {
+	return &AirportRepo{
+		db: db,
+	}
+}
+
+func (a *AirportRepo) Create(ctx context.Context, req models.CreateAirport) (*models.Airport, error) {
+	if err := contextError(ctx); err != nil {
+		return &models.Airport{}, err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	airport, err := a.insert(req)
+	if err != nil {
+		return &models.Airport{}, err
+	}
+
+	return &airport, nil
+}
+
+func (a *AirportRepo) insert(req models.CreateAirport) (models.Airport, error) {
+	err := a.checkReferences(req.CountryId, req.CityId)
+	if err != nil {
+		return models.Airport{}, err
+	}
+
+	created := now()
+	airport := models.Airport{
+		Guid:         uuid.New().String(),
+		Title:        req.Title,
+		CountryId:    req.CountryId,
+		CityId:       req.CityId,
+		Latitude:     req.Latitude,
+		Longitude:    req.Longitude,
+		Radius:       req.Radius,
+		Adress:       req.Adress,
+		TimezoneId:   req.TimezoneId,
+		Country:      req.Country,
+		City:         req.City,
+		Code:         req.Code,
+		ProductCount: req.ProductCount,
+		Gmt:          req.Gmt,
+		CreatedAt:    created,
+		UpdatedAt:    created,
+		Version:      1,
+	}
+	a.db.airports.put(airport.Guid, airport)
+
+	return airport, nil
+}
+
+// checkReferences mirrors the foreign keys of the airport table. Empty ids
+// are stored as NULL and always accepted.
+func (a *AirportRepo) checkReferences(countryId, cityId string) error {
+	if len(countryId) > 0 && !a.db.countries.has(countryId) {
+		return constraintError("country %s does not exist", countryId)
+	}
+
+	if len(cityId) > 0 && !a.db.cities.has(cityId) {
+		return constraintError("city %s does not exist", cityId)
+	}
+
+	return nil
+}
+
+func (a *AirportRepo) GetById(ctx context.Context, req models.AirportPrimaryKey) (*models.Airport, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	a.db.mu.RLock()
+	defer a.db.mu.RUnlock()
+
+	airport, ok := a.db.airports.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return &airport, nil
+}
+
+func (a *AirportRepo) GetList(ctx context.Context, req models.GetListAirportRequest) (*models.GetListAirportResponse, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	a.db.mu.RLock()
+	defer a.db.mu.RUnlock()
+
+	var resp = models.GetListAirportResponse{}
+
+	airports := a.db.airports.all(func(airport models.Airport) bool {
+		return len(req.Search) == 0 ||
+			contains(airport.Title, req.Search) ||
+			contains(airport.Code, req.Search) ||
+			a.db.hasAlias("airport", airport.Guid, req.Search)
+	})
+	resp.Airports, resp.Count = page(airports, req.Offset, req.Limit)
+
+	return &resp, nil
+}
+
+func (a *AirportRepo) Update(ctx context.Context, req models.UpdateAirport) (*models.Airport, error) {
+	if err := contextError(ctx); err != nil {
+		return &models.Airport{}, err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	airport, ok := a.db.airports.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	if req.Version != 0 && req.Version != airport.Version {
+		return &airport, storage.ErrConflict
+	}
+
+	err := a.checkReferences(req.CountryId, req.CityId)
+	if err != nil {
+		return &models.Airport{}, err
+	}
+
+	airport.Title = req.Title
+	airport.CountryId = req.CountryId
+	airport.CityId = req.CityId
+	airport.Latitude = req.Latitude
+	airport.Longitude = req.Longitude
+	airport.Radius = req.Radius
+	airport.Adress = req.Adress
+	airport.TimezoneId = req.TimezoneId
+	airport.Country = req.Country
+	airport.City = req.City
+	airport.Code = req.Code
+	airport.ProductCount = req.ProductCount
+	airport.Gmt = req.Gmt
+	airport.UpdatedAt = now()
+	airport.Version++
+	a.db.airports.put(airport.Guid, airport)
+
+	return &airport, nil
+}
+
+func (a *AirportRepo) UpdateImage(ctx context.Context, req models.UpdateAirportImage) (*models.Airport, error) {
+	if err := contextError(ctx); err != nil {
+		return &models.Airport{}, err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	airport, ok := a.db.airports.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	if airport.Image != req.OldImage {
+		return &airport, storage.ErrConflict
+	}
+
+	airport.Image = req.Image
+	airport.UpdatedAt = now()
+	airport.Version++
+	a.db.airports.put(airport.Guid, airport)
+
+	return &airport, nil
+}
+
+// Delete removes the airport together with its routes and airline links,
+// as the ON DELETE CASCADE references do in Postgres.
+func (a *AirportRepo) Delete(ctx context.Context, req models.AirportPrimaryKey) (string, error) {
+	if err := contextError(ctx); err != nil {
+		return "Does not delete", err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	if !a.db.airports.delete(req.Guid) {
+		return "Does not delete", storage.ErrNotFound
+	}
+	a.db.deleteNames("airport", req.Guid)
+
+	for _, route := range a.db.routes.all(nil) {
+		if route.OriginAirportId == req.Guid || route.DestinationAirportId == req.Guid {
+			a.db.routes.delete(route.Guid)
+		}
+	}
+
+	for _, link := range a.db.airlineAirport.all(nil) {
+		if link.AirportId == req.Guid {
+			a.db.airlineAirport.delete(airlineAirportKey(link.AirlineId, link.AirportId))
+		}
+	}
+
+	return "Deleted", nil
+}
+
+func (a *AirportRepo) Upload(ctx context.Context, req []models.CreateAirport) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	for _, v := range req {
+		_, err := a.insert(v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+var aliasEntities = map[string]bool{"city": true, "airport": true}
+
+type AliasRepo struct {
+	db *database
+}
+
+func NewAliasRepo(db *database) *AliasRepo {
+	return &AliasRepo{
+		db: db,
+	}
+}
+
+func (a *AliasRepo) Create(ctx context.Context, req models.CreateAlias) (*models.Alias, error) {
+	if err := contextError(ctx); err != nil {
+		return &models.Alias{}, err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	if !aliasEntities[req.Entity] {
+		return &models.Alias{}, constraintError("alias entity %q is not supported", req.Entity)
+	}
+
+	for _, alias := range a.db.aliases.rows {
+		if alias.Entity == req.Entity && alias.EntityId == req.EntityId && alias.Title == req.Title {
+			return &models.Alias{}, constraintError("alias %q already exists", req.Title)
+		}
+	}
+
+	alias := models.Alias{
+		Guid:      uuid.New().String(),
+		Entity:    req.Entity,
+		EntityId:  req.EntityId,
+		Title:     req.Title,
+		CreatedAt: now(),
+	}
+	a.db.aliases.put(alias.Guid, alias)
+
+	return &alias, nil
+}
+
+func (a *AliasRepo) GetById(ctx context.Context, req models.AliasPrimaryKey) (*models.Alias, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	a.db.mu.RLock()
+	defer a.db.mu.RUnlock()
+
+	alias, ok := a.db.aliases.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return &alias, nil
+}
+
+func (a *AliasRepo) GetList(ctx context.Context, req models.GetListAliasRequest) (*models.GetListAliasResponse, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	a.db.mu.RLock()
+	defer a.db.mu.RUnlock()
+
+	var resp = models.GetListAliasResponse{}
+
+	aliases := a.db.aliases.all(func(alias models.Alias) bool {
+		return (len(req.Entity) == 0 || alias.Entity == req.Entity) &&
+			(len(req.EntityId) == 0 || alias.EntityId == req.EntityId)
+	})
+	sort.SliceStable(aliases, func(i, j int) bool {
+		return aliases[i].Title < aliases[j].Title
+	})
+	resp.Aliases, resp.Count = page(aliases, req.Offset, req.Limit)
+
+	return &resp, nil
+}
+
+func (a *AliasRepo) Delete(ctx context.Context, req models.AliasPrimaryKey) (string, error) {
+	if err := contextError(ctx); err != nil {
+		return "Does not delete", err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	if !a.db.aliases.delete(req.Guid) {
+		return "Does not delete", storage.ErrNotFound
+	}
+
+	return "Deleted", nil
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+
+	"github.com/google/uuid"
+)
+
+var apiKeyRoles = map[string]bool{"reader": true, "editor": true, "admin": true}
+
+type ApiKeyRepo struct {
+	db *database
+}
+
+func NewApiKeyRepo(db *database) *ApiKeyRepo {
+	return &ApiKeyRepo{
+		db: db,
+	}
+}
+
+func (a *ApiKeyRepo) Create(ctx context.Context, req models.CreateApiKey) (*models.ApiKey, error) {
+	if err := contextError(ctx); err != nil {
+		return &models.ApiKey{}, err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	if !apiKeyRoles[req.Role] {
+		return &models.ApiKey{}, constraintError("api key role %q is not supported", req.Role)
+	}
+
+	if _, ok := a.db.apiKeyHashes[req.Hash]; ok {
+		return &models.ApiKey{}, constraintError("api key hash already exists")
+	}
+
+	apiKey := models.ApiKey{
+		Guid:      uuid.New().String(),
+		Name:      req.Name,
+		Role:      req.Role,
+		Prefix:    req.Prefix,
+		CreatedAt: now(),
+	}
+	a.db.apiKeys.put(apiKey.Guid, apiKey)
+	a.db.apiKeyHashes[req.Hash] = apiKey.Guid
+
+	return &apiKey, nil
+}
+
+func (a *ApiKeyRepo) GetById(ctx context.Context, req models.ApiKeyPrimaryKey) (*models.ApiKey, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	a.db.mu.RLock()
+	defer a.db.mu.RUnlock()
+
+	apiKey, ok := a.db.apiKeys.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return &apiKey, nil
+}
+
+// GetByHash finds the key a client sent by its hash, revoked or not.
+func (a *ApiKeyRepo) GetByHash(ctx context.Context, req models.ApiKeyHash) (*models.ApiKey, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	a.db.mu.RLock()
+	defer a.db.mu.RUnlock()
+
+	apiKey, ok := a.db.apiKeys.get(a.db.apiKeyHashes[req.Hash])
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return &apiKey, nil
+}
+
+func (a *ApiKeyRepo) GetList(ctx context.Context, req models.GetListApiKeyRequest) (*models.GetListApiKeyResponse, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	a.db.mu.RLock()
+	defer a.db.mu.RUnlock()
+
+	var resp = models.GetListApiKeyResponse{}
+
+	apiKeys := a.db.apiKeys.all(func(apiKey models.ApiKey) bool {
+		return req.IncludeRevoked || len(apiKey.RevokedAt) == 0
+	})
+	resp.ApiKeys, resp.Count = page(apiKeys, req.Offset, req.Limit)
+
+	return &resp, nil
+}
+
+// Revoke disables a key for good. Revoking a revoked key keeps the time it
+// was first revoked.
+func (a *ApiKeyRepo) Revoke(ctx context.Context, req models.ApiKeyPrimaryKey) (*models.ApiKey, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	apiKey, ok := a.db.apiKeys.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	if len(apiKey.RevokedAt) == 0 {
+		apiKey.RevokedAt = now()
+		a.db.apiKeys.put(apiKey.Guid, apiKey)
+	}
+
+	return &apiKey, nil
+}
@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+
+	"github.com/google/uuid"
+)
+
+type CityRepo struct {
+	db *database
+}
+
+func NewCityRepo(db *database) *CityRepo {
+	return &CityRepo{
+		db: db,
+	}
+}
+
+func (c *CityRepo) Create(ctx context.Context, req models.CreateCity) (*models.City, error) {
+	if err := contextError(ctx); err != nil {
+		return &models.City{}, err
+	}
+
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	city := c.insert(req)
+
+	return &city, nil
+}
+
+func (c *CityRepo) insert(req models.CreateCity) models.City {
+	created := now()
+	city := models.City{
+		Guid:        uuid.New().String(),
+		Title:       req.Title,
+		CountryId:   req.CountryId,
+		CityCode:    req.CityCode,
+		Latitude:    req.Latitude,
+		Longitude:   req.Longitude,
+		Offset:      req.Offset,
+		TimezoneId:  req.TimezoneId,
+		CountryName: req.CountryName,
+		CreatedAt:   created,
+		UpdatedAt:   created,
+		Version:     1,
+	}
+	c.db.cities.put(city.Guid, city)
+
+	return city
+}
+
+func (c *CityRepo) GetById(ctx context.Context, req models.CityPrimaryKey) (*models.City, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	city, ok := c.db.cities.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return &city, nil
+}
+
+func (c *CityRepo) GetList(ctx context.Context, req models.GetListCityRequest) (*models.GetListCityResponse, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	var resp = models.GetListCityResponse{}
+
+	cities := c.db.cities.all(func(city models.City) bool {
+		return len(req.Search) == 0 ||
+			contains(city.Title, req.Search) ||
+			contains(city.CityCode, req.Search) ||
+			c.db.hasAlias("city", city.Guid, req.Search)
+	})
+	resp.Cities, resp.Count = page(cities, req.Offset, req.Limit)
+
+	return &resp, nil
+}
+
+func (c *CityRepo) Update(ctx context.Context, req models.UpdateCity) (*models.City, error) {
+	if err := contextError(ctx); err != nil {
+		return &models.City{}, err
+	}
+
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	city, ok := c.db.cities.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	if req.Version != 0 && req.Version != city.Version {
+		return &city, storage.ErrConflict
+	}
+
+	city.Title = req.Title
+	city.CountryId = req.CountryId
+	city.CityCode = req.CityCode
+	city.Latitude = req.Latitude
+	city.Longitude = req.Longitude
+	city.Offset = req.Offset
+	city.TimezoneId = req.TimezoneId
+	city.CountryName = req.CountryName
+	city.UpdatedAt = now()
+	city.Version++
+	c.db.cities.put(city.Guid, city)
+
+	return &city, nil
+}
+
+func (c *CityRepo) Delete(ctx context.Context, req models.CityPrimaryKey) (string, error) {
+	if err := contextError(ctx); err != nil {
+		return "Does not delete", err
+	}
+
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	if !c.db.cities.has(req.Guid) {
+		return "Does not delete", storage.ErrNotFound
+	}
+
+	for _, airport := range c.db.airports.rows {
+		if airport.CityId == req.Guid {
+			return "Does not delete", constraintError("city %s is referenced by airport %s", req.Guid, airport.Guid)
+		}
+	}
+
+	c.db.cities.delete(req.Guid)
+	c.db.deleteNames("city", req.Guid)
+
+	return "Deleted", nil
+}
+
+func (c *CityRepo) Upload(ctx context.Context, req []models.CreateCity) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	for _, v := range req {
+		c.insert(v)
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+
+	"github.com/google/uuid"
+)
+
+type CountryRepo struct {
+	db *database
+}
+
+func NewCountryRepo(db *database) *CountryRepo {
+	return &CountryRepo{
+		db: db,
+	}
+}
+
+func (c *CountryRepo) Create(ctx context.Context, req models.CreateCountry) (*models.Country, error) {
+	if err := contextError(ctx); err != nil {
+		return &models.Country{}, err
+	}
+
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	country := c.insert(req)
+
+	return &country, nil
+}
+
+func (c *CountryRepo) insert(req models.CreateCountry) models.Country {
+	created := now()
+	country := models.Country{
+		Guid:        uuid.New().String(),
+		Title:       req.Title,
+		Code:        req.Code,
+		Continent:   req.Continent,
+		CallingCode: req.CallingCode,
+		CreatedAt:   created,
+		UpdatedAt:   created,
+		Version:     1,
+	}
+	c.db.countries.put(country.Guid, country)
+
+	return country
+}
+
+func (c *CountryRepo) GetById(ctx context.Context, req models.CountryPrimaryKey) (*models.Country, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	country, ok := c.db.countries.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return &country, nil
+}
+
+func (c *CountryRepo) GetList(ctx context.Context, req models.GetListCountryRequest) (*models.GetListCountryResponse, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	var resp = models.GetListCountryResponse{}
+	countries := c.db.countries.all(func(country models.Country) bool {
+		return len(req.CallingCode) == 0 || country.CallingCode == req.CallingCode
+	})
+	resp.Countries, resp.Count = page(countries, req.Offset, req.Limit)
+
+	return &resp, nil
+}
+
+func (c *CountryRepo) Update(ctx context.Context, req models.UpdateCountry) (*models.Country, error) {
+	if err := contextError(ctx); err != nil {
+		return &models.Country{}, err
+	}
+
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	country, ok := c.db.countries.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	if req.Version != 0 && req.Version != country.Version {
+		return &country, storage.ErrConflict
+	}
+
+	country.Title = req.Title
+	country.Code = req.Code
+	country.Continent = req.Continent
+	country.CallingCode = req.CallingCode
+	country.UpdatedAt = now()
+	country.Version++
+	c.db.countries.put(country.Guid, country)
+
+	return &country, nil
+}
+
+func (c *CountryRepo) Delete(ctx context.Context, req models.CountryPrimaryKey) (string, error) {
+	if err := contextError(ctx); err != nil {
+		return "Does not delete", err
+	}
+
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	if !c.db.countries.has(req.Guid) {
+		return "Does not delete", storage.ErrNotFound
+	}
+
+	for _, airport := range c.db.airports.rows {
+		if airport.CountryId == req.Guid {
+			return "Does not delete", constraintError("country %s is referenced by airport %s", req.Guid, airport.Guid)
+		}
+	}
+
+	for _, airline := range c.db.airlines.rows {
+		if airline.CountryId == req.Guid {
+			return "Does not delete", constraintError("country %s is referenced by airline %s", req.Guid, airline.Guid)
+		}
+	}
+
+	c.db.countries.delete(req.Guid)
+	c.db.deleteNames("country", req.Guid)
+
+	return "Deleted", nil
+}
+
+func (c *CountryRepo) Upload(ctx context.Context, req []models.CreateCountry) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	for _, v := range req {
+		c.insert(v)
+	}
+
+	return nil
+}
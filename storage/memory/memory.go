@@ -0,0 +1,287 @@
+// Package memory implements storage.StorageI on top of plain Go maps. It
+// keeps everything in the process and loses it on exit, which makes it
+// useful for tests and demos that should run without a database.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"essy_travel/models"
+	"essy_travel/storage"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Store struct {
+	db *database
+
+	city    *CityRepo
+	country *CountryRepo
+	airport *AirportRepo
+	route   *RouteRepo
+	airline *AirlineRepo
+
+	translation *TranslationRepo
+	alias       *AliasRepo
+	apiKey      *ApiKeyRepo
+	user        *UserRepo
+}
+
+// database holds the tables shared by all repos of a Store. A single lock
+// guards them so that checks across tables, such as references, see a
+// consistent state.
+type database struct {
+	mu sync.RWMutex
+
+	countries      *table[models.Country]
+	cities         *table[models.City]
+	airports       *table[models.Airport]
+	routes         *table[models.Route]
+	airlines       *table[models.Airline]
+	airlineAirport *table[models.AirlineAirport]
+	translations   *table[models.Translation]
+	aliases        *table[models.Alias]
+	apiKeys        *table[models.ApiKey]
+	users          *table[models.User]
+
+	// apiKeyHashes maps the hash of an api key to its guid; the hash is
+	// not part of models.ApiKey.
+	apiKeyHashes map[string]string
+}
+
+func NewStore() storage.StorageI {
+	return &Store{
+		db: &database{
+			countries:      newTable[models.Country](),
+			cities:         newTable[models.City](),
+			airports:       newTable[models.Airport](),
+			routes:         newTable[models.Route](),
+			airlines:       newTable[models.Airline](),
+			airlineAirport: newTable[models.AirlineAirport](),
+			translations:   newTable[models.Translation](),
+			aliases:        newTable[models.Alias](),
+			apiKeys:        newTable[models.ApiKey](),
+			users:          newTable[models.User](),
+			apiKeyHashes:   map[string]string{},
+		},
+	}
+}
+
+func (s *Store) City() storage.CityRepoI {
+	if s.city == nil {
+		s.city = NewCityRepo(s.db)
+	}
+	return s.city
+}
+
+func (s *Store) Country() storage.CountryRepoI {
+	if s.country == nil {
+		s.country = NewCountryRepo(s.db)
+	}
+	return s.country
+}
+
+func (s *Store) Airport() storage.AirportRepoI {
+	if s.airport == nil {
+		s.airport = NewAirportRepo(s.db)
+	}
+	return s.airport
+}
+
+func (s *Store) Route() storage.RouteRepoI {
+	if s.route == nil {
+		s.route = NewRouteRepo(s.db)
+	}
+	return s.route
+}
+
+func (s *Store) Airline() storage.AirlineRepoI {
+	if s.airline == nil {
+		s.airline = NewAirlineRepo(s.db)
+	}
+	return s.airline
+}
+
+func (s *Store) Translation() storage.TranslationRepoI {
+	if s.translation == nil {
+		s.translation = NewTranslationRepo(s.db)
+	}
+	return s.translation
+}
+
+func (s *Store) Alias() storage.AliasRepoI {
+	if s.alias == nil {
+		s.alias = NewAliasRepo(s.db)
+	}
+	return s.alias
+}
+
+func (s *Store) ApiKey() storage.ApiKeyRepoI {
+	if s.apiKey == nil {
+		s.apiKey = NewApiKeyRepo(s.db)
+	}
+	return s.apiKey
+}
+
+func (s *Store) User() storage.UserRepoI {
+	if s.user == nil {
+		s.user = NewUserRepo(s.db)
+	}
+	return s.user
+}
+
+// Ping always succeeds unless ctx is done; there is nothing to reach.
+func (s *Store) Ping(ctx context.Context) error {
+	return contextError(ctx)
+}
+
+// Schema reports no migrations, the tables exist from the start.
+func (s *Store) Schema(ctx context.Context) (storage.Schema, error) {
+	return storage.Schema{}, contextError(ctx)
+}
+
+func (s *Store) Stats() sql.DBStats {
+	return sql.DBStats{}
+}
+
+func (s *Store) Close() error {
+	return nil
+}
+
+// table keeps rows by primary key and remembers the order they were
+// inserted in, which is the order lists are returned in when the Postgres
+// queries do not sort either.
+type table[T any] struct {
+	rows  map[string]T
+	order []string
+}
+
+func newTable[T any]() *table[T] {
+	return &table[T]{rows: map[string]T{}}
+}
+
+func (t *table[T]) get(key string) (T, bool) {
+	row, ok := t.rows[key]
+	return row, ok
+}
+
+func (t *table[T]) has(key string) bool {
+	_, ok := t.rows[key]
+	return ok
+}
+
+func (t *table[T]) put(key string, row T) {
+	if _, ok := t.rows[key]; !ok {
+		t.order = append(t.order, key)
+	}
+	t.rows[key] = row
+}
+
+func (t *table[T]) delete(key string) bool {
+	if _, ok := t.rows[key]; !ok {
+		return false
+	}
+
+	delete(t.rows, key)
+	for i, k := range t.order {
+		if k == key {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			break
+		}
+	}
+
+	return true
+}
+
+// all returns the rows for which keep reports true, in insertion order.
+// A nil keep returns every row.
+func (t *table[T]) all(keep func(T) bool) []T {
+	var rows []T
+	for _, key := range t.order {
+		row := t.rows[key]
+		if keep == nil || keep(row) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// page applies offset and limit the way the Postgres repos do: a limit
+// below one means 10, and the count is the number of matching rows, or 0
+// when the page itself is empty, like COUNT(*) OVER() would report.
+func page[T any](rows []T, offset, limit int) ([]T, int) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	if offset >= len(rows) {
+		return nil, 0
+	}
+
+	end := offset + limit
+	if end > len(rows) {
+		end = len(rows)
+	}
+
+	return rows[offset:end], len(rows)
+}
+
+// contextError returns the error a cancelled or expired ctx would produce
+// in the Postgres repos.
+func contextError(ctx context.Context) error {
+	err := ctx.Err()
+	if errors.Is(err, context.DeadlineExceeded) {
+		return storage.ErrTimeout
+	}
+	return err
+}
+
+// constraintError builds an error matching storage.ErrConstraint.
+func constraintError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", storage.ErrConstraint, fmt.Sprintf(format, args...))
+}
+
+// contains reports whether s contains substr ignoring case, like ILIKE
+// '%substr%' does.
+func contains(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// now returns the current time formatted the way timestamps come back
+// from Postgres.
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+// deleteNames deletes the translations and aliases of the entity, as the
+// SQL backends do along with the entity.
+func (db *database) deleteNames(entity, entityId string) {
+	for _, translation := range db.translations.all(nil) {
+		if translation.Entity == entity && translation.EntityId == entityId {
+			db.translations.delete(translation.Guid)
+		}
+	}
+
+	for _, alias := range db.aliases.all(nil) {
+		if alias.Entity == entity && alias.EntityId == entityId {
+			db.aliases.delete(alias.Guid)
+		}
+	}
+}
+
+// hasAlias reports whether the entity has an alias matching search.
+func (db *database) hasAlias(entity, entityId, search string) bool {
+	for _, alias := range db.aliases.rows {
+		if alias.Entity == entity && alias.EntityId == entityId && contains(alias.Title, search) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,241 @@
+package memory
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+type RouteRepo struct {
+	db *database
+}
+
+func NewRouteRepo(db *database) *RouteRepo {
+	return &RouteRepo{
+		db: db,
+	}
+}
+
+func (r *RouteRepo) Create(ctx context.Context, req models.CreateRoute) (*models.Route, error) {
+	if err := contextError(ctx); err != nil {
+		return &models.Route{}, err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	route, err := r.insert(req)
+	if err != nil {
+		return &models.Route{}, err
+	}
+
+	return r.withTitles(route), nil
+}
+
+func (r *RouteRepo) insert(req models.CreateRoute) (models.Route, error) {
+	err := r.checkAirports(req.OriginAirportId, req.DestinationAirportId)
+	if err != nil {
+		return models.Route{}, err
+	}
+
+	created := now()
+	route := models.Route{
+		Guid:                 uuid.New().String(),
+		OriginAirportId:      req.OriginAirportId,
+		DestinationAirportId: req.DestinationAirportId,
+		CarrierCode:          req.CarrierCode,
+		Distance:             req.Distance,
+		Active:               req.Active == nil || *req.Active,
+		CreatedAt:            created,
+		UpdatedAt:            created,
+	}
+	r.db.routes.put(route.Guid, route)
+
+	return route, nil
+}
+
+// checkAirports mirrors the references and the check constraint of the
+// route table.
+func (r *RouteRepo) checkAirports(originId, destinationId string) error {
+	if !r.db.airports.has(originId) {
+		return constraintError("airport %s does not exist", originId)
+	}
+
+	if !r.db.airports.has(destinationId) {
+		return constraintError("airport %s does not exist", destinationId)
+	}
+
+	if originId == destinationId {
+		return constraintError("route origin and destination are the same airport")
+	}
+
+	return nil
+}
+
+// withTitles fills in the airport titles the Postgres repo joins in.
+func (r *RouteRepo) withTitles(route models.Route) *models.Route {
+	origin, _ := r.db.airports.get(route.OriginAirportId)
+	destination, _ := r.db.airports.get(route.DestinationAirportId)
+
+	route.OriginAirport = origin.Title
+	route.DestinationAirport = destination.Title
+
+	return &route
+}
+
+func (r *RouteRepo) GetById(ctx context.Context, req models.RoutePrimaryKey) (*models.Route, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	route, ok := r.db.routes.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return r.withTitles(route), nil
+}
+
+func (r *RouteRepo) GetList(ctx context.Context, req models.GetListRouteRequest) (*models.GetListRouteResponse, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var resp = models.GetListRouteResponse{}
+
+	routes := r.db.routes.all(func(route models.Route) bool {
+		if len(req.OriginAirportId) > 0 && route.OriginAirportId != req.OriginAirportId {
+			return false
+		}
+
+		if len(req.DestinationAirportId) > 0 && route.DestinationAirportId != req.DestinationAirportId {
+			return false
+		}
+
+		if len(req.OriginCityId) > 0 {
+			origin, _ := r.db.airports.get(route.OriginAirportId)
+			if origin.CityId != req.OriginCityId {
+				return false
+			}
+		}
+
+		if len(req.DestinationCityId) > 0 {
+			destination, _ := r.db.airports.get(route.DestinationAirportId)
+			if destination.CityId != req.DestinationCityId {
+				return false
+			}
+		}
+
+		return !req.OnlyActive || route.Active
+	})
+
+	routes, resp.Count = page(routes, req.Offset, req.Limit)
+	for _, route := range routes {
+		resp.Routes = append(resp.Routes, *r.withTitles(route))
+	}
+
+	return &resp, nil
+}
+
+// GetDestinations returns every airport reachable by a direct route
+// from the requested origin airport.
+func (r *RouteRepo) GetDestinations(ctx context.Context, req models.GetDestinationsRequest) (*models.GetListAirportResponse, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var (
+		resp         = models.GetListAirportResponse{}
+		destinations = map[string]bool{}
+	)
+
+	for _, route := range r.db.routes.rows {
+		if route.OriginAirportId == req.AirportId && (!req.OnlyActive || route.Active) {
+			destinations[route.DestinationAirportId] = true
+		}
+	}
+
+	airports := r.db.airports.all(func(airport models.Airport) bool {
+		return destinations[airport.Guid]
+	})
+	sort.SliceStable(airports, func(i, j int) bool {
+		return airports[i].Title < airports[j].Title
+	})
+	resp.Airports, resp.Count = page(airports, req.Offset, req.Limit)
+
+	return &resp, nil
+}
+
+func (r *RouteRepo) Update(ctx context.Context, req models.UpdateRoute) (*models.Route, error) {
+	if err := contextError(ctx); err != nil {
+		return &models.Route{}, err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	route, ok := r.db.routes.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	err := r.checkAirports(req.OriginAirportId, req.DestinationAirportId)
+	if err != nil {
+		return &models.Route{}, err
+	}
+
+	route.OriginAirportId = req.OriginAirportId
+	route.DestinationAirportId = req.DestinationAirportId
+	route.CarrierCode = req.CarrierCode
+	route.Distance = req.Distance
+	route.Active = req.Active
+	route.UpdatedAt = now()
+	r.db.routes.put(route.Guid, route)
+
+	return r.withTitles(route), nil
+}
+
+func (r *RouteRepo) Delete(ctx context.Context, req models.RoutePrimaryKey) (string, error) {
+	if err := contextError(ctx); err != nil {
+		return "Does not delete", err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if !r.db.routes.delete(req.Guid) {
+		return "Does not delete", storage.ErrNotFound
+	}
+
+	return "Deleted", nil
+}
+
+func (r *RouteRepo) Upload(ctx context.Context, req []models.CreateRoute) error {
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, v := range req {
+		_, err := r.insert(v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
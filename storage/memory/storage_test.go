@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"essy_travel/storage"
+	"essy_travel/storage/storagetest"
+	"testing"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.StorageI {
+		return NewStore()
+	})
+}
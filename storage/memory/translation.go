@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+var translationEntities = map[string]bool{"country": true, "city": true, "airport": true}
+
+type TranslationRepo struct {
+	db *database
+}
+
+func NewTranslationRepo(db *database) *TranslationRepo {
+	return &TranslationRepo{
+		db: db,
+	}
+}
+
+// Create inserts a translation, replacing the title when the entity
+// already has one in the same locale.
+func (t *TranslationRepo) Create(ctx context.Context, req models.CreateTranslation) (*models.Translation, error) {
+	if err := contextError(ctx); err != nil {
+		return &models.Translation{}, err
+	}
+
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	if !translationEntities[req.Entity] {
+		return &models.Translation{}, constraintError("translation entity %q is not supported", req.Entity)
+	}
+
+	updated := now()
+
+	translation, ok := t.find(req.Entity, req.EntityId, req.Locale)
+	if !ok {
+		translation = models.Translation{
+			Guid:      uuid.New().String(),
+			Entity:    req.Entity,
+			EntityId:  req.EntityId,
+			Locale:    req.Locale,
+			CreatedAt: updated,
+		}
+	}
+	translation.Title = req.Title
+	translation.UpdatedAt = updated
+	t.db.translations.put(translation.Guid, translation)
+
+	return &translation, nil
+}
+
+// find returns the translation of the entity in locale.
+func (t *TranslationRepo) find(entity, entityId, locale string) (models.Translation, bool) {
+	for _, translation := range t.db.translations.rows {
+		if translation.Entity == entity && translation.EntityId == entityId && translation.Locale == locale {
+			return translation, true
+		}
+	}
+	return models.Translation{}, false
+}
+
+func (t *TranslationRepo) GetById(ctx context.Context, req models.TranslationPrimaryKey) (*models.Translation, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	translation, ok := t.db.translations.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return &translation, nil
+}
+
+func (t *TranslationRepo) GetList(ctx context.Context, req models.GetListTranslationRequest) (*models.GetListTranslationResponse, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	var resp = models.GetListTranslationResponse{}
+
+	translations := t.db.translations.all(func(translation models.Translation) bool {
+		return (len(req.Entity) == 0 || translation.Entity == req.Entity) &&
+			(len(req.EntityId) == 0 || translation.EntityId == req.EntityId) &&
+			(len(req.Locale) == 0 || translation.Locale == req.Locale)
+	})
+	sort.SliceStable(translations, func(i, j int) bool {
+		a, b := translations[i], translations[j]
+		if a.Entity != b.Entity {
+			return a.Entity < b.Entity
+		}
+		if a.EntityId != b.EntityId {
+			return a.EntityId < b.EntityId
+		}
+		return a.Locale < b.Locale
+	})
+	resp.Translations, resp.Count = page(translations, req.Offset, req.Limit)
+
+	return &resp, nil
+}
+
+// GetTitles returns entity id -> title using, for every entity, the first
+// locale in req.Locales that has a translation. Entities without any
+// matching translation are left out of the result.
+func (t *TranslationRepo) GetTitles(ctx context.Context, req models.GetTitlesRequest) (map[string]string, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	var resp = map[string]string{}
+
+	for _, entityId := range req.EntityIds {
+		for _, locale := range req.Locales {
+			if translation, ok := t.find(req.Entity, entityId, locale); ok {
+				resp[entityId] = translation.Title
+				break
+			}
+		}
+	}
+
+	return resp, nil
+}
+
+func (t *TranslationRepo) Update(ctx context.Context, req models.UpdateTranslation) (*models.Translation, error) {
+	if err := contextError(ctx); err != nil {
+		return &models.Translation{}, err
+	}
+
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	translation, ok := t.db.translations.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	other, ok := t.find(translation.Entity, translation.EntityId, req.Locale)
+	if ok && other.Guid != translation.Guid {
+		return &models.Translation{}, constraintError("translation to %q already exists", req.Locale)
+	}
+
+	translation.Locale = req.Locale
+	translation.Title = req.Title
+	translation.UpdatedAt = now()
+	t.db.translations.put(translation.Guid, translation)
+
+	return &translation, nil
+}
+
+func (t *TranslationRepo) Delete(ctx context.Context, req models.TranslationPrimaryKey) (string, error) {
+	if err := contextError(ctx); err != nil {
+		return "Does not delete", err
+	}
+
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	if !t.db.translations.delete(req.Guid) {
+		return "Does not delete", storage.ErrNotFound
+	}
+
+	return "Deleted", nil
+}
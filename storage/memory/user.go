@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+var userRoles = map[string]bool{"reader": true, "editor": true, "admin": true}
+
+type UserRepo struct {
+	db *database
+}
+
+func NewUserRepo(db *database) *UserRepo {
+	return &UserRepo{
+		db: db,
+	}
+}
+
+func (u *UserRepo) Create(ctx context.Context, req models.CreateUser) (*models.User, error) {
+	if err := contextError(ctx); err != nil {
+		return &models.User{}, err
+	}
+
+	u.db.mu.Lock()
+	defer u.db.mu.Unlock()
+
+	if !userRoles[req.Role] {
+		return &models.User{}, constraintError("user role %q is not supported", req.Role)
+	}
+
+	if err := u.unique("", req.Login, req.Email); err != nil {
+		return &models.User{}, err
+	}
+
+	user := models.User{
+		Guid:         uuid.New().String(),
+		Login:        req.Login,
+		Email:        req.Email,
+		Phone:        req.Phone,
+		FullName:     req.FullName,
+		Role:         req.Role,
+		PasswordHash: req.PasswordHash,
+		TokenVersion: 1,
+		CreatedAt:    now(),
+	}
+	u.db.users.put(user.Guid, user)
+
+	return &user, nil
+}
+
+// unique checks that no user but guid has the login or the email.
+func (u *UserRepo) unique(guid, login, email string) error {
+	for _, user := range u.db.users.rows {
+		if user.Guid == guid {
+			continue
+		}
+		if user.Login == login {
+			return constraintError("user login %q already exists", login)
+		}
+		if user.Email == email {
+			return constraintError("user email %q already exists", email)
+		}
+	}
+	return nil
+}
+
+func (u *UserRepo) GetById(ctx context.Context, req models.UserPrimaryKey) (*models.User, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	u.db.mu.RLock()
+	defer u.db.mu.RUnlock()
+
+	user, ok := u.db.users.get(req.Guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return &user, nil
+}
+
+// GetByLogin finds the user whose login or email is req.Login.
+func (u *UserRepo) GetByLogin(ctx context.Context, req models.UserLoginKey) (*models.User, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	u.db.mu.RLock()
+	defer u.db.mu.RUnlock()
+
+	for _, user := range u.db.users.rows {
+		if user.Login == req.Login || user.Email == req.Login {
+			return &user, nil
+		}
+	}
+
+	return nil, storage.ErrNotFound
+}
+
+func (u *UserRepo) GetList(ctx context.Context, req models.GetListUserRequest) (*models.GetListUserResponse, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	u.db.mu.RLock()
+	defer u.db.mu.RUnlock()
+
+	var resp = models.GetListUserResponse{}
+
+	users := u.db.users.all(func(user models.User) bool {
+		return len(req.Search) == 0 || contains(user.Login, req.Search) || contains(user.Email, req.Search) || contains(user.FullName, req.Search)
+	})
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Login < users[j].Login
+	})
+
+	for i := range users {
+		users[i].PasswordHash = ""
+	}
+	resp.Users, resp.Count = page(users, req.Offset, req.Limit)
+
+	return &resp, nil
+}
+
+func (u *UserRepo) Update(ctx context.Context, req models.UpdateUser) (*models.User, error) {
+	return u.update(ctx, req.Guid, func(user *models.User) error {
+		if err := u.unique(user.Guid, user.Login, req.Email); err != nil {
+			return err
+		}
+
+		user.Email = req.Email
+		user.Phone = req.Phone
+		user.FullName = req.FullName
+		return nil
+	})
+}
+
+func (u *UserRepo) UpdatePassword(ctx context.Context, req models.UpdateUserPassword) (*models.User, error) {
+	return u.update(ctx, req.Guid, func(user *models.User) error {
+		user.PasswordHash = req.PasswordHash
+		user.TokenVersion++
+		return nil
+	})
+}
+
+func (u *UserRepo) UpdateRole(ctx context.Context, req models.UpdateUserRole) (*models.User, error) {
+	return u.update(ctx, req.Guid, func(user *models.User) error {
+		if !userRoles[req.Role] {
+			return constraintError("user role %q is not supported", req.Role)
+		}
+
+		user.Role = req.Role
+		return nil
+	})
+}
+
+// update applies set to the user guid under the write lock.
+func (u *UserRepo) update(ctx context.Context, guid string, set func(user *models.User) error) (*models.User, error) {
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	u.db.mu.Lock()
+	defer u.db.mu.Unlock()
+
+	user, ok := u.db.users.get(guid)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	if err := set(&user); err != nil {
+		return nil, err
+	}
+
+	user.UpdatedAt = now()
+	u.db.users.put(user.Guid, user)
+
+	return &user, nil
+}
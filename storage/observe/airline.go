@@ -0,0 +1,75 @@
+package observe
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+)
+
+type AirlineRepo struct {
+	repo      storage.AirlineRepoI
+	observers []Observer
+}
+
+func (a *AirlineRepo) Create(ctx context.Context, req models.CreateAirline) (*models.Airline, error) {
+	ctx, end := start(ctx, a.observers, "AirlineRepo", "Create")
+	resp, err := a.repo.Create(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *AirlineRepo) Update(ctx context.Context, req models.UpdateAirline) (*models.Airline, error) {
+	ctx, end := start(ctx, a.observers, "AirlineRepo", "Update")
+	resp, err := a.repo.Update(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *AirlineRepo) GetById(ctx context.Context, req models.AirlinePrimaryKey) (*models.Airline, error) {
+	ctx, end := start(ctx, a.observers, "AirlineRepo", "GetById")
+	resp, err := a.repo.GetById(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *AirlineRepo) GetList(ctx context.Context, req models.GetListAirlineRequest) (*models.GetListAirlineResponse, error) {
+	ctx, end := start(ctx, a.observers, "AirlineRepo", "GetList")
+	resp, err := a.repo.GetList(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *AirlineRepo) Delete(ctx context.Context, req models.AirlinePrimaryKey) (string, error) {
+	ctx, end := start(ctx, a.observers, "AirlineRepo", "Delete")
+	resp, err := a.repo.Delete(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *AirlineRepo) Upload(ctx context.Context, req []models.CreateAirline) error {
+	ctx, end := start(ctx, a.observers, "AirlineRepo", "Upload")
+	err := a.repo.Upload(ctx, req)
+	end(err)
+	return err
+}
+
+func (a *AirlineRepo) AddAirport(ctx context.Context, req models.CreateAirlineAirport) error {
+	ctx, end := start(ctx, a.observers, "AirlineRepo", "AddAirport")
+	err := a.repo.AddAirport(ctx, req)
+	end(err)
+	return err
+}
+
+func (a *AirlineRepo) RemoveAirport(ctx context.Context, req models.AirlineAirportPrimaryKey) error {
+	ctx, end := start(ctx, a.observers, "AirlineRepo", "RemoveAirport")
+	err := a.repo.RemoveAirport(ctx, req)
+	end(err)
+	return err
+}
+
+func (a *AirlineRepo) GetAirports(ctx context.Context, req models.AirlinePrimaryKey) (*models.GetListAirlineAirportResponse, error) {
+	ctx, end := start(ctx, a.observers, "AirlineRepo", "GetAirports")
+	resp, err := a.repo.GetAirports(ctx, req)
+	end(err)
+	return resp, err
+}
@@ -0,0 +1,61 @@
+package observe
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+)
+
+type AirportRepo struct {
+	repo      storage.AirportRepoI
+	observers []Observer
+}
+
+func (a *AirportRepo) Create(ctx context.Context, req models.CreateAirport) (*models.Airport, error) {
+	ctx, end := start(ctx, a.observers, "AirportRepo", "Create")
+	resp, err := a.repo.Create(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *AirportRepo) Update(ctx context.Context, req models.UpdateAirport) (*models.Airport, error) {
+	ctx, end := start(ctx, a.observers, "AirportRepo", "Update")
+	resp, err := a.repo.Update(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *AirportRepo) GetById(ctx context.Context, req models.AirportPrimaryKey) (*models.Airport, error) {
+	ctx, end := start(ctx, a.observers, "AirportRepo", "GetById")
+	resp, err := a.repo.GetById(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *AirportRepo) GetList(ctx context.Context, req models.GetListAirportRequest) (*models.GetListAirportResponse, error) {
+	ctx, end := start(ctx, a.observers, "AirportRepo", "GetList")
+	resp, err := a.repo.GetList(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *AirportRepo) UpdateImage(ctx context.Context, req models.UpdateAirportImage) (*models.Airport, error) {
+	ctx, end := start(ctx, a.observers, "AirportRepo", "UpdateImage")
+	resp, err := a.repo.UpdateImage(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *AirportRepo) Delete(ctx context.Context, req models.AirportPrimaryKey) (string, error) {
+	ctx, end := start(ctx, a.observers, "AirportRepo", "Delete")
+	resp, err := a.repo.Delete(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *AirportRepo) Upload(ctx context.Context, req []models.CreateAirport) error {
+	ctx, end := start(ctx, a.observers, "AirportRepo", "Upload")
+	err := a.repo.Upload(ctx, req)
+	end(err)
+	return err
+}
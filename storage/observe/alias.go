@@ -0,0 +1,40 @@
+package observe
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+)
+
+type AliasRepo struct {
+	repo      storage.AliasRepoI
+	observers []Observer
+}
+
+func (a *AliasRepo) Create(ctx context.Context, req models.CreateAlias) (*models.Alias, error) {
+	ctx, end := start(ctx, a.observers, "AliasRepo", "Create")
+	resp, err := a.repo.Create(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *AliasRepo) GetById(ctx context.Context, req models.AliasPrimaryKey) (*models.Alias, error) {
+	ctx, end := start(ctx, a.observers, "AliasRepo", "GetById")
+	resp, err := a.repo.GetById(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *AliasRepo) GetList(ctx context.Context, req models.GetListAliasRequest) (*models.GetListAliasResponse, error) {
+	ctx, end := start(ctx, a.observers, "AliasRepo", "GetList")
+	resp, err := a.repo.GetList(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *AliasRepo) Delete(ctx context.Context, req models.AliasPrimaryKey) (string, error) {
+	ctx, end := start(ctx, a.observers, "AliasRepo", "Delete")
+	resp, err := a.repo.Delete(ctx, req)
+	end(err)
+	return resp, err
+}
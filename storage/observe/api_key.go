@@ -0,0 +1,47 @@
+package observe
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+)
+
+type ApiKeyRepo struct {
+	repo      storage.ApiKeyRepoI
+	observers []Observer
+}
+
+func (a *ApiKeyRepo) Create(ctx context.Context, req models.CreateApiKey) (*models.ApiKey, error) {
+	ctx, end := start(ctx, a.observers, "ApiKeyRepo", "Create")
+	resp, err := a.repo.Create(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *ApiKeyRepo) GetById(ctx context.Context, req models.ApiKeyPrimaryKey) (*models.ApiKey, error) {
+	ctx, end := start(ctx, a.observers, "ApiKeyRepo", "GetById")
+	resp, err := a.repo.GetById(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *ApiKeyRepo) GetByHash(ctx context.Context, req models.ApiKeyHash) (*models.ApiKey, error) {
+	ctx, end := start(ctx, a.observers, "ApiKeyRepo", "GetByHash")
+	resp, err := a.repo.GetByHash(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *ApiKeyRepo) GetList(ctx context.Context, req models.GetListApiKeyRequest) (*models.GetListApiKeyResponse, error) {
+	ctx, end := start(ctx, a.observers, "ApiKeyRepo", "GetList")
+	resp, err := a.repo.GetList(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (a *ApiKeyRepo) Revoke(ctx context.Context, req models.ApiKeyPrimaryKey) (*models.ApiKey, error) {
+	ctx, end := start(ctx, a.observers, "ApiKeyRepo", "Revoke")
+	resp, err := a.repo.Revoke(ctx, req)
+	end(err)
+	return resp, err
+}
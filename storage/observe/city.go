@@ -0,0 +1,54 @@
+package observe
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+)
+
+type CityRepo struct {
+	repo      storage.CityRepoI
+	observers []Observer
+}
+
+func (c *CityRepo) Create(ctx context.Context, req models.CreateCity) (*models.City, error) {
+	ctx, end := start(ctx, c.observers, "CityRepo", "Create")
+	resp, err := c.repo.Create(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (c *CityRepo) Update(ctx context.Context, req models.UpdateCity) (*models.City, error) {
+	ctx, end := start(ctx, c.observers, "CityRepo", "Update")
+	resp, err := c.repo.Update(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (c *CityRepo) GetById(ctx context.Context, req models.CityPrimaryKey) (*models.City, error) {
+	ctx, end := start(ctx, c.observers, "CityRepo", "GetById")
+	resp, err := c.repo.GetById(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (c *CityRepo) GetList(ctx context.Context, req models.GetListCityRequest) (*models.GetListCityResponse, error) {
+	ctx, end := start(ctx, c.observers, "CityRepo", "GetList")
+	resp, err := c.repo.GetList(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (c *CityRepo) Delete(ctx context.Context, req models.CityPrimaryKey) (string, error) {
+	ctx, end := start(ctx, c.observers, "CityRepo", "Delete")
+	resp, err := c.repo.Delete(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (c *CityRepo) Upload(ctx context.Context, req []models.CreateCity) error {
+	ctx, end := start(ctx, c.observers, "CityRepo", "Upload")
+	err := c.repo.Upload(ctx, req)
+	end(err)
+	return err
+}
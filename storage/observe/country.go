@@ -0,0 +1,54 @@
+package observe
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+)
+
+type CountryRepo struct {
+	repo      storage.CountryRepoI
+	observers []Observer
+}
+
+func (c *CountryRepo) Create(ctx context.Context, req models.CreateCountry) (*models.Country, error) {
+	ctx, end := start(ctx, c.observers, "CountryRepo", "Create")
+	resp, err := c.repo.Create(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (c *CountryRepo) Update(ctx context.Context, req models.UpdateCountry) (*models.Country, error) {
+	ctx, end := start(ctx, c.observers, "CountryRepo", "Update")
+	resp, err := c.repo.Update(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (c *CountryRepo) GetById(ctx context.Context, req models.CountryPrimaryKey) (*models.Country, error) {
+	ctx, end := start(ctx, c.observers, "CountryRepo", "GetById")
+	resp, err := c.repo.GetById(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (c *CountryRepo) GetList(ctx context.Context, req models.GetListCountryRequest) (*models.GetListCountryResponse, error) {
+	ctx, end := start(ctx, c.observers, "CountryRepo", "GetList")
+	resp, err := c.repo.GetList(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (c *CountryRepo) Delete(ctx context.Context, req models.CountryPrimaryKey) (string, error) {
+	ctx, end := start(ctx, c.observers, "CountryRepo", "Delete")
+	resp, err := c.repo.Delete(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (c *CountryRepo) Upload(ctx context.Context, req []models.CreateCountry) error {
+	ctx, end := start(ctx, c.observers, "CountryRepo", "Upload")
+	err := c.repo.Upload(ctx, req)
+	end(err)
+	return err
+}
@@ -0,0 +1,119 @@
+// Package observe wraps a storage.StorageI so that every repo call is
+// reported to observers, such as the metrics, before and after it runs.
+package observe
+
+import (
+	"context"
+	"database/sql"
+	"essy_travel/storage"
+)
+
+// Observer is told about every repo call. Start runs before the call and
+// may return a derived context for it; the returned func gets its error.
+type Observer interface {
+	Start(ctx context.Context, repo, method string) (context.Context, func(error))
+}
+
+type Store struct {
+	strg      storage.StorageI
+	observers []Observer
+
+	city    *CityRepo
+	country *CountryRepo
+	airport *AirportRepo
+	route   *RouteRepo
+	airline *AirlineRepo
+
+	translation *TranslationRepo
+	alias       *AliasRepo
+	apiKey      *ApiKeyRepo
+	user        *UserRepo
+}
+
+// New returns strg reporting to observers, in order.
+func New(strg storage.StorageI, observers ...Observer) storage.StorageI {
+	return &Store{
+		strg:      strg,
+		observers: observers,
+
+		city:    &CityRepo{repo: strg.City(), observers: observers},
+		country: &CountryRepo{repo: strg.Country(), observers: observers},
+		airport: &AirportRepo{repo: strg.Airport(), observers: observers},
+		route:   &RouteRepo{repo: strg.Route(), observers: observers},
+		airline: &AirlineRepo{repo: strg.Airline(), observers: observers},
+
+		translation: &TranslationRepo{repo: strg.Translation(), observers: observers},
+		alias:       &AliasRepo{repo: strg.Alias(), observers: observers},
+		apiKey:      &ApiKeyRepo{repo: strg.ApiKey(), observers: observers},
+		user:        &UserRepo{repo: strg.User(), observers: observers},
+	}
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return s.strg.Ping(ctx)
+}
+
+func (s *Store) Schema(ctx context.Context) (storage.Schema, error) {
+	return s.strg.Schema(ctx)
+}
+
+func (s *Store) Stats() sql.DBStats {
+	return s.strg.Stats()
+}
+
+func (s *Store) Close() error {
+	return s.strg.Close()
+}
+
+func (s *Store) City() storage.CityRepoI {
+	return s.city
+}
+
+func (s *Store) Country() storage.CountryRepoI {
+	return s.country
+}
+
+func (s *Store) Airport() storage.AirportRepoI {
+	return s.airport
+}
+
+func (s *Store) Route() storage.RouteRepoI {
+	return s.route
+}
+
+func (s *Store) Airline() storage.AirlineRepoI {
+	return s.airline
+}
+
+func (s *Store) Translation() storage.TranslationRepoI {
+	return s.translation
+}
+
+func (s *Store) Alias() storage.AliasRepoI {
+	return s.alias
+}
+
+func (s *Store) ApiKey() storage.ApiKeyRepoI {
+	return s.apiKey
+}
+
+func (s *Store) User() storage.UserRepoI {
+	return s.user
+}
+
+// start reports the beginning of a call to every observer and returns the
+// context to run it with and the func that reports its end.
+func start(ctx context.Context, observers []Observer, repo, method string) (context.Context, func(error)) {
+	var ends = make([]func(error), 0, len(observers))
+	for _, observer := range observers {
+		var end func(error)
+		ctx, end = observer.Start(ctx, repo, method)
+		ends = append(ends, end)
+	}
+
+	return ctx, func(err error) {
+		for i := len(ends) - 1; i >= 0; i-- {
+			ends[i](err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package observe
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+)
+
+type RouteRepo struct {
+	repo      storage.RouteRepoI
+	observers []Observer
+}
+
+func (r *RouteRepo) Create(ctx context.Context, req models.CreateRoute) (*models.Route, error) {
+	ctx, end := start(ctx, r.observers, "RouteRepo", "Create")
+	resp, err := r.repo.Create(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (r *RouteRepo) Update(ctx context.Context, req models.UpdateRoute) (*models.Route, error) {
+	ctx, end := start(ctx, r.observers, "RouteRepo", "Update")
+	resp, err := r.repo.Update(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (r *RouteRepo) GetById(ctx context.Context, req models.RoutePrimaryKey) (*models.Route, error) {
+	ctx, end := start(ctx, r.observers, "RouteRepo", "GetById")
+	resp, err := r.repo.GetById(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (r *RouteRepo) GetList(ctx context.Context, req models.GetListRouteRequest) (*models.GetListRouteResponse, error) {
+	ctx, end := start(ctx, r.observers, "RouteRepo", "GetList")
+	resp, err := r.repo.GetList(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (r *RouteRepo) GetDestinations(ctx context.Context, req models.GetDestinationsRequest) (*models.GetListAirportResponse, error) {
+	ctx, end := start(ctx, r.observers, "RouteRepo", "GetDestinations")
+	resp, err := r.repo.GetDestinations(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (r *RouteRepo) Delete(ctx context.Context, req models.RoutePrimaryKey) (string, error) {
+	ctx, end := start(ctx, r.observers, "RouteRepo", "Delete")
+	resp, err := r.repo.Delete(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (r *RouteRepo) Upload(ctx context.Context, req []models.CreateRoute) error {
+	ctx, end := start(ctx, r.observers, "RouteRepo", "Upload")
+	err := r.repo.Upload(ctx, req)
+	end(err)
+	return err
+}
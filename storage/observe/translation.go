@@ -0,0 +1,54 @@
+package observe
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+)
+
+type TranslationRepo struct {
+	repo      storage.TranslationRepoI
+	observers []Observer
+}
+
+func (t *TranslationRepo) Create(ctx context.Context, req models.CreateTranslation) (*models.Translation, error) {
+	ctx, end := start(ctx, t.observers, "TranslationRepo", "Create")
+	resp, err := t.repo.Create(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (t *TranslationRepo) Update(ctx context.Context, req models.UpdateTranslation) (*models.Translation, error) {
+	ctx, end := start(ctx, t.observers, "TranslationRepo", "Update")
+	resp, err := t.repo.Update(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (t *TranslationRepo) GetById(ctx context.Context, req models.TranslationPrimaryKey) (*models.Translation, error) {
+	ctx, end := start(ctx, t.observers, "TranslationRepo", "GetById")
+	resp, err := t.repo.GetById(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (t *TranslationRepo) GetList(ctx context.Context, req models.GetListTranslationRequest) (*models.GetListTranslationResponse, error) {
+	ctx, end := start(ctx, t.observers, "TranslationRepo", "GetList")
+	resp, err := t.repo.GetList(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (t *TranslationRepo) GetTitles(ctx context.Context, req models.GetTitlesRequest) (map[string]string, error) {
+	ctx, end := start(ctx, t.observers, "TranslationRepo", "GetTitles")
+	resp, err := t.repo.GetTitles(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (t *TranslationRepo) Delete(ctx context.Context, req models.TranslationPrimaryKey) (string, error) {
+	ctx, end := start(ctx, t.observers, "TranslationRepo", "Delete")
+	resp, err := t.repo.Delete(ctx, req)
+	end(err)
+	return resp, err
+}
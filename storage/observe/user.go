@@ -0,0 +1,61 @@
+package observe
+
+import (
+	"context"
+	"essy_travel/models"
+	"essy_travel/storage"
+)
+
+type UserRepo struct {
+	repo      storage.UserRepoI
+	observers []Observer
+}
+
+func (u *UserRepo) Create(ctx context.Context, req models.CreateUser) (*models.User, error) {
+	ctx, end := start(ctx, u.observers, "UserRepo", "Create")
+	resp, err := u.repo.Create(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (u *UserRepo) GetById(ctx context.Context, req models.UserPrimaryKey) (*models.User, error) {
+	ctx, end := start(ctx, u.observers, "UserRepo", "GetById")
+	resp, err := u.repo.GetById(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (u *UserRepo) GetByLogin(ctx context.Context, req models.UserLoginKey) (*models.User, error) {
+	ctx, end := start(ctx, u.observers, "UserRepo", "GetByLogin")
+	resp, err := u.repo.GetByLogin(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (u *UserRepo) GetList(ctx context.Context, req models.GetListUserRequest) (*models.GetListUserResponse, error) {
+	ctx, end := start(ctx, u.observers, "UserRepo", "GetList")
+	resp, err := u.repo.GetList(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (u *UserRepo) Update(ctx context.Context, req models.UpdateUser) (*models.User, error) {
+	ctx, end := start(ctx, u.observers, "UserRepo", "Update")
+	resp, err := u.repo.Update(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (u *UserRepo) UpdatePassword(ctx context.Context, req models.UpdateUserPassword) (*models.User, error) {
+	ctx, end := start(ctx, u.observers, "UserRepo", "UpdatePassword")
+	resp, err := u.repo.UpdatePassword(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func (u *UserRepo) UpdateRole(ctx context.Context, req models.UpdateUserRole) (*models.User, error) {
+	ctx, end := start(ctx, u.observers, "UserRepo", "UpdateRole")
+	resp, err := u.repo.UpdateRole(ctx, req)
+	end(err)
+	return resp, err
+}
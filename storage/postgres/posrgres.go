@@ -1,58 +1,95 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"essy_travel/config"
+	"essy_travel/migrations"
+	"essy_travel/pkg/tracing"
 	"essy_travel/storage"
+	"essy_travel/storage/sqlstore"
 	"fmt"
+	"net"
+	"net/url"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
 )
 
-type Store struct {
-	db      *sql.DB
-	city    *CityRepo
-	country *CountryRepo
-	airport *AirportRepo
+// Dialect runs the sqlstore queries on Postgres.
+var Dialect = &sqlstore.Dialect{
+	ILike:      "ILIKE",
+	Now:        "NOW()",
+	Migrations: migrations.Postgres,
+	Constraint: func(err error) (string, bool) {
+		// Class 23 holds the integrity constraint violations.
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Class() == "23" {
+			return pqErr.Message, true
+		}
+		return "", false
+	},
 }
 
-func NewConnectionPostgres(cfg *config.Config) (storage.StorageI, error) {
-	connect := fmt.Sprintf(
-		"host=%s user=%s dbname=%s password=%s port=%s sslmode=disable",
-		cfg.PostgresHost,
-		cfg.PostgresUser,
-		cfg.PostgresDatabase,
-		cfg.PostgresPassword,
-		cfg.PostgresPort,
-	)
-
-	db, err := sql.Open("postgres", connect)
+// Open opens the Postgres database at cfg.DatabaseURL, or the one the
+// Postgres* fields describe, with the configured pool limits.
+func Open(cfg *config.Config) (*sql.DB, error) {
+	connect := cfg.DatabaseURL
+	if len(connect) == 0 {
+		connect = (&url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(cfg.PostgresUser, cfg.PostgresPassword),
+			Host:     net.JoinHostPort(cfg.PostgresHost, cfg.PostgresPort),
+			Path:     cfg.PostgresDatabase,
+			RawQuery: url.Values{"sslmode": {cfg.PostgresSSLMode}}.Encode(),
+		}).String()
+	}
+
+	db, err := tracing.OpenDB("postgres", connect, semconv.DBSystemPostgreSQL)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	return &Store{
-		db: db,
-	}, nil
+	db.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	db.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
+
+	return db, nil
 }
 
-func (s *Store) City() storage.CityRepoI {
-	if s.city == nil {
-		s.city = NewCityRepo(s.db)
+func NewConnectionPostgres(cfg *config.Config) (storage.StorageI, error) {
+	db, err := Open(cfg)
+	if err != nil {
+		return nil, err
 	}
-	return s.city
-}
 
-func (s *Store) Airport() storage.AirportRepoI {
-	if s.airport == nil {
-		s.airport = NewAirportRepo(s.db)
+	// sql.Open only checks the arguments; fail here rather than on the
+	// first request when the database cannot be reached.
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DBReadTimeout)
+	defer cancel()
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("postgres: %w", err)
 	}
-	return s.airport
-}
 
-func (s *Store) Country() storage.CountryRepoI {
-	if s.country == nil {
-		s.country = NewCountryRepo(s.db)
+	if cfg.AutoMigrate {
+		runner, err := migrations.NewRunner(db, migrations.Postgres)
+		if err == nil {
+			err = runner.Up(context.Background())
+		}
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
 	}
-	return s.country
+
+	return sqlstore.New(db, storage.Timeouts{
+		Read:   cfg.DBReadTimeout,
+		Write:  cfg.DBWriteTimeout,
+		Upload: cfg.DBUploadTimeout,
+	}, Dialect), nil
 }
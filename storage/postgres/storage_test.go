@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"essy_travel/config"
+	"essy_travel/migrations"
+	"essy_travel/storage"
+	"essy_travel/storage/storagetest"
+	"os"
+	"sync"
+	"testing"
+)
+
+// TestStorage runs the conformance suite against the database at
+// TEST_DATABASE_URL, which it migrates and empties; every table in it is
+// lost.
+func TestStorage(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if len(dsn) == 0 {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	cfg := &config.Config{
+		DatabaseURL:    dsn,
+		AutoMigrate:    true,
+		DBMaxOpenConns: 4,
+		DBMaxIdleConns: 4,
+	}
+
+	storagetest.Run(t, func(t *testing.T) storage.StorageI {
+		strg, err := NewConnectionPostgres(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { strg.Close() })
+
+		db, err := Open(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		_, err = db.Exec(`TRUNCATE country, city, airport, route, airline, airline_airport, translation, alias, api_key, users CASCADE`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return strg
+	})
+}
+
+// TestMigrateConcurrently starts several runners at once, as instances
+// starting with AUTO_MIGRATE do; the advisory lock lets one migrate and
+// the others find the work done. It rolls back every migration of the
+// database at TEST_DATABASE_URL first.
+func TestMigrateConcurrently(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if len(dsn) == 0 {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	db, err := Open(&config.Config{DatabaseURL: dsn, DBMaxOpenConns: 8, DBMaxIdleConns: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	runner, err := migrations.NewRunner(db, migrations.Postgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = runner.Goto(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, 4)
+	)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			runner, err := migrations.NewRunner(db, migrations.Postgres)
+			if err == nil {
+				err = runner.Up(ctx)
+			}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("runner %d: %v", i, err)
+		}
+	}
+
+	version, dirty, err := runner.Version(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != runner.Latest() || dirty {
+		t.Errorf("version %d, dirty %v; want %d, clean", version, dirty, runner.Latest())
+	}
+}
@@ -0,0 +1,81 @@
+// Package sqlite implements storage.StorageI on SQLite through the pure Go
+// modernc.org/sqlite driver, for local development and small offline
+// deployments that do not want to run Postgres.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"essy_travel/config"
+	"essy_travel/migrations"
+	"essy_travel/pkg/tracing"
+	"essy_travel/storage"
+	"essy_travel/storage/sqlstore"
+	"net/url"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	sqlitedriver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Dialect runs the sqlstore queries on SQLite, which matches LIKE ignoring
+// the case of ASCII letters already.
+var Dialect = &sqlstore.Dialect{
+	ILike:      "LIKE",
+	Now:        "strftime('%Y-%m-%dT%H:%M:%fZ', 'now')",
+	Migrations: migrations.SQLite,
+	Constraint: func(err error) (string, bool) {
+		// Extended result codes keep the primary code in the low byte.
+		var sqliteErr *sqlitedriver.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.Code()&0xff == sqlite3.SQLITE_CONSTRAINT {
+			return sqliteErr.Error(), true
+		}
+		return "", false
+	},
+}
+
+// Open opens the SQLite database file at cfg.SQLitePath with foreign keys
+// enforced and the configured pool limits.
+func Open(cfg *config.Config) (*sql.DB, error) {
+	pragmas := url.Values{}
+	pragmas.Add("_pragma", "foreign_keys(1)")
+	pragmas.Add("_pragma", "busy_timeout(5000)")
+	pragmas.Add("_pragma", "journal_mode(WAL)")
+
+	db, err := tracing.OpenDB("sqlite", "file:"+cfg.SQLitePath+"?"+pragmas.Encode(), semconv.DBSystemSqlite)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	db.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
+
+	return db, nil
+}
+
+func NewConnectionSQLite(cfg *config.Config) (storage.StorageI, error) {
+	db, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.AutoMigrate {
+		runner, err := migrations.NewRunner(db, migrations.SQLite)
+		if err == nil {
+			err = runner.Up(context.Background())
+		}
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return sqlstore.New(db, storage.Timeouts{
+		Read:   cfg.DBReadTimeout,
+		Write:  cfg.DBWriteTimeout,
+		Upload: cfg.DBUploadTimeout,
+	}, Dialect), nil
+}
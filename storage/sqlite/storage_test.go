@@ -0,0 +1,24 @@
+package sqlite
+
+import (
+	"essy_travel/config"
+	"essy_travel/storage"
+	"essy_travel/storage/storagetest"
+	"path/filepath"
+	"testing"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.StorageI {
+		strg, err := NewConnectionSQLite(&config.Config{
+			SQLitePath:  filepath.Join(t.TempDir(), "essy_travel.db"),
+			AutoMigrate: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { strg.Close() })
+
+		return strg
+	})
+}
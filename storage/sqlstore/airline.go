@@ -0,0 +1,374 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"essy_travel/models"
+	"essy_travel/pkg/helpers"
+	"essy_travel/storage"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type AirlineRepo struct {
+	db       *sql.DB
+	timeouts storage.Timeouts
+	dialect  *Dialect
+}
+
+func NewAirlineRepo(db *sql.DB, timeouts storage.Timeouts, dialect *Dialect) *AirlineRepo {
+	return &AirlineRepo{
+		db:       db,
+		timeouts: timeouts,
+		dialect:  dialect,
+	}
+}
+
+func (a *AirlineRepo) Create(ctx context.Context, req models.CreateAirline) (*models.Airline, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Write)
+	defer cancel()
+
+	query := `
+		INSERT INTO airline(
+			"guid",
+			"iata_code",
+			"icao_code",
+			"title",
+			"country_id",
+			"active",
+			"updated_at"
+		) VALUES($1, $2, $3, $4, $5, $6, ` + a.dialect.Now + `)`
+
+	guid := uuid.New().String()
+	_, err := a.db.ExecContext(ctx, query,
+		guid,
+		req.IataCode,
+		req.IcaoCode,
+		req.Title,
+		helpers.NewNullString(req.CountryId),
+		req.Active,
+	)
+	if err != nil {
+		return &models.Airline{}, a.dialect.queryError(ctx, err)
+	}
+
+	return a.GetById(ctx, models.AirlinePrimaryKey{Guid: guid})
+}
+
+func (a *AirlineRepo) GetById(ctx context.Context, req models.AirlinePrimaryKey) (*models.Airline, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Read)
+	defer cancel()
+
+	query := `
+		SELECT
+			"guid",
+			"iata_code",
+			"icao_code",
+			"title",
+			"country_id",
+			"active",
+			"created_at",
+			"updated_at"
+		FROM airline
+		WHERE guid = $1
+	`
+
+	var (
+		Guid      sql.NullString
+		IataCode  sql.NullString
+		IcaoCode  sql.NullString
+		Title     sql.NullString
+		CountryId sql.NullString
+		Active    sql.NullBool
+		CreatedAt sql.NullString
+		UpdatedAt sql.NullString
+	)
+
+	err := a.db.QueryRowContext(ctx, query, req.Guid).Scan(
+		&Guid,
+		&IataCode,
+		&IcaoCode,
+		&Title,
+		&CountryId,
+		&Active,
+		&CreatedAt,
+		&UpdatedAt,
+	)
+	if err != nil {
+		return nil, a.dialect.queryError(ctx, err)
+	}
+
+	return &models.Airline{
+		Guid:      Guid.String,
+		IataCode:  IataCode.String,
+		IcaoCode:  IcaoCode.String,
+		Title:     Title.String,
+		CountryId: CountryId.String,
+		Active:    Active.Bool,
+		CreatedAt: CreatedAt.String,
+		UpdatedAt: UpdatedAt.String,
+	}, nil
+}
+
+func (a *AirlineRepo) GetList(ctx context.Context, req models.GetListAirlineRequest) (*models.GetListAirlineResponse, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Read)
+	defer cancel()
+
+	var (
+		resp   = models.GetListAirlineResponse{}
+		where  = " WHERE TRUE"
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		args   = []interface{}{}
+	)
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	if len(req.Search) > 0 {
+		args = append(args, "%"+req.Search+"%")
+		where += fmt.Sprintf(` AND ("title" %[1]s $%[2]d OR "iata_code" %[1]s $%[2]d OR "icao_code" %[1]s $%[2]d)`,
+			a.dialect.ILike, len(args))
+	}
+
+	if len(req.CountryId) > 0 {
+		args = append(args, req.CountryId)
+		where += fmt.Sprintf(` AND "country_id" = $%d`, len(args))
+	}
+
+	if req.OnlyActive {
+		where += ` AND "active"`
+	}
+
+	query := `
+		SELECT
+			COUNT(*) OVER(),
+			"guid",
+			"iata_code",
+			"icao_code",
+			"title",
+			"country_id",
+			"active",
+			"created_at",
+			"updated_at"
+		FROM airline
+	`
+	query += where + ` ORDER BY "title"` + limit + offset
+
+	rows, err := a.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, a.dialect.queryError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Guid      sql.NullString
+			IataCode  sql.NullString
+			IcaoCode  sql.NullString
+			Title     sql.NullString
+			CountryId sql.NullString
+			Active    sql.NullBool
+			CreatedAt sql.NullString
+			UpdatedAt sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Guid,
+			&IataCode,
+			&IcaoCode,
+			&Title,
+			&CountryId,
+			&Active,
+			&CreatedAt,
+			&UpdatedAt,
+		)
+		if err != nil {
+			return nil, a.dialect.queryError(ctx, err)
+		}
+
+		resp.Airlines = append(resp.Airlines, models.Airline{
+			Guid:      Guid.String,
+			IataCode:  IataCode.String,
+			IcaoCode:  IcaoCode.String,
+			Title:     Title.String,
+			CountryId: CountryId.String,
+			Active:    Active.Bool,
+			CreatedAt: CreatedAt.String,
+			UpdatedAt: UpdatedAt.String,
+		})
+	}
+
+	return &resp, nil
+}
+
+func (a *AirlineRepo) Update(ctx context.Context, req models.UpdateAirline) (*models.Airline, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Write)
+	defer cancel()
+
+	query := `
+		UPDATE airline SET
+			"iata_code" = $1,
+			"icao_code" = $2,
+			"title" = $3,
+			"country_id" = $4,
+			"active" = $5,
+			"updated_at" = ` + a.dialect.Now + `
+		WHERE "guid" = $6
+	`
+	_, err := a.db.ExecContext(ctx,
+		query,
+		req.IataCode,
+		req.IcaoCode,
+		req.Title,
+		helpers.NewNullString(req.CountryId),
+		req.Active,
+		req.Guid,
+	)
+	if err != nil {
+		return &models.Airline{}, a.dialect.queryError(ctx, err)
+	}
+
+	return a.GetById(ctx, models.AirlinePrimaryKey{Guid: req.Guid})
+}
+
+func (a *AirlineRepo) Delete(ctx context.Context, req models.AirlinePrimaryKey) (string, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Write)
+	defer cancel()
+
+	result, err := a.db.ExecContext(ctx, `DELETE FROM airline WHERE guid = $1`, req.Guid)
+
+	if err != nil {
+		return "Does not delete", a.dialect.queryError(ctx, err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return "Does not delete", storage.ErrNotFound
+	}
+
+	return "Deleted", nil
+}
+
+func (a *AirlineRepo) Upload(ctx context.Context, req []models.CreateAirline) error {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Upload)
+	defer cancel()
+
+	query := `
+		INSERT INTO airline(
+			"guid",
+			"iata_code",
+			"icao_code",
+			"title",
+			"country_id",
+			"active",
+			"updated_at") VALUES
+			($1, $2, $3, $4, $5, $6, ` + a.dialect.Now + `)
+	`
+	for _, v := range req {
+
+		guid := uuid.New().String()
+		_, err := a.db.ExecContext(ctx, query, guid, v.IataCode, v.IcaoCode, v.Title,
+			helpers.NewNullString(v.CountryId), v.Active)
+		if err != nil {
+			return a.dialect.queryError(ctx, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *AirlineRepo) AddAirport(ctx context.Context, req models.CreateAirlineAirport) error {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Write)
+	defer cancel()
+
+	query := `
+		INSERT INTO airline_airport(
+			"airline_id",
+			"airport_id",
+			"type"
+		) VALUES($1, $2, $3)
+		ON CONFLICT ("airline_id", "airport_id") DO UPDATE SET "type" = EXCLUDED."type"
+	`
+
+	_, err := a.db.ExecContext(ctx, query, req.AirlineId, req.AirportId, req.Type)
+
+	return a.dialect.queryError(ctx, err)
+}
+
+func (a *AirlineRepo) RemoveAirport(ctx context.Context, req models.AirlineAirportPrimaryKey) error {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Write)
+	defer cancel()
+
+	_, err := a.db.ExecContext(ctx,
+		`DELETE FROM airline_airport WHERE airline_id = $1 AND airport_id = $2`,
+		req.AirlineId,
+		req.AirportId,
+	)
+
+	return a.dialect.queryError(ctx, err)
+}
+
+func (a *AirlineRepo) GetAirports(ctx context.Context, req models.AirlinePrimaryKey) (*models.GetListAirlineAirportResponse, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Read)
+	defer cancel()
+
+	var resp = models.GetListAirlineAirportResponse{}
+
+	query := `
+		SELECT
+			aa."airline_id",
+			aa."airport_id",
+			aa."type",
+			ap."title",
+			aa."created_at"
+		FROM airline_airport AS aa
+		LEFT JOIN airport AS ap ON ap."guid" = aa."airport_id"
+		WHERE aa."airline_id" = $1
+		ORDER BY aa."type", ap."title"
+	`
+
+	rows, err := a.db.QueryContext(ctx, query, req.Guid)
+	if err != nil {
+		return nil, a.dialect.queryError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			AirlineId sql.NullString
+			AirportId sql.NullString
+			Type      sql.NullString
+			Airport   sql.NullString
+			CreatedAt sql.NullString
+		)
+
+		err = rows.Scan(
+			&AirlineId,
+			&AirportId,
+			&Type,
+			&Airport,
+			&CreatedAt,
+		)
+		if err != nil {
+			return nil, a.dialect.queryError(ctx, err)
+		}
+
+		resp.Airports = append(resp.Airports, models.AirlineAirport{
+			AirlineId: AirlineId.String,
+			AirportId: AirportId.String,
+			Type:      Type.String,
+			Airport:   Airport.String,
+			CreatedAt: CreatedAt.String,
+		})
+	}
+	resp.Count = len(resp.Airports)
+
+	return &resp, nil
+}
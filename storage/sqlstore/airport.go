@@ -0,0 +1,387 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"essy_travel/models"
+	"essy_travel/pkg/helpers"
+	"essy_travel/storage"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type AirportRepo struct {
+	db       *sql.DB
+	timeouts storage.Timeouts
+	dialect  *Dialect
+}
+
+func NewAirportRepo(db *sql.DB, timeouts storage.Timeouts, dialect *Dialect) *AirportRepo {
+	return &AirportRepo{
+		db:       db,
+		timeouts: timeouts,
+		dialect:  dialect,
+	}
+}
+
+func (a *AirportRepo) Create(ctx context.Context, req models.CreateAirport) (*models.Airport, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Write)
+	defer cancel()
+
+	query := `INSERT INTO airport(guid,title,country_id,city_id,latitude,longitude,radius,adress,timezone_id,country,city,code,product_count,gmt,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,` + a.dialect.Now + `)`
+	guid := uuid.New().String()
+	_, err := a.db.ExecContext(ctx, query,
+		guid,
+		req.Title,
+		helpers.NewNullString(req.CountryId),
+		helpers.NewNullString(req.CityId),
+		req.Latitude,
+		req.Longitude,
+		req.Radius,
+		req.Adress,
+		helpers.NewNullString(req.TimezoneId),
+		req.Country,
+		req.City,
+		req.Code,
+		req.ProductCount,
+		req.Gmt,
+	)
+	if err != nil {
+		return &models.Airport{}, a.dialect.queryError(ctx, err)
+	}
+	return a.GetById(ctx, models.AirportPrimaryKey{Guid: guid})
+}
+
+func (a *AirportRepo) GetById(ctx context.Context, req models.AirportPrimaryKey) (*models.Airport, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Read)
+	defer cancel()
+
+	query := `
+		SELECT
+			"guid",
+			"title",
+			"country_id",
+			"city_id",
+			"latitude",
+			"longitude",
+			"radius",
+			"image",
+			"adress",
+			"timezone_id",
+			"country",
+			"city",
+			"code",
+			"product_count",
+			"gmt",
+			"created_at",
+			"updated_at",
+			"version"
+		FROM airport
+		WHERE guid = $1
+	`
+
+	var (
+		Guid         sql.NullString
+		Title        sql.NullString
+		CountryId    sql.NullString
+		CityId       sql.NullString
+		Latitude     sql.NullFloat64
+		Longitude    sql.NullFloat64
+		Radius       sql.NullFloat64
+		Image        sql.NullString
+		Adress       sql.NullString
+		TimezoneId   sql.NullString
+		Country      sql.NullString
+		City         sql.NullString
+		Code         sql.NullString
+		ProductCount sql.NullInt64
+		Gmt          sql.NullString
+		CreatedAt    sql.NullString
+		UpdatedAt    sql.NullString
+		Version      sql.NullInt64
+	)
+
+	err := a.db.QueryRowContext(ctx, query, req.Guid).Scan(
+		&Guid,
+		&Title,
+		&CountryId,
+		&CityId,
+		&Latitude,
+		&Longitude,
+		&Radius,
+		&Image,
+		&Adress,
+		&TimezoneId,
+		&Country,
+		&City,
+		&Code,
+		&ProductCount,
+		&Gmt,
+		&CreatedAt,
+		&UpdatedAt,
+		&Version,
+	)
+	if err != nil {
+		return nil, a.dialect.queryError(ctx, err)
+	}
+
+	return &models.Airport{
+		Guid:         Guid.String,
+		Title:        Title.String,
+		CountryId:    CountryId.String,
+		CityId:       CityId.String,
+		Latitude:     Latitude.Float64,
+		Longitude:    Longitude.Float64,
+		Radius:       Radius.Float64,
+		Image:        Image.String,
+		Adress:       Adress.String,
+		TimezoneId:   TimezoneId.String,
+		Country:      Country.String,
+		City:         City.String,
+		Code:         Code.String,
+		ProductCount: int(ProductCount.Int64),
+		Gmt:          Gmt.String,
+		CreatedAt:    CreatedAt.String,
+		UpdatedAt:    UpdatedAt.String,
+		Version:      int(Version.Int64),
+	}, nil
+}
+
+func (a *AirportRepo) GetList(ctx context.Context, req models.GetListAirportRequest) (*models.GetListAirportResponse, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Read)
+	defer cancel()
+
+	var (
+		resp   = models.GetListAirportResponse{}
+		where  = " WHERE TRUE"
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		args   = []interface{}{}
+	)
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	if len(req.Search) > 0 {
+		args = append(args, "%"+req.Search+"%")
+		where += ` AND ("title" ` + a.dialect.ILike + ` $1 OR "code" ` + a.dialect.ILike + ` $1 OR EXISTS (
+			SELECT 1 FROM alias
+			WHERE alias."entity" = 'airport' AND alias."entity_id" = airport."guid" AND alias."title" ` + a.dialect.ILike + ` $1
+		))`
+	}
+
+	query := `
+		SELECT
+			COUNT(*) OVER(),
+			"guid",
+			"title",
+			"country_id",
+			"city_id",
+			"latitude",
+			"longitude",
+			"radius",
+			"image",
+			"adress",
+			"timezone_id",
+			"country",
+			"city",
+			"code",
+			"product_count",
+			"gmt",
+			"created_at",
+			"updated_at",
+			"version"
+		FROM airport
+	`
+	query += where + limit + offset
+
+	rows, err := a.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, a.dialect.queryError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Guid         sql.NullString
+			Title        sql.NullString
+			CountryId    sql.NullString
+			CityId       sql.NullString
+			Latitude     sql.NullFloat64
+			Longitude    sql.NullFloat64
+			Radius       sql.NullFloat64
+			Image        sql.NullString
+			Adress       sql.NullString
+			TimezoneId   sql.NullString
+			Country      sql.NullString
+			City         sql.NullString
+			Code         sql.NullString
+			ProductCount sql.NullInt64
+			Gmt          sql.NullString
+			CreatedAt    sql.NullString
+			UpdatedAt    sql.NullString
+			Version      sql.NullInt64
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Guid,
+			&Title,
+			&CountryId,
+			&CityId,
+			&Latitude,
+			&Longitude,
+			&Radius,
+			&Image,
+			&Adress,
+			&TimezoneId,
+			&Country,
+			&City,
+			&Code,
+			&ProductCount,
+			&Gmt,
+			&CreatedAt,
+			&UpdatedAt,
+			&Version,
+		)
+		if err != nil {
+			return nil, a.dialect.queryError(ctx, err)
+		}
+
+		resp.Airports = append(resp.Airports, models.Airport{
+			Guid:         Guid.String,
+			Title:        Title.String,
+			CountryId:    CountryId.String,
+			CityId:       CityId.String,
+			Latitude:     Latitude.Float64,
+			Longitude:    Longitude.Float64,
+			Radius:       Radius.Float64,
+			Image:        Image.String,
+			Adress:       Adress.String,
+			TimezoneId:   TimezoneId.String,
+			Country:      Country.String,
+			City:         City.String,
+			Code:         Code.String,
+			ProductCount: int(ProductCount.Int64),
+			Gmt:          Gmt.String,
+			CreatedAt:    CreatedAt.String,
+			UpdatedAt:    UpdatedAt.String,
+			Version:      int(Version.Int64),
+		})
+	}
+
+	return &resp, nil
+}
+
+func (a *AirportRepo) Update(ctx context.Context, req models.UpdateAirport) (*models.Airport, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Write)
+	defer cancel()
+
+	query := `UPDATE airport SET title=$1,country_id=$2,city_id=$3,latitude=$4,longitude=$5,radius=$6,adress=$7,timezone_id=$8,country=$9,city=$10,code=$11,product_count=$12,gmt=$13,updated_at = ` + a.dialect.Now + `,version = version + 1 WHERE guid = $14 AND ($15 = 0 OR version = $15)`
+	result, err := a.db.ExecContext(ctx,
+		query,
+		req.Title,
+		helpers.NewNullString(req.CountryId),
+		helpers.NewNullString(req.CityId),
+		req.Latitude,
+		req.Longitude,
+		req.Radius,
+		req.Adress,
+		helpers.NewNullString(req.TimezoneId),
+		req.Country,
+		req.City,
+		req.Code,
+		req.ProductCount,
+		req.Gmt,
+		req.Guid,
+		req.Version,
+	)
+	if err != nil {
+		return &models.Airport{}, a.dialect.queryError(ctx, err)
+	}
+
+	resp, err := a.GetById(ctx, models.AirportPrimaryKey{Guid: req.Guid})
+	if rows, _ := result.RowsAffected(); err == nil && rows == 0 {
+		return resp, storage.ErrConflict
+	}
+
+	return resp, err
+}
+
+func (a *AirportRepo) UpdateImage(ctx context.Context, req models.UpdateAirportImage) (*models.Airport, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Write)
+	defer cancel()
+
+	result, err := a.db.ExecContext(ctx,
+		`UPDATE airport SET "image" = $1, "updated_at" = `+a.dialect.Now+`, "version" = "version" + 1 WHERE "guid" = $2 AND COALESCE("image", '') = $3`,
+		req.Image,
+		req.Guid,
+		req.OldImage,
+	)
+	if err != nil {
+		return &models.Airport{}, a.dialect.queryError(ctx, err)
+	}
+
+	resp, err := a.GetById(ctx, models.AirportPrimaryKey{Guid: req.Guid})
+	if rows, _ := result.RowsAffected(); err == nil && rows == 0 {
+		return resp, storage.ErrConflict
+	}
+
+	return resp, err
+}
+
+func (a *AirportRepo) Delete(ctx context.Context, req models.AirportPrimaryKey) (string, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Write)
+	defer cancel()
+
+	err := deleteEntity(ctx, a.db, a.dialect, "airport", req.Guid)
+	if err != nil {
+		return "Does not delete", err
+	}
+
+	return "Deleted", nil
+}
+
+func (c *AirportRepo) Upload(ctx context.Context, req []models.CreateAirport) error {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Upload)
+	defer cancel()
+
+	query := `
+		INSERT INTO airport(
+			"guid",
+			"title",
+			"country_id",
+			"city_id",
+			"latitude",
+			"longitude",
+			"radius",
+			"adress",
+			"timezone_id",
+			"country",
+			"city",
+			"code",
+			"product_count",
+			"gmt",
+			"updated_at") VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, ` + c.dialect.Now + `)
+	`
+	for _, v := range req {
+		guid := uuid.New().String()
+
+		_, err := c.db.ExecContext(ctx, query, guid, v.Title,
+			helpers.NewNullString(v.CountryId), helpers.NewNullString(v.CityId), v.Latitude, v.Longitude,
+			v.Radius, v.Adress, helpers.NewNullString(v.TimezoneId), v.Country, v.City, v.Code,
+			v.ProductCount, v.Gmt)
+		if err != nil {
+			return c.dialect.queryError(ctx, err)
+		}
+	}
+
+	return nil
+}
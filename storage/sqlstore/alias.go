@@ -0,0 +1,192 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"essy_travel/models"
+	"essy_travel/storage"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type AliasRepo struct {
+	db       *sql.DB
+	timeouts storage.Timeouts
+	dialect  *Dialect
+}
+
+func NewAliasRepo(db *sql.DB, timeouts storage.Timeouts, dialect *Dialect) *AliasRepo {
+	return &AliasRepo{
+		db:       db,
+		timeouts: timeouts,
+		dialect:  dialect,
+	}
+}
+
+func (a *AliasRepo) Create(ctx context.Context, req models.CreateAlias) (*models.Alias, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Write)
+	defer cancel()
+
+	query := `
+		INSERT INTO alias(
+			"guid",
+			"entity",
+			"entity_id",
+			"title"
+		) VALUES($1, $2, $3, $4)`
+
+	guid := uuid.New().String()
+	_, err := a.db.ExecContext(ctx, query,
+		guid,
+		req.Entity,
+		req.EntityId,
+		req.Title,
+	)
+	if err != nil {
+		return &models.Alias{}, a.dialect.queryError(ctx, err)
+	}
+
+	return a.GetById(ctx, models.AliasPrimaryKey{Guid: guid})
+}
+
+func (a *AliasRepo) GetById(ctx context.Context, req models.AliasPrimaryKey) (*models.Alias, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Read)
+	defer cancel()
+
+	query := `
+		SELECT
+			"guid",
+			"entity",
+			"entity_id",
+			"title",
+			"created_at"
+		FROM alias
+		WHERE guid = $1
+	`
+
+	var (
+		Guid      sql.NullString
+		Entity    sql.NullString
+		EntityId  sql.NullString
+		Title     sql.NullString
+		CreatedAt sql.NullString
+	)
+
+	err := a.db.QueryRowContext(ctx, query, req.Guid).Scan(
+		&Guid,
+		&Entity,
+		&EntityId,
+		&Title,
+		&CreatedAt,
+	)
+	if err != nil {
+		return nil, a.dialect.queryError(ctx, err)
+	}
+
+	return &models.Alias{
+		Guid:      Guid.String,
+		Entity:    Entity.String,
+		EntityId:  EntityId.String,
+		Title:     Title.String,
+		CreatedAt: CreatedAt.String,
+	}, nil
+}
+
+func (a *AliasRepo) GetList(ctx context.Context, req models.GetListAliasRequest) (*models.GetListAliasResponse, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Read)
+	defer cancel()
+
+	var (
+		resp   = models.GetListAliasResponse{}
+		where  = " WHERE TRUE"
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		args   = []interface{}{}
+	)
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	if len(req.Entity) > 0 {
+		args = append(args, req.Entity)
+		where += fmt.Sprintf(` AND "entity" = $%d`, len(args))
+	}
+
+	if len(req.EntityId) > 0 {
+		args = append(args, req.EntityId)
+		where += fmt.Sprintf(` AND "entity_id" = $%d`, len(args))
+	}
+
+	query := `
+		SELECT
+			COUNT(*) OVER(),
+			"guid",
+			"entity",
+			"entity_id",
+			"title",
+			"created_at"
+		FROM alias
+	`
+	query += where + ` ORDER BY "title"` + limit + offset
+
+	rows, err := a.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, a.dialect.queryError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Guid      sql.NullString
+			Entity    sql.NullString
+			EntityId  sql.NullString
+			Title     sql.NullString
+			CreatedAt sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Guid,
+			&Entity,
+			&EntityId,
+			&Title,
+			&CreatedAt,
+		)
+		if err != nil {
+			return nil, a.dialect.queryError(ctx, err)
+		}
+
+		resp.Aliases = append(resp.Aliases, models.Alias{
+			Guid:      Guid.String,
+			Entity:    Entity.String,
+			EntityId:  EntityId.String,
+			Title:     Title.String,
+			CreatedAt: CreatedAt.String,
+		})
+	}
+
+	return &resp, nil
+}
+
+func (a *AliasRepo) Delete(ctx context.Context, req models.AliasPrimaryKey) (string, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Write)
+	defer cancel()
+
+	result, err := a.db.ExecContext(ctx, `DELETE FROM alias WHERE guid = $1`, req.Guid)
+
+	if err != nil {
+		return "Does not delete", a.dialect.queryError(ctx, err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return "Does not delete", storage.ErrNotFound
+	}
+
+	return "Deleted", nil
+}
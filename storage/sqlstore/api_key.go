@@ -0,0 +1,204 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"essy_travel/models"
+	"essy_travel/storage"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type ApiKeyRepo struct {
+	db       *sql.DB
+	timeouts storage.Timeouts
+	dialect  *Dialect
+}
+
+func NewApiKeyRepo(db *sql.DB, timeouts storage.Timeouts, dialect *Dialect) *ApiKeyRepo {
+	return &ApiKeyRepo{
+		db:       db,
+		timeouts: timeouts,
+		dialect:  dialect,
+	}
+}
+
+func (a *ApiKeyRepo) Create(ctx context.Context, req models.CreateApiKey) (*models.ApiKey, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Write)
+	defer cancel()
+
+	query := `
+		INSERT INTO api_key(
+			"guid",
+			"name",
+			"role",
+			"prefix",
+			"hash"
+		) VALUES($1, $2, $3, $4, $5)`
+
+	guid := uuid.New().String()
+	_, err := a.db.ExecContext(ctx, query,
+		guid,
+		req.Name,
+		req.Role,
+		req.Prefix,
+		req.Hash,
+	)
+	if err != nil {
+		return &models.ApiKey{}, a.dialect.queryError(ctx, err)
+	}
+
+	return a.GetById(ctx, models.ApiKeyPrimaryKey{Guid: guid})
+}
+
+func (a *ApiKeyRepo) GetById(ctx context.Context, req models.ApiKeyPrimaryKey) (*models.ApiKey, error) {
+	return a.get(ctx, `"guid" = $1`, req.Guid)
+}
+
+// GetByHash finds the key a client sent by its hash, revoked or not.
+func (a *ApiKeyRepo) GetByHash(ctx context.Context, req models.ApiKeyHash) (*models.ApiKey, error) {
+	return a.get(ctx, `"hash" = $1`, req.Hash)
+}
+
+func (a *ApiKeyRepo) get(ctx context.Context, where string, arg interface{}) (*models.ApiKey, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Read)
+	defer cancel()
+
+	query := `
+		SELECT
+			"guid",
+			"name",
+			"role",
+			"prefix",
+			"created_at",
+			"revoked_at"
+		FROM api_key
+		WHERE ` + where
+
+	var (
+		Guid      sql.NullString
+		Name      sql.NullString
+		Role      sql.NullString
+		Prefix    sql.NullString
+		CreatedAt sql.NullString
+		RevokedAt sql.NullString
+	)
+
+	err := a.db.QueryRowContext(ctx, query, arg).Scan(
+		&Guid,
+		&Name,
+		&Role,
+		&Prefix,
+		&CreatedAt,
+		&RevokedAt,
+	)
+	if err != nil {
+		return nil, a.dialect.queryError(ctx, err)
+	}
+
+	return &models.ApiKey{
+		Guid:      Guid.String,
+		Name:      Name.String,
+		Role:      Role.String,
+		Prefix:    Prefix.String,
+		CreatedAt: CreatedAt.String,
+		RevokedAt: RevokedAt.String,
+	}, nil
+}
+
+func (a *ApiKeyRepo) GetList(ctx context.Context, req models.GetListApiKeyRequest) (*models.GetListApiKeyResponse, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Read)
+	defer cancel()
+
+	var (
+		resp   = models.GetListApiKeyResponse{}
+		where  = " WHERE TRUE"
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+	)
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	if !req.IncludeRevoked {
+		where += ` AND "revoked_at" IS NULL`
+	}
+
+	query := `
+		SELECT
+			COUNT(*) OVER(),
+			"guid",
+			"name",
+			"role",
+			"prefix",
+			"created_at",
+			"revoked_at"
+		FROM api_key
+	`
+	query += where + ` ORDER BY "created_at", "guid"` + limit + offset
+
+	rows, err := a.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, a.dialect.queryError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Guid      sql.NullString
+			Name      sql.NullString
+			Role      sql.NullString
+			Prefix    sql.NullString
+			CreatedAt sql.NullString
+			RevokedAt sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Guid,
+			&Name,
+			&Role,
+			&Prefix,
+			&CreatedAt,
+			&RevokedAt,
+		)
+		if err != nil {
+			return nil, a.dialect.queryError(ctx, err)
+		}
+
+		resp.ApiKeys = append(resp.ApiKeys, models.ApiKey{
+			Guid:      Guid.String,
+			Name:      Name.String,
+			Role:      Role.String,
+			Prefix:    Prefix.String,
+			CreatedAt: CreatedAt.String,
+			RevokedAt: RevokedAt.String,
+		})
+	}
+
+	return &resp, nil
+}
+
+// Revoke disables a key for good. Revoking a revoked key keeps the time it
+// was first revoked.
+func (a *ApiKeyRepo) Revoke(ctx context.Context, req models.ApiKeyPrimaryKey) (*models.ApiKey, error) {
+	ctx, cancel := withTimeout(ctx, a.timeouts.Write)
+	defer cancel()
+
+	result, err := a.db.ExecContext(ctx, `UPDATE api_key SET "revoked_at" = COALESCE("revoked_at", `+a.dialect.Now+`) WHERE "guid" = $1`, req.Guid)
+	if err != nil {
+		return nil, a.dialect.queryError(ctx, err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, storage.ErrNotFound
+	}
+
+	return a.GetById(ctx, req)
+}
@@ -1,25 +1,33 @@
-package postgres
+package sqlstore
 
 import (
+	"context"
 	"database/sql"
 	"essy_travel/models"
 	"essy_travel/pkg/helpers"
+	"essy_travel/storage"
 	"fmt"
 
 	"github.com/google/uuid"
 )
 
 type CityRepo struct {
-	db *sql.DB
+	db       *sql.DB
+	timeouts storage.Timeouts
+	dialect  *Dialect
 }
 
-func NewCityRepo(db *sql.DB) *CityRepo {
+func NewCityRepo(db *sql.DB, timeouts storage.Timeouts, dialect *Dialect) *CityRepo {
 	return &CityRepo{
-		db: db,
+		db:       db,
+		timeouts: timeouts,
+		dialect:  dialect,
 	}
 }
 
-func (c *CityRepo) Create(req models.CreateCity) (*models.City, error) {
+func (c *CityRepo) Create(ctx context.Context, req models.CreateCity) (*models.City, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Write)
+	defer cancel()
 
 	query := `
 		INSERT INTO city(
@@ -33,10 +41,10 @@ func (c *CityRepo) Create(req models.CreateCity) (*models.City, error) {
 			"timezone_id",
 			"country_name",
 			"updated_at"
-		) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())`
+		) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, ` + c.dialect.Now + `)`
 
 	id := uuid.New().String()
-	_, err := c.db.Exec(query,
+	_, err := c.db.ExecContext(ctx, query,
 		id,
 		req.Title,
 		helpers.NewNullString(req.CountryId),
@@ -48,13 +56,16 @@ func (c *CityRepo) Create(req models.CreateCity) (*models.City, error) {
 		req.CountryName,
 	)
 	if err != nil {
-		return &models.City{}, err
+		return &models.City{}, c.dialect.queryError(ctx, err)
 	}
 
-	return c.GetById(models.CityPrimaryKey{Guid: id})
+	return c.GetById(ctx, models.CityPrimaryKey{Guid: id})
 }
 
-func (c *CityRepo) GetById(req models.CityPrimaryKey) (*models.City, error) {
+func (c *CityRepo) GetById(ctx context.Context, req models.CityPrimaryKey) (*models.City, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Read)
+	defer cancel()
+
 	query := `
 		SELECT
 			"guid",
@@ -67,7 +78,8 @@ func (c *CityRepo) GetById(req models.CityPrimaryKey) (*models.City, error) {
 			"timezone_id",
 			"country_name",
 			"created_at",
-			"updated_at"
+			"updated_at",
+			"version"
 		FROM city
 		WHERE guid = $1
 	`
@@ -83,8 +95,9 @@ func (c *CityRepo) GetById(req models.CityPrimaryKey) (*models.City, error) {
 		CountryName sql.NullString
 		CreatedAt   sql.NullString
 		UpdatedAt   sql.NullString
+		Version     sql.NullInt64
 	)
-	err := c.db.QueryRow(query, req.Guid).Scan(
+	err := c.db.QueryRowContext(ctx, query, req.Guid).Scan(
 		&req.Guid,
 		&Title,
 		&CountryId,
@@ -96,9 +109,10 @@ func (c *CityRepo) GetById(req models.CityPrimaryKey) (*models.City, error) {
 		&CountryName,
 		&CreatedAt,
 		&UpdatedAt,
+		&Version,
 	)
 	if err != nil {
-		return nil, err
+		return nil, c.dialect.queryError(ctx, err)
 	}
 
 	return &models.City{
@@ -113,15 +127,20 @@ func (c *CityRepo) GetById(req models.CityPrimaryKey) (*models.City, error) {
 		CountryName: CountryName.String,
 		CreatedAt:   CreatedAt.String,
 		UpdatedAt:   UpdatedAt.String,
+		Version:     int(Version.Int64),
 	}, nil
 }
 
-func (c *CityRepo) GetList(req models.GetListCityRequest) (*models.GetListCityResponse, error) {
+func (c *CityRepo) GetList(ctx context.Context, req models.GetListCityRequest) (*models.GetListCityResponse, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Read)
+	defer cancel()
+
 	var (
 		resp   = models.GetListCityResponse{}
 		where  = " WHERE TRUE"
 		offset = " OFFSET 0"
 		limit  = " LIMIT 10"
+		args   = []interface{}{}
 	)
 
 	if req.Offset > 0 {
@@ -132,6 +151,14 @@ func (c *CityRepo) GetList(req models.GetListCityRequest) (*models.GetListCityRe
 		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
 	}
 
+	if len(req.Search) > 0 {
+		args = append(args, "%"+req.Search+"%")
+		where += ` AND ("title" ` + c.dialect.ILike + ` $1 OR "city_code" ` + c.dialect.ILike + ` $1 OR EXISTS (
+			SELECT 1 FROM alias
+			WHERE alias."entity" = 'city' AND alias."entity_id" = city."guid" AND alias."title" ` + c.dialect.ILike + ` $1
+		))`
+	}
+
 	query := `
 		SELECT
 			COUNT(*) OVER(),
@@ -145,14 +172,15 @@ func (c *CityRepo) GetList(req models.GetListCityRequest) (*models.GetListCityRe
 			"timezone_id",
 			"country_name",
 			"created_at",
-			"updated_at"
+			"updated_at",
+			"version"
 		FROM city
 	`
 	query += where + limit + offset
 
-	rows, err := c.db.Query(query)
+	rows, err := c.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, c.dialect.queryError(ctx, err)
 	}
 	defer rows.Close()
 
@@ -169,6 +197,7 @@ func (c *CityRepo) GetList(req models.GetListCityRequest) (*models.GetListCityRe
 			CountryName sql.NullString
 			CreatedAt   sql.NullString
 			UpdatedAt   sql.NullString
+			Version     sql.NullInt64
 		)
 
 		err = rows.Scan(
@@ -184,9 +213,10 @@ func (c *CityRepo) GetList(req models.GetListCityRequest) (*models.GetListCityRe
 			&CountryName,
 			&CreatedAt,
 			&UpdatedAt,
+			&Version,
 		)
 		if err != nil {
-			return nil, err
+			return nil, c.dialect.queryError(ctx, err)
 		}
 
 		resp.Cities = append(resp.Cities, models.City{
@@ -201,13 +231,17 @@ func (c *CityRepo) GetList(req models.GetListCityRequest) (*models.GetListCityRe
 			CountryName: CountryName.String,
 			CreatedAt:   CreatedAt.String,
 			UpdatedAt:   UpdatedAt.String,
+			Version:     int(Version.Int64),
 		})
 	}
 
 	return &resp, nil
 }
 
-func (c *CityRepo) Update(req models.UpdateCity) (*models.City, error) {
+func (c *CityRepo) Update(ctx context.Context, req models.UpdateCity) (*models.City, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Write)
+	defer cancel()
+
 	query := `
 		UPDATE city SET 
 			"title" = $1,
@@ -217,32 +251,41 @@ func (c *CityRepo) Update(req models.UpdateCity) (*models.City, error) {
 			"longitude" = $5,
 			"offset" = $6,
 			"timezone_id" = $7,
-			"country_name" = $8
-		WHERE "guid" = $9
+			"country_name" = $8,
+			"updated_at" = ` + c.dialect.Now + `,
+			"version" = "version" + 1
+		WHERE "guid" = $9 AND ($10 = 0 OR "version" = $10)
 	`
-	_, err := c.db.Exec(
+	result, err := c.db.ExecContext(ctx,
 		query,
 		req.Title,
-		req.CountryId,
+		helpers.NewNullString(req.CountryId),
 		req.CityCode,
 		req.Latitude,
 		req.Longitude,
 		req.Offset,
-		req.TimezoneId,
+		helpers.NewNullString(req.TimezoneId),
 		req.CountryName,
 		req.Guid,
+		req.Version,
 	)
 	if err != nil {
-		return &models.City{}, err
+		return &models.City{}, c.dialect.queryError(ctx, err)
 	}
 
-	return c.GetById(models.CityPrimaryKey{Guid: req.Guid})
-}
+	resp, err := c.GetById(ctx, models.CityPrimaryKey{Guid: req.Guid})
+	if rows, _ := result.RowsAffected(); err == nil && rows == 0 {
+		return resp, storage.ErrConflict
+	}
 
-func (c *CityRepo) Delete(req models.CityPrimaryKey) (string, error) {
+	return resp, err
+}
 
-	_, err := c.db.Exec(`DELETE FROM city WHERE guid = $1`, req.Guid)
+func (c *CityRepo) Delete(ctx context.Context, req models.CityPrimaryKey) (string, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Write)
+	defer cancel()
 
+	err := deleteEntity(ctx, c.db, c.dialect, "city", req.Guid)
 	if err != nil {
 		return "Does not delete", err
 	}
@@ -250,7 +293,10 @@ func (c *CityRepo) Delete(req models.CityPrimaryKey) (string, error) {
 	return "Deleted", nil
 }
 
-func (c *CityRepo) Upload(req []models.CreateCity) error {
+func (c *CityRepo) Upload(ctx context.Context, req []models.CreateCity) error {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Upload)
+	defer cancel()
+
 	query := `
 		INSERT INTO city(
 			"guid",
@@ -263,15 +309,15 @@ func (c *CityRepo) Upload(req []models.CreateCity) error {
 			"timezone_id",
 			"country_name",
 			"updated_at") VALUES
-			($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, ` + c.dialect.Now + `)
 	`
 	for _, v := range req {
-		
+
 		guid := uuid.New().String()
-		_, err := c.db.Exec(query, guid, v.Title, v.CountryId, v.CityCode, v.Latitude,
-			v.Longitude, v.Offset, v.TimezoneId, v.CountryName)
+		_, err := c.db.ExecContext(ctx, query, guid, v.Title, helpers.NewNullString(v.CountryId), v.CityCode, v.Latitude,
+			v.Longitude, v.Offset, helpers.NewNullString(v.TimezoneId), v.CountryName)
 		if err != nil {
-			return err
+			return c.dialect.queryError(ctx, err)
 		}
 	}
 
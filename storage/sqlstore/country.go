@@ -0,0 +1,255 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"essy_travel/models"
+	"essy_travel/pkg/helpers"
+	"essy_travel/storage"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type CountryRepo struct {
+	db       *sql.DB
+	timeouts storage.Timeouts
+	dialect  *Dialect
+}
+
+func NewCountryRepo(db *sql.DB, timeouts storage.Timeouts, dialect *Dialect) *CountryRepo {
+	return &CountryRepo{
+		db:       db,
+		timeouts: timeouts,
+		dialect:  dialect,
+	}
+}
+
+func (c *CountryRepo) Create(ctx context.Context, req models.CreateCountry) (*models.Country, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Write)
+	defer cancel()
+
+	query := `
+	INSERT INTO country(
+		guid,
+		title,
+		code,
+		continent,
+		calling_code,
+		updated_at)
+		VALUES ($1,$2,$3,$4,$5,` + c.dialect.Now + `)`
+	guid := uuid.New().String()
+	_, err := c.db.ExecContext(ctx, query, guid, req.Title, req.Code, req.Continent, helpers.NewNullString(req.CallingCode))
+	if err != nil {
+		return &models.Country{}, c.dialect.queryError(ctx, err)
+	}
+	return c.GetById(ctx, models.CountryPrimaryKey{Guid: guid})
+}
+
+func (c *CountryRepo) GetById(ctx context.Context, req models.CountryPrimaryKey) (*models.Country, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Read)
+	defer cancel()
+
+	query := `
+		SELECT
+			"guid",
+			"title",
+			"code",
+			"continent",
+			"calling_code",
+			"created_at",
+			"updated_at",
+			"version"
+		FROM country
+		WHERE guid = $1
+	`
+
+	var (
+		Guid        sql.NullString
+		Title       sql.NullString
+		Code        sql.NullString
+		Continent   sql.NullString
+		CallingCode sql.NullString
+		CreatedAt   sql.NullString
+		UpdatedAt   sql.NullString
+		Version     sql.NullInt64
+	)
+
+	err := c.db.QueryRowContext(ctx, query, req.Guid).Scan(
+		&Guid,
+		&Title,
+		&Code,
+		&Continent,
+		&CallingCode,
+		&CreatedAt,
+		&UpdatedAt,
+		&Version,
+	)
+	if err != nil {
+		return nil, c.dialect.queryError(ctx, err)
+	}
+
+	return &models.Country{
+		Guid:        Guid.String,
+		Title:       Title.String,
+		Code:        Code.String,
+		Continent:   Continent.String,
+		CallingCode: CallingCode.String,
+		CreatedAt:   CreatedAt.String,
+		UpdatedAt:   UpdatedAt.String,
+		Version:     int(Version.Int64),
+	}, nil
+}
+
+func (c *CountryRepo) GetList(ctx context.Context, req models.GetListCountryRequest) (*models.GetListCountryResponse, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Read)
+	defer cancel()
+
+	var (
+		resp   = models.GetListCountryResponse{}
+		where  = " WHERE TRUE"
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		args   = []interface{}{}
+	)
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	if len(req.CallingCode) > 0 {
+		args = append(args, req.CallingCode)
+		where += ` AND "calling_code" = $1`
+	}
+
+	query := `
+		SELECT
+			COUNT(*) OVER(),
+			"guid",
+			"title",
+			"code",
+			"continent",
+			"calling_code",
+			"created_at",
+			"updated_at",
+			"version"
+		FROM country
+	`
+	query += where + limit + offset
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, c.dialect.queryError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Guid        sql.NullString
+			Title       sql.NullString
+			Code        sql.NullString
+			Continent   sql.NullString
+			CallingCode sql.NullString
+			CreatedAt   sql.NullString
+			UpdatedAt   sql.NullString
+			Version     sql.NullInt64
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Guid,
+			&Title,
+			&Code,
+			&Continent,
+			&CallingCode,
+			&CreatedAt,
+			&UpdatedAt,
+			&Version,
+		)
+		if err != nil {
+			return nil, c.dialect.queryError(ctx, err)
+		}
+
+		resp.Countries = append(resp.Countries, models.Country{
+			Guid:        Guid.String,
+			Title:       Title.String,
+			Code:        Code.String,
+			Continent:   Continent.String,
+			CallingCode: CallingCode.String,
+			CreatedAt:   CreatedAt.String,
+			UpdatedAt:   UpdatedAt.String,
+			Version:     int(Version.Int64),
+		})
+	}
+
+	return &resp, nil
+}
+
+func (c *CountryRepo) Update(ctx context.Context, req models.UpdateCountry) (*models.Country, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Write)
+	defer cancel()
+
+	query := `
+		UPDATE country SET 
+			"title" = $1,
+			"code" = $2,
+			"continent" = $3,
+			"calling_code" = $4,
+			"updated_at" = ` + c.dialect.Now + `,
+			"version" = "version" + 1
+		WHERE 
+			guid = $5 AND ($6 = 0 OR "version" = $6)`
+	result, err := c.db.ExecContext(ctx, query, req.Title, req.Code, req.Continent, helpers.NewNullString(req.CallingCode), req.Guid, req.Version)
+	if err != nil {
+		return &models.Country{}, c.dialect.queryError(ctx, err)
+	}
+
+	resp, err := c.GetById(ctx, models.CountryPrimaryKey{Guid: req.Guid})
+	if rows, _ := result.RowsAffected(); err == nil && rows == 0 {
+		return resp, storage.ErrConflict
+	}
+
+	return resp, err
+}
+
+func (c *CountryRepo) Delete(ctx context.Context, req models.CountryPrimaryKey) (string, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Write)
+	defer cancel()
+
+	err := deleteEntity(ctx, c.db, c.dialect, "country", req.Guid)
+	if err != nil {
+		return "Does not delete", err
+	}
+
+	return "Deleted", nil
+}
+
+func (c *CountryRepo) Upload(ctx context.Context, req []models.CreateCountry) error {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Upload)
+	defer cancel()
+
+	query := `
+		INSERT INTO country(
+			"guid",
+			"title",
+			"code",
+			"continent",
+			"calling_code",
+			"updated_at") VALUES
+			($1, $2, $3, $4, $5, ` + c.dialect.Now + `)
+	`
+	for _, v := range req {
+
+		guid := uuid.New().String()
+		_, err := c.db.ExecContext(ctx, query, guid, v.Title, v.Code, v.Continent, helpers.NewNullString(v.CallingCode))
+		if err != nil {
+			return c.dialect.queryError(ctx, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,430 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"essy_travel/models"
+	"essy_travel/storage"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type RouteRepo struct {
+	db       *sql.DB
+	timeouts storage.Timeouts
+	dialect  *Dialect
+}
+
+func NewRouteRepo(db *sql.DB, timeouts storage.Timeouts, dialect *Dialect) *RouteRepo {
+	return &RouteRepo{
+		db:       db,
+		timeouts: timeouts,
+		dialect:  dialect,
+	}
+}
+
+func (r *RouteRepo) Create(ctx context.Context, req models.CreateRoute) (*models.Route, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	guid := uuid.New().String()
+	query, args := r.insert(guid, req)
+	_, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return &models.Route{}, r.dialect.queryError(ctx, err)
+	}
+
+	return r.GetById(ctx, models.RoutePrimaryKey{Guid: guid})
+}
+
+func (r *RouteRepo) GetById(ctx context.Context, req models.RoutePrimaryKey) (*models.Route, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	query := `
+		SELECT
+			r."guid",
+			r."origin_airport_id",
+			r."destination_airport_id",
+			r."carrier_code",
+			r."distance",
+			r."active",
+			o."title",
+			d."title",
+			r."created_at",
+			r."updated_at"
+		FROM route AS r
+		LEFT JOIN airport AS o ON o."guid" = r."origin_airport_id"
+		LEFT JOIN airport AS d ON d."guid" = r."destination_airport_id"
+		WHERE r."guid" = $1
+	`
+
+	var (
+		Guid                 sql.NullString
+		OriginAirportId      sql.NullString
+		DestinationAirportId sql.NullString
+		CarrierCode          sql.NullString
+		Distance             sql.NullFloat64
+		Active               sql.NullBool
+		OriginAirport        sql.NullString
+		DestinationAirport   sql.NullString
+		CreatedAt            sql.NullString
+		UpdatedAt            sql.NullString
+	)
+
+	err := r.db.QueryRowContext(ctx, query, req.Guid).Scan(
+		&Guid,
+		&OriginAirportId,
+		&DestinationAirportId,
+		&CarrierCode,
+		&Distance,
+		&Active,
+		&OriginAirport,
+		&DestinationAirport,
+		&CreatedAt,
+		&UpdatedAt,
+	)
+	if err != nil {
+		return nil, r.dialect.queryError(ctx, err)
+	}
+
+	return &models.Route{
+		Guid:                 Guid.String,
+		OriginAirportId:      OriginAirportId.String,
+		DestinationAirportId: DestinationAirportId.String,
+		CarrierCode:          CarrierCode.String,
+		Distance:             Distance.Float64,
+		Active:               Active.Bool,
+		OriginAirport:        OriginAirport.String,
+		DestinationAirport:   DestinationAirport.String,
+		CreatedAt:            CreatedAt.String,
+		UpdatedAt:            UpdatedAt.String,
+	}, nil
+}
+
+func (r *RouteRepo) GetList(ctx context.Context, req models.GetListRouteRequest) (*models.GetListRouteResponse, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var (
+		resp   = models.GetListRouteResponse{}
+		where  = " WHERE TRUE"
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		args   = []interface{}{}
+	)
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	if len(req.OriginAirportId) > 0 {
+		args = append(args, req.OriginAirportId)
+		where += fmt.Sprintf(` AND r."origin_airport_id" = $%d`, len(args))
+	}
+
+	if len(req.DestinationAirportId) > 0 {
+		args = append(args, req.DestinationAirportId)
+		where += fmt.Sprintf(` AND r."destination_airport_id" = $%d`, len(args))
+	}
+
+	if len(req.OriginCityId) > 0 {
+		args = append(args, req.OriginCityId)
+		where += fmt.Sprintf(` AND o."city_id" = $%d`, len(args))
+	}
+
+	if len(req.DestinationCityId) > 0 {
+		args = append(args, req.DestinationCityId)
+		where += fmt.Sprintf(` AND d."city_id" = $%d`, len(args))
+	}
+
+	if req.OnlyActive {
+		where += ` AND r."active"`
+	}
+
+	query := `
+		SELECT
+			COUNT(*) OVER(),
+			r."guid",
+			r."origin_airport_id",
+			r."destination_airport_id",
+			r."carrier_code",
+			r."distance",
+			r."active",
+			o."title",
+			d."title",
+			r."created_at",
+			r."updated_at"
+		FROM route AS r
+		LEFT JOIN airport AS o ON o."guid" = r."origin_airport_id"
+		LEFT JOIN airport AS d ON d."guid" = r."destination_airport_id"
+	`
+	query += where + ` ORDER BY r."created_at"` + limit + offset
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, r.dialect.queryError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Guid                 sql.NullString
+			OriginAirportId      sql.NullString
+			DestinationAirportId sql.NullString
+			CarrierCode          sql.NullString
+			Distance             sql.NullFloat64
+			Active               sql.NullBool
+			OriginAirport        sql.NullString
+			DestinationAirport   sql.NullString
+			CreatedAt            sql.NullString
+			UpdatedAt            sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Guid,
+			&OriginAirportId,
+			&DestinationAirportId,
+			&CarrierCode,
+			&Distance,
+			&Active,
+			&OriginAirport,
+			&DestinationAirport,
+			&CreatedAt,
+			&UpdatedAt,
+		)
+		if err != nil {
+			return nil, r.dialect.queryError(ctx, err)
+		}
+
+		resp.Routes = append(resp.Routes, models.Route{
+			Guid:                 Guid.String,
+			OriginAirportId:      OriginAirportId.String,
+			DestinationAirportId: DestinationAirportId.String,
+			CarrierCode:          CarrierCode.String,
+			Distance:             Distance.Float64,
+			Active:               Active.Bool,
+			OriginAirport:        OriginAirport.String,
+			DestinationAirport:   DestinationAirport.String,
+			CreatedAt:            CreatedAt.String,
+			UpdatedAt:            UpdatedAt.String,
+		})
+	}
+
+	return &resp, nil
+}
+
+// GetDestinations returns every airport reachable by a direct route
+// from the requested origin airport.
+func (r *RouteRepo) GetDestinations(ctx context.Context, req models.GetDestinationsRequest) (*models.GetListAirportResponse, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var (
+		resp   = models.GetListAirportResponse{}
+		where  = ` WHERE r."origin_airport_id" = $1`
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+	)
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	if req.OnlyActive {
+		where += ` AND r."active"`
+	}
+
+	query := `
+		SELECT
+			COUNT(*) OVER(),
+			a."guid",
+			a."title",
+			a."country_id",
+			a."city_id",
+			a."latitude",
+			a."longitude",
+			a."radius",
+			a."image",
+			a."adress",
+			a."timezone_id",
+			a."country",
+			a."city",
+			a."code",
+			a."product_count",
+			a."gmt",
+			a."created_at",
+			a."updated_at",
+			a."version"
+		FROM airport AS a
+		WHERE a."guid" IN (
+			SELECT r."destination_airport_id" FROM route AS r
+	`
+	query += where + `) ORDER BY a."title"` + limit + offset
+
+	rows, err := r.db.QueryContext(ctx, query, req.AirportId)
+	if err != nil {
+		return nil, r.dialect.queryError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Guid         sql.NullString
+			Title        sql.NullString
+			CountryId    sql.NullString
+			CityId       sql.NullString
+			Latitude     sql.NullFloat64
+			Longitude    sql.NullFloat64
+			Radius       sql.NullFloat64
+			Image        sql.NullString
+			Adress       sql.NullString
+			TimezoneId   sql.NullString
+			Country      sql.NullString
+			City         sql.NullString
+			Code         sql.NullString
+			ProductCount sql.NullInt64
+			Gmt          sql.NullString
+			CreatedAt    sql.NullString
+			UpdatedAt    sql.NullString
+			Version      sql.NullInt64
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Guid,
+			&Title,
+			&CountryId,
+			&CityId,
+			&Latitude,
+			&Longitude,
+			&Radius,
+			&Image,
+			&Adress,
+			&TimezoneId,
+			&Country,
+			&City,
+			&Code,
+			&ProductCount,
+			&Gmt,
+			&CreatedAt,
+			&UpdatedAt,
+			&Version,
+		)
+		if err != nil {
+			return nil, r.dialect.queryError(ctx, err)
+		}
+
+		resp.Airports = append(resp.Airports, models.Airport{
+			Guid:         Guid.String,
+			Title:        Title.String,
+			CountryId:    CountryId.String,
+			CityId:       CityId.String,
+			Latitude:     Latitude.Float64,
+			Longitude:    Longitude.Float64,
+			Radius:       Radius.Float64,
+			Image:        Image.String,
+			Adress:       Adress.String,
+			TimezoneId:   TimezoneId.String,
+			Country:      Country.String,
+			City:         City.String,
+			Code:         Code.String,
+			ProductCount: int(ProductCount.Int64),
+			Gmt:          Gmt.String,
+			CreatedAt:    CreatedAt.String,
+			UpdatedAt:    UpdatedAt.String,
+			Version:      int(Version.Int64),
+		})
+	}
+
+	return &resp, nil
+}
+
+func (r *RouteRepo) Update(ctx context.Context, req models.UpdateRoute) (*models.Route, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	query := `
+		UPDATE route SET
+			"origin_airport_id" = $1,
+			"destination_airport_id" = $2,
+			"carrier_code" = $3,
+			"distance" = $4,
+			"active" = $5,
+			"updated_at" = ` + r.dialect.Now + `
+		WHERE "guid" = $6
+	`
+	_, err := r.db.ExecContext(ctx,
+		query,
+		req.OriginAirportId,
+		req.DestinationAirportId,
+		req.CarrierCode,
+		req.Distance,
+		req.Active,
+		req.Guid,
+	)
+	if err != nil {
+		return &models.Route{}, r.dialect.queryError(ctx, err)
+	}
+
+	return r.GetById(ctx, models.RoutePrimaryKey{Guid: req.Guid})
+}
+
+func (r *RouteRepo) Delete(ctx context.Context, req models.RoutePrimaryKey) (string, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM route WHERE guid = $1`, req.Guid)
+
+	if err != nil {
+		return "Does not delete", r.dialect.queryError(ctx, err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return "Does not delete", storage.ErrNotFound
+	}
+
+	return "Deleted", nil
+}
+
+func (r *RouteRepo) Upload(ctx context.Context, req []models.CreateRoute) error {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Upload)
+	defer cancel()
+
+	for _, v := range req {
+		query, args := r.insert(uuid.New().String(), v)
+		_, err := r.db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return r.dialect.queryError(ctx, err)
+		}
+	}
+
+	return nil
+}
+
+// insert returns the INSERT of req as the route guid. A route that does
+// not say whether it is active is left to the column default.
+func (r *RouteRepo) insert(guid string, req models.CreateRoute) (string, []interface{}) {
+	columns := `"guid", "origin_airport_id", "destination_airport_id", "carrier_code", "distance"`
+	values := `$1, $2, $3, $4, $5`
+	args := []interface{}{guid, req.OriginAirportId, req.DestinationAirportId, req.CarrierCode, req.Distance}
+
+	if req.Active != nil {
+		columns += `, "active"`
+		values += `, $6`
+		args = append(args, *req.Active)
+	}
+
+	query := `INSERT INTO route(` + columns + `, "updated_at") VALUES(` + values + `, ` + r.dialect.Now + `)`
+	return query, args
+}
@@ -0,0 +1,196 @@
+// Package sqlstore implements storage.StorageI on a SQL database. The
+// queries are written once; what Postgres and SQLite spell differently
+// comes from the Dialect the store is opened with.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"essy_travel/migrations"
+	"essy_travel/storage"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// Dialect is what the repos need to know of the database they run on.
+type Dialect struct {
+	// ILike is the operator matching a pattern ignoring case.
+	ILike string
+	// Now is the expression for the current time.
+	Now string
+	// Migrations build the schema the repos query.
+	Migrations fs.FS
+	// Constraint reports whether err is an integrity constraint violation
+	// and returns its message.
+	Constraint func(err error) (string, bool)
+}
+
+type Store struct {
+	db       *sql.DB
+	timeouts storage.Timeouts
+	dialect  *Dialect
+
+	city    *CityRepo
+	country *CountryRepo
+	airport *AirportRepo
+	route   *RouteRepo
+	airline *AirlineRepo
+
+	translation *TranslationRepo
+	alias       *AliasRepo
+	apiKey      *ApiKeyRepo
+	user        *UserRepo
+}
+
+func New(db *sql.DB, timeouts storage.Timeouts, dialect *Dialect) *Store {
+	return &Store{
+		db:       db,
+		timeouts: timeouts,
+		dialect:  dialect,
+
+		city:    NewCityRepo(db, timeouts, dialect),
+		country: NewCountryRepo(db, timeouts, dialect),
+		airport: NewAirportRepo(db, timeouts, dialect),
+		route:   NewRouteRepo(db, timeouts, dialect),
+		airline: NewAirlineRepo(db, timeouts, dialect),
+
+		translation: NewTranslationRepo(db, timeouts, dialect),
+		alias:       NewAliasRepo(db, timeouts, dialect),
+		apiKey:      NewApiKeyRepo(db, timeouts, dialect),
+		user:        NewUserRepo(db, timeouts, dialect),
+	}
+}
+
+func (s *Store) City() storage.CityRepoI {
+	return s.city
+}
+
+func (s *Store) Country() storage.CountryRepoI {
+	return s.country
+}
+
+func (s *Store) Airport() storage.AirportRepoI {
+	return s.airport
+}
+
+func (s *Store) Route() storage.RouteRepoI {
+	return s.route
+}
+
+func (s *Store) Airline() storage.AirlineRepoI {
+	return s.airline
+}
+
+func (s *Store) Translation() storage.TranslationRepoI {
+	return s.translation
+}
+
+func (s *Store) Alias() storage.AliasRepoI {
+	return s.alias
+}
+
+func (s *Store) ApiKey() storage.ApiKeyRepoI {
+	return s.apiKey
+}
+
+func (s *Store) User() storage.UserRepoI {
+	return s.user
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	return s.dialect.queryError(ctx, s.db.PingContext(ctx))
+}
+
+func (s *Store) Schema(ctx context.Context) (storage.Schema, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	runner, err := migrations.NewRunner(s.db, s.dialect.Migrations)
+	if err != nil {
+		return storage.Schema{}, err
+	}
+
+	version, dirty, err := runner.Version(ctx)
+	if err != nil {
+		return storage.Schema{}, s.dialect.queryError(ctx, err)
+	}
+
+	return storage.Schema{
+		Version: version,
+		Latest:  runner.Latest(),
+		Dirty:   dirty,
+	}, nil
+}
+
+func (s *Store) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// withTimeout bounds ctx by d; a zero d leaves the deadline to the caller.
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// deleteEntity deletes the row guid of the table entity together with the
+// translations and aliases naming it, which have no foreign key to cascade
+// along, in one transaction.
+func deleteEntity(ctx context.Context, db *sql.DB, dialect *Dialect, entity, guid string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return dialect.queryError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM `+entity+` WHERE "guid" = $1`, guid)
+	if err != nil {
+		return dialect.queryError(ctx, err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return storage.ErrNotFound
+	}
+
+	for _, table := range []string{"translation", "alias"} {
+		_, err = tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE "entity" = $1 AND "entity_id" = $2`, entity, guid)
+		if err != nil {
+			return dialect.queryError(ctx, err)
+		}
+	}
+
+	return dialect.queryError(ctx, tx.Commit())
+}
+
+// queryError translates driver errors into the storage package errors.
+// A statement cancelled because the operation deadline ran out is
+// reported as storage.ErrTimeout rather than as a driver error.
+func (d *Dialect) queryError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return storage.ErrTimeout
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+
+	if message, ok := d.Constraint(err); ok {
+		return fmt.Errorf("%w: %s", storage.ErrConstraint, message)
+	}
+
+	return err
+}
@@ -0,0 +1,315 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"essy_travel/models"
+	"essy_travel/storage"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type TranslationRepo struct {
+	db       *sql.DB
+	timeouts storage.Timeouts
+	dialect  *Dialect
+}
+
+func NewTranslationRepo(db *sql.DB, timeouts storage.Timeouts, dialect *Dialect) *TranslationRepo {
+	return &TranslationRepo{
+		db:       db,
+		timeouts: timeouts,
+		dialect:  dialect,
+	}
+}
+
+// Create inserts a translation, replacing the title when the entity
+// already has one in the same locale.
+func (t *TranslationRepo) Create(ctx context.Context, req models.CreateTranslation) (*models.Translation, error) {
+	ctx, cancel := withTimeout(ctx, t.timeouts.Write)
+	defer cancel()
+
+	query := `
+		INSERT INTO translation(
+			"guid",
+			"entity",
+			"entity_id",
+			"locale",
+			"title",
+			"updated_at"
+		) VALUES($1, $2, $3, $4, $5, ` + t.dialect.Now + `)
+		ON CONFLICT ("entity", "entity_id", "locale") DO UPDATE SET
+			"title" = EXCLUDED."title",
+			"updated_at" = ` + t.dialect.Now + `
+		RETURNING "guid"
+	`
+
+	var guid string
+	err := t.db.QueryRowContext(ctx, query,
+		uuid.New().String(),
+		req.Entity,
+		req.EntityId,
+		req.Locale,
+		req.Title,
+	).Scan(&guid)
+	if err != nil {
+		return &models.Translation{}, t.dialect.queryError(ctx, err)
+	}
+
+	return t.GetById(ctx, models.TranslationPrimaryKey{Guid: guid})
+}
+
+func (t *TranslationRepo) GetById(ctx context.Context, req models.TranslationPrimaryKey) (*models.Translation, error) {
+	ctx, cancel := withTimeout(ctx, t.timeouts.Read)
+	defer cancel()
+
+	query := `
+		SELECT
+			"guid",
+			"entity",
+			"entity_id",
+			"locale",
+			"title",
+			"created_at",
+			"updated_at"
+		FROM translation
+		WHERE guid = $1
+	`
+
+	var (
+		Guid      sql.NullString
+		Entity    sql.NullString
+		EntityId  sql.NullString
+		Locale    sql.NullString
+		Title     sql.NullString
+		CreatedAt sql.NullString
+		UpdatedAt sql.NullString
+	)
+
+	err := t.db.QueryRowContext(ctx, query, req.Guid).Scan(
+		&Guid,
+		&Entity,
+		&EntityId,
+		&Locale,
+		&Title,
+		&CreatedAt,
+		&UpdatedAt,
+	)
+	if err != nil {
+		return nil, t.dialect.queryError(ctx, err)
+	}
+
+	return &models.Translation{
+		Guid:      Guid.String,
+		Entity:    Entity.String,
+		EntityId:  EntityId.String,
+		Locale:    Locale.String,
+		Title:     Title.String,
+		CreatedAt: CreatedAt.String,
+		UpdatedAt: UpdatedAt.String,
+	}, nil
+}
+
+func (t *TranslationRepo) GetList(ctx context.Context, req models.GetListTranslationRequest) (*models.GetListTranslationResponse, error) {
+	ctx, cancel := withTimeout(ctx, t.timeouts.Read)
+	defer cancel()
+
+	var (
+		resp   = models.GetListTranslationResponse{}
+		where  = " WHERE TRUE"
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		args   = []interface{}{}
+	)
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	if len(req.Entity) > 0 {
+		args = append(args, req.Entity)
+		where += fmt.Sprintf(` AND "entity" = $%d`, len(args))
+	}
+
+	if len(req.EntityId) > 0 {
+		args = append(args, req.EntityId)
+		where += fmt.Sprintf(` AND "entity_id" = $%d`, len(args))
+	}
+
+	if len(req.Locale) > 0 {
+		args = append(args, req.Locale)
+		where += fmt.Sprintf(` AND "locale" = $%d`, len(args))
+	}
+
+	query := `
+		SELECT
+			COUNT(*) OVER(),
+			"guid",
+			"entity",
+			"entity_id",
+			"locale",
+			"title",
+			"created_at",
+			"updated_at"
+		FROM translation
+	`
+	query += where + ` ORDER BY "entity", "entity_id", "locale"` + limit + offset
+
+	rows, err := t.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, t.dialect.queryError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Guid      sql.NullString
+			Entity    sql.NullString
+			EntityId  sql.NullString
+			Locale    sql.NullString
+			Title     sql.NullString
+			CreatedAt sql.NullString
+			UpdatedAt sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Guid,
+			&Entity,
+			&EntityId,
+			&Locale,
+			&Title,
+			&CreatedAt,
+			&UpdatedAt,
+		)
+		if err != nil {
+			return nil, t.dialect.queryError(ctx, err)
+		}
+
+		resp.Translations = append(resp.Translations, models.Translation{
+			Guid:      Guid.String,
+			Entity:    Entity.String,
+			EntityId:  EntityId.String,
+			Locale:    Locale.String,
+			Title:     Title.String,
+			CreatedAt: CreatedAt.String,
+			UpdatedAt: UpdatedAt.String,
+		})
+	}
+
+	return &resp, nil
+}
+
+// GetTitles returns entity id -> title using, for every entity, the first
+// locale in req.Locales that has a translation. Entities without any
+// matching translation are left out of the result.
+func (t *TranslationRepo) GetTitles(ctx context.Context, req models.GetTitlesRequest) (map[string]string, error) {
+	ctx, cancel := withTimeout(ctx, t.timeouts.Read)
+	defer cancel()
+
+	var resp = map[string]string{}
+
+	if len(req.EntityIds) == 0 || len(req.Locales) == 0 {
+		return resp, nil
+	}
+
+	var (
+		args     = []interface{}{req.Entity}
+		ids      = make([]string, 0, len(req.EntityIds))
+		locales  = make([]string, 0, len(req.Locales))
+		priority = map[string]int{}
+	)
+
+	for _, id := range req.EntityIds {
+		args = append(args, id)
+		ids = append(ids, fmt.Sprintf("$%d", len(args)))
+	}
+
+	for i, locale := range req.Locales {
+		args = append(args, locale)
+		locales = append(locales, fmt.Sprintf("$%d", len(args)))
+		if _, ok := priority[locale]; !ok {
+			priority[locale] = i
+		}
+	}
+
+	// DISTINCT ON is Postgres only, so every matching locale is read and
+	// the preferred one is picked here.
+	query := `
+		SELECT
+			"entity_id",
+			"locale",
+			"title"
+		FROM translation
+		WHERE "entity" = $1 AND CAST("entity_id" AS TEXT) IN (` + strings.Join(ids, ", ") + `) AND "locale" IN (` + strings.Join(locales, ", ") + `)
+	`
+
+	rows, err := t.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, t.dialect.queryError(ctx, err)
+	}
+	defer rows.Close()
+
+	var best = map[string]int{}
+	for rows.Next() {
+		var (
+			EntityId string
+			Locale   string
+			Title    sql.NullString
+		)
+
+		err = rows.Scan(&EntityId, &Locale, &Title)
+		if err != nil {
+			return nil, t.dialect.queryError(ctx, err)
+		}
+
+		if current, ok := best[EntityId]; !ok || priority[Locale] < current {
+			best[EntityId] = priority[Locale]
+			resp[EntityId] = Title.String
+		}
+	}
+
+	return resp, nil
+}
+
+func (t *TranslationRepo) Update(ctx context.Context, req models.UpdateTranslation) (*models.Translation, error) {
+	ctx, cancel := withTimeout(ctx, t.timeouts.Write)
+	defer cancel()
+
+	query := `
+		UPDATE translation SET
+			"locale" = $1,
+			"title" = $2,
+			"updated_at" = ` + t.dialect.Now + `
+		WHERE "guid" = $3
+	`
+	_, err := t.db.ExecContext(ctx, query, req.Locale, req.Title, req.Guid)
+	if err != nil {
+		return &models.Translation{}, t.dialect.queryError(ctx, err)
+	}
+
+	return t.GetById(ctx, models.TranslationPrimaryKey{Guid: req.Guid})
+}
+
+func (t *TranslationRepo) Delete(ctx context.Context, req models.TranslationPrimaryKey) (string, error) {
+	ctx, cancel := withTimeout(ctx, t.timeouts.Write)
+	defer cancel()
+
+	result, err := t.db.ExecContext(ctx, `DELETE FROM translation WHERE guid = $1`, req.Guid)
+
+	if err != nil {
+		return "Does not delete", t.dialect.queryError(ctx, err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return "Does not delete", storage.ErrNotFound
+	}
+
+	return "Deleted", nil
+}
@@ -0,0 +1,253 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"essy_travel/models"
+	"essy_travel/pkg/helpers"
+	"essy_travel/storage"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type UserRepo struct {
+	db       *sql.DB
+	timeouts storage.Timeouts
+	dialect  *Dialect
+}
+
+func NewUserRepo(db *sql.DB, timeouts storage.Timeouts, dialect *Dialect) *UserRepo {
+	return &UserRepo{
+		db:       db,
+		timeouts: timeouts,
+		dialect:  dialect,
+	}
+}
+
+func (u *UserRepo) Create(ctx context.Context, req models.CreateUser) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx, u.timeouts.Write)
+	defer cancel()
+
+	query := `
+		INSERT INTO users(
+			"guid",
+			"login",
+			"email",
+			"phone",
+			"full_name",
+			"role",
+			"password_hash"
+		) VALUES($1, $2, $3, $4, $5, $6, $7)`
+
+	guid := uuid.New().String()
+	_, err := u.db.ExecContext(ctx, query,
+		guid,
+		req.Login,
+		req.Email,
+		helpers.NewNullString(req.Phone),
+		helpers.NewNullString(req.FullName),
+		req.Role,
+		req.PasswordHash,
+	)
+	if err != nil {
+		return &models.User{}, u.dialect.queryError(ctx, err)
+	}
+
+	return u.GetById(ctx, models.UserPrimaryKey{Guid: guid})
+}
+
+func (u *UserRepo) GetById(ctx context.Context, req models.UserPrimaryKey) (*models.User, error) {
+	return u.get(ctx, `"guid" = $1`, req.Guid)
+}
+
+// GetByLogin finds the user whose login or email is req.Login.
+func (u *UserRepo) GetByLogin(ctx context.Context, req models.UserLoginKey) (*models.User, error) {
+	return u.get(ctx, `"login" = $1 OR "email" = $1`, req.Login)
+}
+
+func (u *UserRepo) get(ctx context.Context, where string, arg interface{}) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx, u.timeouts.Read)
+	defer cancel()
+
+	query := `
+		SELECT
+			"guid",
+			"login",
+			"email",
+			"phone",
+			"full_name",
+			"role",
+			"password_hash",
+			"token_version",
+			"created_at",
+			"updated_at"
+		FROM users
+		WHERE ` + where
+
+	var (
+		Guid         sql.NullString
+		Login        sql.NullString
+		Email        sql.NullString
+		Phone        sql.NullString
+		FullName     sql.NullString
+		Role         sql.NullString
+		PasswordHash sql.NullString
+		TokenVersion sql.NullInt64
+		CreatedAt    sql.NullString
+		UpdatedAt    sql.NullString
+	)
+
+	err := u.db.QueryRowContext(ctx, query, arg).Scan(
+		&Guid,
+		&Login,
+		&Email,
+		&Phone,
+		&FullName,
+		&Role,
+		&PasswordHash,
+		&TokenVersion,
+		&CreatedAt,
+		&UpdatedAt,
+	)
+	if err != nil {
+		return nil, u.dialect.queryError(ctx, err)
+	}
+
+	return &models.User{
+		Guid:         Guid.String,
+		Login:        Login.String,
+		Email:        Email.String,
+		Phone:        Phone.String,
+		FullName:     FullName.String,
+		Role:         Role.String,
+		PasswordHash: PasswordHash.String,
+		TokenVersion: int(TokenVersion.Int64),
+		CreatedAt:    CreatedAt.String,
+		UpdatedAt:    UpdatedAt.String,
+	}, nil
+}
+
+func (u *UserRepo) GetList(ctx context.Context, req models.GetListUserRequest) (*models.GetListUserResponse, error) {
+	ctx, cancel := withTimeout(ctx, u.timeouts.Read)
+	defer cancel()
+
+	var (
+		resp   = models.GetListUserResponse{}
+		where  = " WHERE TRUE"
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		args   = []interface{}{}
+	)
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	if len(req.Search) > 0 {
+		args = append(args, "%"+req.Search+"%")
+		where += ` AND ("login" ` + u.dialect.ILike + ` $1 OR "email" ` + u.dialect.ILike + ` $1 OR "full_name" ` + u.dialect.ILike + ` $1)`
+	}
+
+	query := `
+		SELECT
+			COUNT(*) OVER(),
+			"guid",
+			"login",
+			"email",
+			"phone",
+			"full_name",
+			"role",
+			"created_at",
+			"updated_at"
+		FROM users
+	`
+	query += where + ` ORDER BY "login"` + limit + offset
+
+	rows, err := u.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, u.dialect.queryError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Guid      sql.NullString
+			Login     sql.NullString
+			Email     sql.NullString
+			Phone     sql.NullString
+			FullName  sql.NullString
+			Role      sql.NullString
+			CreatedAt sql.NullString
+			UpdatedAt sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Guid,
+			&Login,
+			&Email,
+			&Phone,
+			&FullName,
+			&Role,
+			&CreatedAt,
+			&UpdatedAt,
+		)
+		if err != nil {
+			return nil, u.dialect.queryError(ctx, err)
+		}
+
+		resp.Users = append(resp.Users, models.User{
+			Guid:      Guid.String,
+			Login:     Login.String,
+			Email:     Email.String,
+			Phone:     Phone.String,
+			FullName:  FullName.String,
+			Role:      Role.String,
+			CreatedAt: CreatedAt.String,
+			UpdatedAt: UpdatedAt.String,
+		})
+	}
+
+	return &resp, nil
+}
+
+func (u *UserRepo) Update(ctx context.Context, req models.UpdateUser) (*models.User, error) {
+	return u.update(ctx, req.Guid, `"email" = $2, "phone" = $3, "full_name" = $4`,
+		req.Email,
+		helpers.NewNullString(req.Phone),
+		helpers.NewNullString(req.FullName),
+	)
+}
+
+func (u *UserRepo) UpdatePassword(ctx context.Context, req models.UpdateUserPassword) (*models.User, error) {
+	return u.update(ctx, req.Guid, `"password_hash" = $2, "token_version" = "token_version" + 1`, req.PasswordHash)
+}
+
+func (u *UserRepo) UpdateRole(ctx context.Context, req models.UpdateUserRole) (*models.User, error) {
+	return u.update(ctx, req.Guid, `"role" = $2`, req.Role)
+}
+
+// update sets the columns of the user guid, whose values are numbered from
+// $2.
+func (u *UserRepo) update(ctx context.Context, guid string, set string, args ...interface{}) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx, u.timeouts.Write)
+	defer cancel()
+
+	query := `UPDATE users SET ` + set + `, "updated_at" = ` + u.dialect.Now + ` WHERE "guid" = $1`
+
+	result, err := u.db.ExecContext(ctx, query, append([]interface{}{guid}, args...)...)
+	if err != nil {
+		return nil, u.dialect.queryError(ctx, err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, storage.ErrNotFound
+	}
+
+	return u.GetById(ctx, models.UserPrimaryKey{Guid: guid})
+}
@@ -1,36 +1,146 @@
 package storage
 
-import "essy_travel/models"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"essy_travel/models"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when the requested row does not exist.
+	ErrNotFound = errors.New("storage: not found")
+
+	// ErrConstraint is returned when a write breaks a reference, unique or
+	// check constraint, e.g. an airport pointing at an unknown city.
+	ErrConstraint = errors.New("storage: constraint violation")
+
+	// ErrTimeout is returned when a storage operation runs longer than its
+	// configured timeout.
+	ErrTimeout = errors.New("storage: query timed out")
+
+	// ErrConflict is returned when an update names a version other than
+	// the stored one, i.e. someone else changed the row since it was read.
+	// The update returns the row as it is along with it.
+	ErrConflict = errors.New("storage: version conflict")
+)
+
+// Timeouts bound how long a single repo call may run. Zero means no limit.
+type Timeouts struct {
+	Read   time.Duration
+	Write  time.Duration
+	Upload time.Duration
+}
+
+// Schema is the migration state of the database behind a storage.
+type Schema struct {
+	Version uint `json:"version"`
+	Latest  uint `json:"latest"`
+	Dirty   bool `json:"dirty"`
+}
 
 type StorageI interface {
+	// Ping checks that the database can be reached.
+	Ping(ctx context.Context) error
+	// Schema reports the applied and the latest known migration.
+	Schema(ctx context.Context) (Schema, error)
+	// Stats returns the connection pool statistics.
+	Stats() sql.DBStats
+	// Close releases the database; the storage is unusable afterwards.
+	Close() error
+
 	City() CityRepoI
 	Airport() AirportRepoI
 	Country() CountryRepoI
+	Route() RouteRepoI
+	Airline() AirlineRepoI
+	Translation() TranslationRepoI
+	Alias() AliasRepoI
+	ApiKey() ApiKeyRepoI
+	User() UserRepoI
 }
 
 type CountryRepoI interface {
-	Create(req models.CreateCountry) (*models.Country, error)
-	Update(req models.UpdateCountry) (*models.Country, error)
-	GetById(req models.CountryPrimaryKey) (*models.Country, error)
-	GetList(req models.GetListCountryRequest) (*models.GetListCountryResponse, error)
-	Delete(req models.CountryPrimaryKey) (string, error)
-	Upload(req []models.CreateCountry) error
+	Create(ctx context.Context, req models.CreateCountry) (*models.Country, error)
+	Update(ctx context.Context, req models.UpdateCountry) (*models.Country, error)
+	GetById(ctx context.Context, req models.CountryPrimaryKey) (*models.Country, error)
+	GetList(ctx context.Context, req models.GetListCountryRequest) (*models.GetListCountryResponse, error)
+	Delete(ctx context.Context, req models.CountryPrimaryKey) (string, error)
+	Upload(ctx context.Context, req []models.CreateCountry) error
 }
 
 type CityRepoI interface {
-	Create(req models.CreateCity) (*models.City, error)
-	Update(req models.UpdateCity) (*models.City, error)
-	GetById(req models.CityPrimaryKey) (*models.City, error)
-	GetList(req models.GetListCityRequest) (*models.GetListCityResponse, error)
-	Delete(req models.CityPrimaryKey) (string, error)
-	Upload(req []models.CreateCity) error
+	Create(ctx context.Context, req models.CreateCity) (*models.City, error)
+	Update(ctx context.Context, req models.UpdateCity) (*models.City, error)
+	GetById(ctx context.Context, req models.CityPrimaryKey) (*models.City, error)
+	GetList(ctx context.Context, req models.GetListCityRequest) (*models.GetListCityResponse, error)
+	Delete(ctx context.Context, req models.CityPrimaryKey) (string, error)
+	Upload(ctx context.Context, req []models.CreateCity) error
 }
 
 type AirportRepoI interface {
-	Create(req models.CreateAirport) (*models.Airport, error)
-	Update(req models.UpdateAirport) (*models.Airport, error)
-	GetById(req models.AirportPrimaryKey) (*models.Airport, error)
-	GetList(req models.GetListAirportRequest) (*models.GetListAirportResponse, error)
-	Delete(req models.AirportPrimaryKey) (string, error)
-	Upload(req []models.CreateAirport) error
+	Create(ctx context.Context, req models.CreateAirport) (*models.Airport, error)
+	Update(ctx context.Context, req models.UpdateAirport) (*models.Airport, error)
+	GetById(ctx context.Context, req models.AirportPrimaryKey) (*models.Airport, error)
+	GetList(ctx context.Context, req models.GetListAirportRequest) (*models.GetListAirportResponse, error)
+	UpdateImage(ctx context.Context, req models.UpdateAirportImage) (*models.Airport, error)
+	Delete(ctx context.Context, req models.AirportPrimaryKey) (string, error)
+	Upload(ctx context.Context, req []models.CreateAirport) error
+}
+
+type RouteRepoI interface {
+	Create(ctx context.Context, req models.CreateRoute) (*models.Route, error)
+	Update(ctx context.Context, req models.UpdateRoute) (*models.Route, error)
+	GetById(ctx context.Context, req models.RoutePrimaryKey) (*models.Route, error)
+	GetList(ctx context.Context, req models.GetListRouteRequest) (*models.GetListRouteResponse, error)
+	GetDestinations(ctx context.Context, req models.GetDestinationsRequest) (*models.GetListAirportResponse, error)
+	Delete(ctx context.Context, req models.RoutePrimaryKey) (string, error)
+	Upload(ctx context.Context, req []models.CreateRoute) error
+}
+
+type AirlineRepoI interface {
+	Create(ctx context.Context, req models.CreateAirline) (*models.Airline, error)
+	Update(ctx context.Context, req models.UpdateAirline) (*models.Airline, error)
+	GetById(ctx context.Context, req models.AirlinePrimaryKey) (*models.Airline, error)
+	GetList(ctx context.Context, req models.GetListAirlineRequest) (*models.GetListAirlineResponse, error)
+	Delete(ctx context.Context, req models.AirlinePrimaryKey) (string, error)
+	Upload(ctx context.Context, req []models.CreateAirline) error
+	AddAirport(ctx context.Context, req models.CreateAirlineAirport) error
+	RemoveAirport(ctx context.Context, req models.AirlineAirportPrimaryKey) error
+	GetAirports(ctx context.Context, req models.AirlinePrimaryKey) (*models.GetListAirlineAirportResponse, error)
+}
+
+type TranslationRepoI interface {
+	Create(ctx context.Context, req models.CreateTranslation) (*models.Translation, error)
+	Update(ctx context.Context, req models.UpdateTranslation) (*models.Translation, error)
+	GetById(ctx context.Context, req models.TranslationPrimaryKey) (*models.Translation, error)
+	GetList(ctx context.Context, req models.GetListTranslationRequest) (*models.GetListTranslationResponse, error)
+	GetTitles(ctx context.Context, req models.GetTitlesRequest) (map[string]string, error)
+	Delete(ctx context.Context, req models.TranslationPrimaryKey) (string, error)
+}
+
+type AliasRepoI interface {
+	Create(ctx context.Context, req models.CreateAlias) (*models.Alias, error)
+	GetById(ctx context.Context, req models.AliasPrimaryKey) (*models.Alias, error)
+	GetList(ctx context.Context, req models.GetListAliasRequest) (*models.GetListAliasResponse, error)
+	Delete(ctx context.Context, req models.AliasPrimaryKey) (string, error)
+}
+
+type ApiKeyRepoI interface {
+	Create(ctx context.Context, req models.CreateApiKey) (*models.ApiKey, error)
+	GetById(ctx context.Context, req models.ApiKeyPrimaryKey) (*models.ApiKey, error)
+	GetByHash(ctx context.Context, req models.ApiKeyHash) (*models.ApiKey, error)
+	GetList(ctx context.Context, req models.GetListApiKeyRequest) (*models.GetListApiKeyResponse, error)
+	Revoke(ctx context.Context, req models.ApiKeyPrimaryKey) (*models.ApiKey, error)
+}
+
+type UserRepoI interface {
+	Create(ctx context.Context, req models.CreateUser) (*models.User, error)
+	GetById(ctx context.Context, req models.UserPrimaryKey) (*models.User, error)
+	GetByLogin(ctx context.Context, req models.UserLoginKey) (*models.User, error)
+	GetList(ctx context.Context, req models.GetListUserRequest) (*models.GetListUserResponse, error)
+	Update(ctx context.Context, req models.UpdateUser) (*models.User, error)
+	UpdatePassword(ctx context.Context, req models.UpdateUserPassword) (*models.User, error)
+	UpdateRole(ctx context.Context, req models.UpdateUserRole) (*models.User, error)
 }
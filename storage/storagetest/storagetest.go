@@ -0,0 +1,1085 @@
+// Package storagetest is the conformance suite every storage.StorageI
+// implementation has to pass, so that handlers behave the same whichever
+// backend they run on. A backend runs it from its own tests:
+//
+//	func TestStorage(t *testing.T) {
+//		storagetest.Run(t, func(t *testing.T) storage.StorageI {
+//			return memory.NewStore()
+//		})
+//	}
+//
+// newStorage is called once per subtest and must return an empty store.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"essy_travel/models"
+	"essy_travel/storage"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Run runs the whole suite against the stores returned by newStorage.
+func Run(t *testing.T, newStorage func(t *testing.T) storage.StorageI) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, strg storage.StorageI)
+	}{
+		{"Country", testCountry},
+		{"CountryList", testCountryList},
+		{"City", testCity},
+		{"CitySearch", testCitySearch},
+		{"Airport", testAirport},
+		{"AirportReferences", testAirportReferences},
+		{"AirportSearch", testAirportSearch},
+		{"Version", testVersion},
+		{"Route", testRoute},
+		{"RouteList", testRouteList},
+		{"Airline", testAirline},
+		{"AirlineAirports", testAirlineAirports},
+		{"Translation", testTranslation},
+		{"Alias", testAlias},
+		{"DeleteNames", testDeleteNames},
+		{"ApiKey", testApiKey},
+		{"User", testUser},
+		{"Context", testContext},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newStorage(t))
+		})
+	}
+}
+
+func testCountry(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	created, err := strg.Country().Create(ctx, models.CreateCountry{Title: "Uzbekistan", Code: "UZ", Continent: "Asia", CallingCode: "998"})
+	mustNot(t, err)
+	if !isUUID(created.Guid) || created.Title != "Uzbekistan" || created.Code != "UZ" || created.Continent != "Asia" || created.CallingCode != "998" {
+		t.Fatalf("Create returned %+v", created)
+	}
+	if len(created.CreatedAt) == 0 {
+		t.Errorf("Create did not set created_at")
+	}
+
+	got, err := strg.Country().GetById(ctx, models.CountryPrimaryKey{Guid: created.Guid})
+	mustNot(t, err)
+	if *got != *created {
+		t.Errorf("GetById = %+v, want %+v", got, created)
+	}
+
+	updated, err := strg.Country().Update(ctx, models.UpdateCountry{Guid: created.Guid, Title: "O'zbekiston", Code: "UZB", Continent: "Asia"})
+	mustNot(t, err)
+	if updated.Guid != created.Guid || updated.Title != "O'zbekiston" || updated.Code != "UZB" || updated.CallingCode != "" {
+		t.Errorf("Update returned %+v", updated)
+	}
+
+	_, err = strg.Country().Update(ctx, models.UpdateCountry{Guid: uuid.NewString(), Title: "Nowhere"})
+	mustBe(t, err, storage.ErrNotFound)
+
+	_, err = strg.Country().Delete(ctx, models.CountryPrimaryKey{Guid: created.Guid})
+	mustNot(t, err)
+
+	_, err = strg.Country().GetById(ctx, models.CountryPrimaryKey{Guid: created.Guid})
+	mustBe(t, err, storage.ErrNotFound)
+
+	_, err = strg.Country().Delete(ctx, models.CountryPrimaryKey{Guid: created.Guid})
+	mustBe(t, err, storage.ErrNotFound)
+}
+
+func testCountryList(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	err := strg.Country().Upload(ctx, []models.CreateCountry{
+		{Title: "Uzbekistan", Code: "UZ", CallingCode: "998"},
+		{Title: "Kazakhstan", Code: "KZ", CallingCode: "7"},
+		{Title: "Turkey", Code: "TR", CallingCode: "90"},
+	})
+	mustNot(t, err)
+
+	byCode, err := strg.Country().GetList(ctx, models.GetListCountryRequest{CallingCode: "7"})
+	mustNot(t, err)
+	if byCode.Count != 1 || len(byCode.Countries) != 1 || byCode.Countries[0].Title != "Kazakhstan" || byCode.Countries[0].CallingCode != "7" {
+		t.Errorf("GetList(calling code 7) = %+v, want Kazakhstan", byCode)
+	}
+
+	first, err := strg.Country().GetList(ctx, models.GetListCountryRequest{Limit: 2})
+	mustNot(t, err)
+	if first.Count != 3 || len(first.Countries) != 2 {
+		t.Fatalf("first page: count %d, %d countries; want 3, 2", first.Count, len(first.Countries))
+	}
+
+	second, err := strg.Country().GetList(ctx, models.GetListCountryRequest{Offset: 2, Limit: 2})
+	mustNot(t, err)
+	if second.Count != 3 || len(second.Countries) != 1 {
+		t.Fatalf("second page: count %d, %d countries; want 3, 1", second.Count, len(second.Countries))
+	}
+
+	var titles []string
+	for _, country := range append(first.Countries, second.Countries...) {
+		titles = append(titles, country.Title)
+	}
+	equalSets(t, titles, []string{"Uzbekistan", "Kazakhstan", "Turkey"})
+
+	empty, err := strg.Country().GetList(ctx, models.GetListCountryRequest{Offset: 3})
+	mustNot(t, err)
+	if empty.Count != 0 || len(empty.Countries) != 0 {
+		t.Errorf("page past the end: count %d, %d countries; want 0, 0", empty.Count, len(empty.Countries))
+	}
+}
+
+func testCity(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	country := createCountry(t, strg)
+
+	created, err := strg.City().Create(ctx, models.CreateCity{
+		Title:       "Tashkent",
+		CountryId:   country.Guid,
+		CityCode:    "TAS",
+		Latitude:    "41.2995",
+		Longitude:   "69.2401",
+		Offset:      "+05:00",
+		CountryName: country.Title,
+	})
+	mustNot(t, err)
+	if !isUUID(created.Guid) || created.Title != "Tashkent" || created.CountryId != country.Guid || created.CityCode != "TAS" {
+		t.Fatalf("Create returned %+v", created)
+	}
+
+	got, err := strg.City().GetById(ctx, models.CityPrimaryKey{Guid: created.Guid})
+	mustNot(t, err)
+	if *got != *created {
+		t.Errorf("GetById = %+v, want %+v", got, created)
+	}
+
+	updated, err := strg.City().Update(ctx, models.UpdateCity{Guid: created.Guid, Title: "Toshkent", CountryId: country.Guid, CityCode: "TAS"})
+	mustNot(t, err)
+	if updated.Title != "Toshkent" || updated.Latitude != "" {
+		t.Errorf("Update returned %+v", updated)
+	}
+
+	_, err = strg.City().Update(ctx, models.UpdateCity{Guid: uuid.NewString(), Title: "Nowhere"})
+	mustBe(t, err, storage.ErrNotFound)
+
+	_, err = strg.City().Delete(ctx, models.CityPrimaryKey{Guid: created.Guid})
+	mustNot(t, err)
+
+	_, err = strg.City().GetById(ctx, models.CityPrimaryKey{Guid: created.Guid})
+	mustBe(t, err, storage.ErrNotFound)
+
+	_, err = strg.City().Delete(ctx, models.CityPrimaryKey{Guid: created.Guid})
+	mustBe(t, err, storage.ErrNotFound)
+}
+
+func testCitySearch(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	err := strg.City().Upload(ctx, []models.CreateCity{
+		{Title: "Mumbai", CityCode: "BOM"},
+		{Title: "Samarkand", CityCode: "SKD"},
+		{Title: "Tashkent", CityCode: "TAS"},
+	})
+	mustNot(t, err)
+
+	mumbai := findCity(t, strg, "Mumbai")
+	_, err = strg.Alias().Create(ctx, models.CreateAlias{Entity: "city", EntityId: mumbai.Guid, Title: "Bombay"})
+	mustNot(t, err)
+
+	for search, want := range map[string][]string{
+		"":        {"Mumbai", "Samarkand", "Tashkent"},
+		"TASH":    {"Tashkent"},
+		"skd":     {"Samarkand"},
+		"a":       {"Mumbai", "Samarkand", "Tashkent"},
+		"bombay":  {"Mumbai"},
+		"nowhere": nil,
+	} {
+		resp, err := strg.City().GetList(ctx, models.GetListCityRequest{Search: search})
+		mustNot(t, err)
+
+		var titles []string
+		for _, city := range resp.Cities {
+			titles = append(titles, city.Title)
+		}
+		if resp.Count != len(want) {
+			t.Errorf("search %q: count %d, want %d", search, resp.Count, len(want))
+		}
+		equalSets(t, titles, want)
+	}
+}
+
+func testAirport(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	country := createCountry(t, strg)
+	city := createCity(t, strg, country.Guid, "Tashkent")
+
+	created, err := strg.Airport().Create(ctx, models.CreateAirport{
+		Title:        "Tashkent International",
+		CountryId:    country.Guid,
+		CityId:       city.Guid,
+		Latitude:     41.2579,
+		Longitude:    69.2812,
+		Radius:       10,
+		Code:         "TAS",
+		ProductCount: 3,
+		Gmt:          "+05:00",
+	})
+	mustNot(t, err)
+	if !isUUID(created.Guid) || created.Title != "Tashkent International" || created.CityId != city.Guid ||
+		created.Latitude != 41.2579 || created.Longitude != 69.2812 || created.ProductCount != 3 {
+		t.Fatalf("Create returned %+v", created)
+	}
+
+	got, err := strg.Airport().GetById(ctx, models.AirportPrimaryKey{Guid: created.Guid})
+	mustNot(t, err)
+	if *got != *created {
+		t.Errorf("GetById = %+v, want %+v", got, created)
+	}
+
+	updated, err := strg.Airport().Update(ctx, models.UpdateAirport{
+		Guid:      created.Guid,
+		Title:     "Islam Karimov International",
+		CountryId: country.Guid,
+		CityId:    city.Guid,
+		Latitude:  41.26,
+		Longitude: 69.28,
+		Code:      "TAS",
+	})
+	mustNot(t, err)
+	if updated.Guid != created.Guid || updated.Title != "Islam Karimov International" || updated.Latitude != 41.26 {
+		t.Errorf("Update returned %+v", updated)
+	}
+
+	withImage, err := strg.Airport().UpdateImage(ctx, models.UpdateAirportImage{Guid: created.Guid, Image: "airport/x.jpg"})
+	mustNot(t, err)
+	if withImage.Image != "airport/x.jpg" || withImage.Title != updated.Title {
+		t.Errorf("UpdateImage returned %+v", withImage)
+	}
+
+	// A second upload that read the airport before the first one stored
+	// its image loses.
+	current, err := strg.Airport().UpdateImage(ctx, models.UpdateAirportImage{Guid: created.Guid, Image: "airport/y.jpg"})
+	mustBe(t, err, storage.ErrConflict)
+	if current == nil || current.Image != "airport/x.jpg" {
+		t.Errorf("UpdateImage of a stale image returned %+v, want the stored row", current)
+	}
+
+	withImage, err = strg.Airport().UpdateImage(ctx, models.UpdateAirportImage{Guid: created.Guid, Image: "airport/y.jpg", OldImage: "airport/x.jpg"})
+	mustNot(t, err)
+	if withImage.Image != "airport/y.jpg" {
+		t.Errorf("UpdateImage returned %+v", withImage)
+	}
+
+	_, err = strg.Airport().Update(ctx, models.UpdateAirport{Guid: uuid.NewString(), Title: "Nowhere"})
+	mustBe(t, err, storage.ErrNotFound)
+
+	_, err = strg.Airport().UpdateImage(ctx, models.UpdateAirportImage{Guid: uuid.NewString()})
+	mustBe(t, err, storage.ErrNotFound)
+
+	_, err = strg.Airport().Delete(ctx, models.AirportPrimaryKey{Guid: created.Guid})
+	mustNot(t, err)
+
+	_, err = strg.Airport().GetById(ctx, models.AirportPrimaryKey{Guid: created.Guid})
+	mustBe(t, err, storage.ErrNotFound)
+
+	_, err = strg.Airport().Delete(ctx, models.AirportPrimaryKey{Guid: created.Guid})
+	mustBe(t, err, storage.ErrNotFound)
+}
+
+func testAirportReferences(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	country := createCountry(t, strg)
+	city := createCity(t, strg, country.Guid, "Tashkent")
+
+	_, err := strg.Airport().Create(ctx, models.CreateAirport{Title: "Lost", CityId: uuid.NewString()})
+	mustBe(t, err, storage.ErrConstraint)
+
+	_, err = strg.Airport().Create(ctx, models.CreateAirport{Title: "Lost", CountryId: uuid.NewString()})
+	mustBe(t, err, storage.ErrConstraint)
+
+	airport := createAirport(t, strg, city, "Tashkent International", "TAS")
+
+	_, err = strg.Airport().Update(ctx, models.UpdateAirport{Guid: airport.Guid, Title: "Lost", CityId: uuid.NewString()})
+	mustBe(t, err, storage.ErrConstraint)
+
+	_, err = strg.City().Delete(ctx, models.CityPrimaryKey{Guid: city.Guid})
+	mustBe(t, err, storage.ErrConstraint)
+
+	_, err = strg.Country().Delete(ctx, models.CountryPrimaryKey{Guid: country.Guid})
+	mustBe(t, err, storage.ErrConstraint)
+}
+
+func testAirportSearch(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	country := createCountry(t, strg)
+	city := createCity(t, strg, country.Guid, "Moscow")
+
+	sheremetyevo := createAirport(t, strg, city, "Sheremetyevo", "SVO")
+	createAirport(t, strg, city, "Domodedovo", "DME")
+	createAirport(t, strg, city, "Vnukovo", "VKO")
+
+	_, err := strg.Alias().Create(ctx, models.CreateAlias{Entity: "airport", EntityId: sheremetyevo.Guid, Title: "Pushkin"})
+	mustNot(t, err)
+
+	for search, want := range map[string][]string{
+		"":        {"Sheremetyevo", "Domodedovo", "Vnukovo"},
+		"dme":     {"Domodedovo"},
+		"ovo":     {"Domodedovo", "Vnukovo"},
+		"pushkin": {"Sheremetyevo"},
+		"nowhere": nil,
+	} {
+		resp, err := strg.Airport().GetList(ctx, models.GetListAirportRequest{Search: search})
+		mustNot(t, err)
+
+		var titles []string
+		for _, airport := range resp.Airports {
+			titles = append(titles, airport.Title)
+		}
+		if resp.Count != len(want) {
+			t.Errorf("search %q: count %d, want %d", search, resp.Count, len(want))
+		}
+		equalSets(t, titles, want)
+	}
+
+	resp, err := strg.Airport().GetList(ctx, models.GetListAirportRequest{Offset: 1, Limit: 1})
+	mustNot(t, err)
+	if resp.Count != 3 || len(resp.Airports) != 1 {
+		t.Errorf("paged list: count %d, %d airports; want 3, 1", resp.Count, len(resp.Airports))
+	}
+}
+
+// testVersion checks that updates of countries, cities and airports move
+// their version on and refuse a stale one.
+func testVersion(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	country := createCountry(t, strg)
+	if country.Version != 1 {
+		t.Fatalf("Create set version %d, want 1", country.Version)
+	}
+
+	updated, err := strg.Country().Update(ctx, models.UpdateCountry{Guid: country.Guid, Title: "O'zbekiston", Code: "UZ", Version: 1})
+	mustNot(t, err)
+	if updated.Version != 2 {
+		t.Errorf("Update set version %d, want 2", updated.Version)
+	}
+
+	current, err := strg.Country().Update(ctx, models.UpdateCountry{Guid: country.Guid, Title: "Stale", Code: "UZ", Version: 1})
+	mustBe(t, err, storage.ErrConflict)
+	if current == nil || current.Title != "O'zbekiston" || current.Version != 2 {
+		t.Errorf("Update of a stale version returned %+v, want the stored row", current)
+	}
+
+	updated, err = strg.Country().Update(ctx, models.UpdateCountry{Guid: country.Guid, Title: "Uzbekistan", Code: "UZ"})
+	mustNot(t, err)
+	if updated.Version != 3 {
+		t.Errorf("Update without a version set version %d, want 3", updated.Version)
+	}
+
+	_, err = strg.Country().Update(ctx, models.UpdateCountry{Guid: uuid.NewString(), Title: "Nowhere", Version: 1})
+	mustBe(t, err, storage.ErrNotFound)
+
+	city := createCity(t, strg, country.Guid, "Tashkent")
+	if city.Version != 1 {
+		t.Fatalf("Create set city version %d, want 1", city.Version)
+	}
+
+	// Timestamps are kept to the millisecond or finer.
+	time.Sleep(5 * time.Millisecond)
+
+	updatedCity, err := strg.City().Update(ctx, models.UpdateCity{Guid: city.Guid, Title: "Toshkent", CountryId: country.Guid, Version: 1})
+	mustNot(t, err)
+	if updatedCity.Version != 2 {
+		t.Errorf("Update set city version %d, want 2", updatedCity.Version)
+	}
+	if updatedCity.UpdatedAt == city.UpdatedAt {
+		t.Errorf("Update left city updated_at at %s", city.UpdatedAt)
+	}
+
+	currentCity, err := strg.City().Update(ctx, models.UpdateCity{Guid: city.Guid, Title: "Stale", CountryId: country.Guid, Version: 1})
+	mustBe(t, err, storage.ErrConflict)
+	if currentCity == nil || currentCity.Title != "Toshkent" {
+		t.Errorf("Update of a stale city returned %+v, want the stored row", currentCity)
+	}
+
+	airport := createAirport(t, strg, city, "Tashkent International", "TAS")
+	if airport.Version != 1 {
+		t.Fatalf("Create set airport version %d, want 1", airport.Version)
+	}
+
+	withImage, err := strg.Airport().UpdateImage(ctx, models.UpdateAirportImage{Guid: airport.Guid, Image: "airport/tas.png"})
+	mustNot(t, err)
+	if withImage.Version != 2 {
+		t.Errorf("UpdateImage set airport version %d, want 2", withImage.Version)
+	}
+
+	currentAirport, err := strg.Airport().Update(ctx, models.UpdateAirport{Guid: airport.Guid, Title: "Stale", CountryId: country.Guid, CityId: city.Guid, Version: 1})
+	mustBe(t, err, storage.ErrConflict)
+	if currentAirport == nil || currentAirport.Image != "airport/tas.png" {
+		t.Errorf("Update of a stale airport returned %+v, want the stored row", currentAirport)
+	}
+
+	updatedAirport, err := strg.Airport().Update(ctx, models.UpdateAirport{Guid: airport.Guid, Title: "Islam Karimov", CountryId: country.Guid, CityId: city.Guid, Version: 2})
+	mustNot(t, err)
+	if updatedAirport.Version != 3 || updatedAirport.Title != "Islam Karimov" {
+		t.Errorf("Update returned %+v", updatedAirport)
+	}
+}
+
+func testRoute(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	country := createCountry(t, strg)
+	city := createCity(t, strg, country.Guid, "Tashkent")
+	tas := createAirport(t, strg, city, "Tashkent International", "TAS")
+	skd := createAirport(t, strg, city, "Samarkand International", "SKD")
+
+	created, err := strg.Route().Create(ctx, models.CreateRoute{
+		OriginAirportId:      tas.Guid,
+		DestinationAirportId: skd.Guid,
+		CarrierCode:          "HY",
+		Distance:             270,
+	})
+	mustNot(t, err)
+	if !isUUID(created.Guid) || created.OriginAirport != tas.Title || created.DestinationAirport != skd.Title || !created.Active {
+		t.Fatalf("Create returned %+v", created)
+	}
+
+	got, err := strg.Route().GetById(ctx, models.RoutePrimaryKey{Guid: created.Guid})
+	mustNot(t, err)
+	if *got != *created {
+		t.Errorf("GetById = %+v, want %+v", got, created)
+	}
+
+	updated, err := strg.Route().Update(ctx, models.UpdateRoute{
+		Guid:                 created.Guid,
+		OriginAirportId:      skd.Guid,
+		DestinationAirportId: tas.Guid,
+		CarrierCode:          "HY",
+		Distance:             270,
+	})
+	mustNot(t, err)
+	if updated.OriginAirport != skd.Title || updated.Active {
+		t.Errorf("Update returned %+v", updated)
+	}
+
+	_, err = strg.Route().Create(ctx, models.CreateRoute{OriginAirportId: tas.Guid, DestinationAirportId: tas.Guid})
+	mustBe(t, err, storage.ErrConstraint)
+
+	_, err = strg.Route().Create(ctx, models.CreateRoute{OriginAirportId: tas.Guid, DestinationAirportId: uuid.NewString()})
+	mustBe(t, err, storage.ErrConstraint)
+
+	_, err = strg.Route().Update(ctx, models.UpdateRoute{Guid: uuid.NewString(), OriginAirportId: tas.Guid, DestinationAirportId: skd.Guid})
+	mustBe(t, err, storage.ErrNotFound)
+
+	_, err = strg.Airport().Delete(ctx, models.AirportPrimaryKey{Guid: skd.Guid})
+	mustNot(t, err)
+
+	_, err = strg.Route().GetById(ctx, models.RoutePrimaryKey{Guid: created.Guid})
+	mustBe(t, err, storage.ErrNotFound)
+
+	_, err = strg.Route().Delete(ctx, models.RoutePrimaryKey{Guid: created.Guid})
+	mustBe(t, err, storage.ErrNotFound)
+}
+
+func testRouteList(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	country := createCountry(t, strg)
+	tashkent := createCity(t, strg, country.Guid, "Tashkent")
+	samarkand := createCity(t, strg, country.Guid, "Samarkand")
+	tas := createAirport(t, strg, tashkent, "Tashkent International", "TAS")
+	skd := createAirport(t, strg, samarkand, "Samarkand International", "SKD")
+	bhk := createAirport(t, strg, samarkand, "Bukhara International", "BHK")
+
+	active, inactive := true, false
+	err := strg.Route().Upload(ctx, []models.CreateRoute{
+		{OriginAirportId: tas.Guid, DestinationAirportId: skd.Guid},
+		{OriginAirportId: tas.Guid, DestinationAirportId: bhk.Guid, Active: &inactive},
+		{OriginAirportId: skd.Guid, DestinationAirportId: tas.Guid, Active: &active},
+	})
+	mustNot(t, err)
+
+	for _, tt := range []struct {
+		name  string
+		req   models.GetListRouteRequest
+		count int
+	}{
+		{"all", models.GetListRouteRequest{}, 3},
+		{"origin", models.GetListRouteRequest{OriginAirportId: tas.Guid}, 2},
+		{"destination", models.GetListRouteRequest{DestinationAirportId: tas.Guid}, 1},
+		{"origin city", models.GetListRouteRequest{OriginCityId: samarkand.Guid}, 1},
+		{"destination city", models.GetListRouteRequest{DestinationCityId: samarkand.Guid}, 2},
+		{"active", models.GetListRouteRequest{OnlyActive: true}, 2},
+		{"origin and active", models.GetListRouteRequest{OriginAirportId: tas.Guid, OnlyActive: true}, 1},
+	} {
+		resp, err := strg.Route().GetList(ctx, tt.req)
+		mustNot(t, err)
+		if resp.Count != tt.count || len(resp.Routes) != tt.count {
+			t.Errorf("%s: count %d, %d routes; want %d", tt.name, resp.Count, len(resp.Routes), tt.count)
+		}
+	}
+
+	destinations, err := strg.Route().GetDestinations(ctx, models.GetDestinationsRequest{AirportId: tas.Guid})
+	mustNot(t, err)
+	if destinations.Count != 2 || len(destinations.Airports) != 2 ||
+		destinations.Airports[0].Guid != bhk.Guid || destinations.Airports[1].Guid != skd.Guid ||
+		destinations.Airports[0].Version != bhk.Version {
+		t.Errorf("GetDestinations = %+v, want Bukhara and Samarkand by title", destinations)
+	}
+
+	destinations, err = strg.Route().GetDestinations(ctx, models.GetDestinationsRequest{AirportId: tas.Guid, OnlyActive: true})
+	mustNot(t, err)
+	if destinations.Count != 1 || destinations.Airports[0].Guid != skd.Guid {
+		t.Errorf("active GetDestinations = %+v, want Samarkand", destinations)
+	}
+}
+
+func testAirline(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	country := createCountry(t, strg)
+
+	created, err := strg.Airline().Create(ctx, models.CreateAirline{
+		IataCode:  "HY",
+		IcaoCode:  "UZB",
+		Title:     "Uzbekistan Airways",
+		CountryId: country.Guid,
+		Active:    true,
+	})
+	mustNot(t, err)
+	if !isUUID(created.Guid) || created.IataCode != "HY" || created.CountryId != country.Guid || !created.Active {
+		t.Fatalf("Create returned %+v", created)
+	}
+
+	got, err := strg.Airline().GetById(ctx, models.AirlinePrimaryKey{Guid: created.Guid})
+	mustNot(t, err)
+	if *got != *created {
+		t.Errorf("GetById = %+v, want %+v", got, created)
+	}
+
+	err = strg.Airline().Upload(ctx, []models.CreateAirline{
+		{IataCode: "TK", IcaoCode: "THY", Title: "Turkish Airlines", Active: true},
+		{IataCode: "KC", IcaoCode: "KZR", Title: "Air Astana", Active: false},
+	})
+	mustNot(t, err)
+
+	for _, tt := range []struct {
+		name string
+		req  models.GetListAirlineRequest
+		want []string
+	}{
+		{"all", models.GetListAirlineRequest{}, []string{"Air Astana", "Turkish Airlines", "Uzbekistan Airways"}},
+		{"search iata", models.GetListAirlineRequest{Search: "tk"}, []string{"Turkish Airlines"}},
+		{"search icao", models.GetListAirlineRequest{Search: "kzr"}, []string{"Air Astana"}},
+		{"country", models.GetListAirlineRequest{CountryId: country.Guid}, []string{"Uzbekistan Airways"}},
+		{"active", models.GetListAirlineRequest{OnlyActive: true}, []string{"Turkish Airlines", "Uzbekistan Airways"}},
+	} {
+		resp, err := strg.Airline().GetList(ctx, tt.req)
+		mustNot(t, err)
+
+		var titles []string
+		for _, airline := range resp.Airlines {
+			titles = append(titles, airline.Title)
+		}
+		if resp.Count != len(tt.want) || !equal(titles, tt.want) {
+			t.Errorf("%s: count %d, titles %q; want %q in order", tt.name, resp.Count, titles, tt.want)
+		}
+	}
+
+	_, err = strg.Airline().Create(ctx, models.CreateAirline{Title: "Lost", CountryId: uuid.NewString()})
+	mustBe(t, err, storage.ErrConstraint)
+
+	updated, err := strg.Airline().Update(ctx, models.UpdateAirline{Guid: created.Guid, IataCode: "HY", Title: "Uzbekistan Airways", Active: false})
+	mustNot(t, err)
+	if updated.Active || updated.CountryId != "" {
+		t.Errorf("Update returned %+v", updated)
+	}
+
+	_, err = strg.Airline().Update(ctx, models.UpdateAirline{Guid: uuid.NewString(), Title: "Nowhere"})
+	mustBe(t, err, storage.ErrNotFound)
+
+	_, err = strg.Airline().Delete(ctx, models.AirlinePrimaryKey{Guid: created.Guid})
+	mustNot(t, err)
+
+	_, err = strg.Airline().Delete(ctx, models.AirlinePrimaryKey{Guid: created.Guid})
+	mustBe(t, err, storage.ErrNotFound)
+}
+
+func testAirlineAirports(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	country := createCountry(t, strg)
+	city := createCity(t, strg, country.Guid, "Tashkent")
+	tas := createAirport(t, strg, city, "Tashkent International", "TAS")
+	skd := createAirport(t, strg, city, "Samarkand International", "SKD")
+	bhk := createAirport(t, strg, city, "Bukhara International", "BHK")
+
+	airline, err := strg.Airline().Create(ctx, models.CreateAirline{IataCode: "HY", Title: "Uzbekistan Airways", Active: true})
+	mustNot(t, err)
+
+	for _, link := range []models.CreateAirlineAirport{
+		{AirlineId: airline.Guid, AirportId: tas.Guid, Type: "hub"},
+		{AirlineId: airline.Guid, AirportId: skd.Guid, Type: "hub"},
+		{AirlineId: airline.Guid, AirportId: bhk.Guid, Type: "hub"},
+		{AirlineId: airline.Guid, AirportId: skd.Guid, Type: "base"},
+	} {
+		mustNot(t, strg.Airline().AddAirport(ctx, link))
+	}
+
+	err = strg.Airline().AddAirport(ctx, models.CreateAirlineAirport{AirlineId: airline.Guid, AirportId: tas.Guid, Type: "gate"})
+	mustBe(t, err, storage.ErrConstraint)
+
+	err = strg.Airline().AddAirport(ctx, models.CreateAirlineAirport{AirlineId: airline.Guid, AirportId: uuid.NewString(), Type: "hub"})
+	mustBe(t, err, storage.ErrConstraint)
+
+	resp, err := strg.Airline().GetAirports(ctx, models.AirlinePrimaryKey{Guid: airline.Guid})
+	mustNot(t, err)
+
+	var got []string
+	for _, link := range resp.Airports {
+		got = append(got, link.Type+" "+link.Airport)
+	}
+	want := []string{"base Samarkand International", "hub Bukhara International", "hub Tashkent International"}
+	if resp.Count != 3 || !equal(got, want) {
+		t.Errorf("GetAirports = %q, want %q", got, want)
+	}
+
+	mustNot(t, strg.Airline().RemoveAirport(ctx, models.AirlineAirportPrimaryKey{AirlineId: airline.Guid, AirportId: tas.Guid}))
+
+	_, err = strg.Airport().Delete(ctx, models.AirportPrimaryKey{Guid: bhk.Guid})
+	mustNot(t, err)
+
+	resp, err = strg.Airline().GetAirports(ctx, models.AirlinePrimaryKey{Guid: airline.Guid})
+	mustNot(t, err)
+	if resp.Count != 1 || resp.Airports[0].AirportId != skd.Guid {
+		t.Errorf("GetAirports after removal = %+v, want only Samarkand", resp)
+	}
+}
+
+func testTranslation(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	country := createCountry(t, strg)
+	other := createCountry(t, strg)
+
+	created, err := strg.Translation().Create(ctx, models.CreateTranslation{Entity: "country", EntityId: country.Guid, Locale: "ru", Title: "Узбекистан"})
+	mustNot(t, err)
+	if !isUUID(created.Guid) || created.Locale != "ru" || created.Title != "Узбекистан" {
+		t.Fatalf("Create returned %+v", created)
+	}
+
+	replaced, err := strg.Translation().Create(ctx, models.CreateTranslation{Entity: "country", EntityId: country.Guid, Locale: "ru", Title: "Республика Узбекистан"})
+	mustNot(t, err)
+	if replaced.Guid != created.Guid || replaced.Title != "Республика Узбекистан" {
+		t.Errorf("Create of an existing locale returned %+v, want the updated %s", replaced, created.Guid)
+	}
+
+	uz, err := strg.Translation().Create(ctx, models.CreateTranslation{Entity: "country", EntityId: country.Guid, Locale: "uz", Title: "O'zbekiston"})
+	mustNot(t, err)
+
+	_, err = strg.Translation().Create(ctx, models.CreateTranslation{Entity: "country", EntityId: other.Guid, Locale: "ru", Title: "Казахстан"})
+	mustNot(t, err)
+
+	_, err = strg.Translation().Create(ctx, models.CreateTranslation{Entity: "planet", EntityId: country.Guid, Locale: "ru", Title: "Земля"})
+	mustBe(t, err, storage.ErrConstraint)
+
+	list, err := strg.Translation().GetList(ctx, models.GetListTranslationRequest{Entity: "country", EntityId: country.Guid})
+	mustNot(t, err)
+	if list.Count != 2 || len(list.Translations) != 2 || list.Translations[0].Locale != "ru" || list.Translations[1].Locale != "uz" {
+		t.Errorf("GetList = %+v, want ru and uz", list)
+	}
+
+	list, err = strg.Translation().GetList(ctx, models.GetListTranslationRequest{Locale: "ru"})
+	mustNot(t, err)
+	if list.Count != 2 {
+		t.Errorf("GetList by locale: count %d, want 2", list.Count)
+	}
+
+	titles, err := strg.Translation().GetTitles(ctx, models.GetTitlesRequest{
+		Entity:    "country",
+		EntityIds: []string{country.Guid, other.Guid, uuid.NewString()},
+		Locales:   []string{"uz", "ru"},
+	})
+	mustNot(t, err)
+	if len(titles) != 2 || titles[country.Guid] != "O'zbekiston" || titles[other.Guid] != "Казахстан" {
+		t.Errorf("GetTitles = %v", titles)
+	}
+
+	_, err = strg.Translation().Update(ctx, models.UpdateTranslation{Guid: uz.Guid, Locale: "ru", Title: "Узбекистан"})
+	mustBe(t, err, storage.ErrConstraint)
+
+	updated, err := strg.Translation().Update(ctx, models.UpdateTranslation{Guid: uz.Guid, Locale: "uz", Title: "Oʻzbekiston"})
+	mustNot(t, err)
+	if updated.Title != "Oʻzbekiston" {
+		t.Errorf("Update returned %+v", updated)
+	}
+
+	_, err = strg.Translation().Update(ctx, models.UpdateTranslation{Guid: uuid.NewString(), Locale: "en"})
+	mustBe(t, err, storage.ErrNotFound)
+
+	_, err = strg.Translation().Delete(ctx, models.TranslationPrimaryKey{Guid: uz.Guid})
+	mustNot(t, err)
+
+	_, err = strg.Translation().GetById(ctx, models.TranslationPrimaryKey{Guid: uz.Guid})
+	mustBe(t, err, storage.ErrNotFound)
+
+	_, err = strg.Translation().Delete(ctx, models.TranslationPrimaryKey{Guid: uz.Guid})
+	mustBe(t, err, storage.ErrNotFound)
+}
+
+func testAlias(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	country := createCountry(t, strg)
+	city := createCity(t, strg, country.Guid, "Mumbai")
+
+	created, err := strg.Alias().Create(ctx, models.CreateAlias{Entity: "city", EntityId: city.Guid, Title: "Bombay"})
+	mustNot(t, err)
+	if !isUUID(created.Guid) || created.Entity != "city" || created.EntityId != city.Guid || created.Title != "Bombay" {
+		t.Fatalf("Create returned %+v", created)
+	}
+
+	got, err := strg.Alias().GetById(ctx, models.AliasPrimaryKey{Guid: created.Guid})
+	mustNot(t, err)
+	if *got != *created {
+		t.Errorf("GetById = %+v, want %+v", got, created)
+	}
+
+	_, err = strg.Alias().Create(ctx, models.CreateAlias{Entity: "city", EntityId: city.Guid, Title: "Bombay"})
+	mustBe(t, err, storage.ErrConstraint)
+
+	_, err = strg.Alias().Create(ctx, models.CreateAlias{Entity: "country", EntityId: country.Guid, Title: "Hindustan"})
+	mustBe(t, err, storage.ErrConstraint)
+
+	_, err = strg.Alias().Create(ctx, models.CreateAlias{Entity: "city", EntityId: city.Guid, Title: "Bambai"})
+	mustNot(t, err)
+
+	list, err := strg.Alias().GetList(ctx, models.GetListAliasRequest{Entity: "city", EntityId: city.Guid})
+	mustNot(t, err)
+	if list.Count != 2 || len(list.Aliases) != 2 || list.Aliases[0].Title != "Bambai" || list.Aliases[1].Title != "Bombay" {
+		t.Errorf("GetList = %+v, want Bambai and Bombay", list)
+	}
+
+	_, err = strg.Alias().Delete(ctx, models.AliasPrimaryKey{Guid: created.Guid})
+	mustNot(t, err)
+
+	_, err = strg.Alias().GetById(ctx, models.AliasPrimaryKey{Guid: created.Guid})
+	mustBe(t, err, storage.ErrNotFound)
+
+	_, err = strg.Alias().Delete(ctx, models.AliasPrimaryKey{Guid: created.Guid})
+	mustBe(t, err, storage.ErrNotFound)
+}
+
+func testDeleteNames(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	country := createCountry(t, strg)
+	city := createCity(t, strg, country.Guid, "Mumbai")
+	airport := createAirport(t, strg, city, "Chhatrapati Shivaji", "BOM")
+	other := createCity(t, strg, country.Guid, "Delhi")
+
+	for _, name := range []struct{ entity, id string }{
+		{"country", country.Guid},
+		{"city", city.Guid},
+		{"airport", airport.Guid},
+		{"city", other.Guid},
+	} {
+		_, err := strg.Translation().Create(ctx, models.CreateTranslation{Entity: name.entity, EntityId: name.id, Locale: "ru", Title: "Название"})
+		mustNot(t, err)
+
+		if name.entity != "country" {
+			_, err = strg.Alias().Create(ctx, models.CreateAlias{Entity: name.entity, EntityId: name.id, Title: "Alias"})
+			mustNot(t, err)
+		}
+	}
+
+	_, err := strg.Airport().Delete(ctx, models.AirportPrimaryKey{Guid: airport.Guid})
+	mustNot(t, err)
+	_, err = strg.City().Delete(ctx, models.CityPrimaryKey{Guid: city.Guid})
+	mustNot(t, err)
+
+	translations, err := strg.Translation().GetList(ctx, models.GetListTranslationRequest{})
+	mustNot(t, err)
+	if translations.Count != 2 {
+		t.Errorf("after deleting the city and airport %d translations are left, want 2", translations.Count)
+	}
+
+	aliases, err := strg.Alias().GetList(ctx, models.GetListAliasRequest{})
+	mustNot(t, err)
+	if aliases.Count != 1 || len(aliases.Aliases) != 1 || aliases.Aliases[0].EntityId != other.Guid {
+		t.Errorf("after deleting the city and airport aliases are %+v, want the other city's", aliases)
+	}
+
+	found, err := strg.City().GetList(ctx, models.GetListCityRequest{Search: "Alias"})
+	mustNot(t, err)
+	if found.Count != 1 {
+		t.Errorf("search by alias found %d cities, want 1", found.Count)
+	}
+
+	_, err = strg.Country().Delete(ctx, models.CountryPrimaryKey{Guid: country.Guid})
+	mustNot(t, err)
+
+	translations, err = strg.Translation().GetList(ctx, models.GetListTranslationRequest{Entity: "country"})
+	mustNot(t, err)
+	if translations.Count != 0 {
+		t.Errorf("after deleting the country %d of its translations are left", translations.Count)
+	}
+}
+
+func testApiKey(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	created, err := strg.ApiKey().Create(ctx, models.CreateApiKey{Name: "ci", Role: "editor", Prefix: "et_abcdefgh", Hash: strings.Repeat("a", 64)})
+	mustNot(t, err)
+	if !isUUID(created.Guid) || created.Name != "ci" || created.Role != "editor" || created.Prefix != "et_abcdefgh" || len(created.CreatedAt) == 0 || len(created.RevokedAt) > 0 {
+		t.Fatalf("Create returned %+v", created)
+	}
+
+	got, err := strg.ApiKey().GetByHash(ctx, models.ApiKeyHash{Hash: strings.Repeat("a", 64)})
+	mustNot(t, err)
+	if *got != *created {
+		t.Errorf("GetByHash = %+v, want %+v", got, created)
+	}
+
+	_, err = strg.ApiKey().GetByHash(ctx, models.ApiKeyHash{Hash: strings.Repeat("b", 64)})
+	mustBe(t, err, storage.ErrNotFound)
+
+	_, err = strg.ApiKey().Create(ctx, models.CreateApiKey{Name: "copy", Role: "reader", Prefix: "et_abcdefgh", Hash: strings.Repeat("a", 64)})
+	mustBe(t, err, storage.ErrConstraint)
+
+	_, err = strg.ApiKey().Create(ctx, models.CreateApiKey{Name: "root", Role: "root", Prefix: "et_bcdefghi", Hash: strings.Repeat("b", 64)})
+	mustBe(t, err, storage.ErrConstraint)
+
+	other, err := strg.ApiKey().Create(ctx, models.CreateApiKey{Name: "ops", Role: "admin", Prefix: "et_cdefghij", Hash: strings.Repeat("c", 64)})
+	mustNot(t, err)
+
+	revoked, err := strg.ApiKey().Revoke(ctx, models.ApiKeyPrimaryKey{Guid: created.Guid})
+	mustNot(t, err)
+	if len(revoked.RevokedAt) == 0 {
+		t.Errorf("Revoke returned %+v, want revoked_at", revoked)
+	}
+
+	again, err := strg.ApiKey().Revoke(ctx, models.ApiKeyPrimaryKey{Guid: created.Guid})
+	mustNot(t, err)
+	if again.RevokedAt != revoked.RevokedAt {
+		t.Errorf("revoking again moved revoked_at from %q to %q", revoked.RevokedAt, again.RevokedAt)
+	}
+
+	got, err = strg.ApiKey().GetByHash(ctx, models.ApiKeyHash{Hash: strings.Repeat("a", 64)})
+	mustNot(t, err)
+	if got.RevokedAt != revoked.RevokedAt {
+		t.Errorf("GetByHash = %+v, want revoked", got)
+	}
+
+	list, err := strg.ApiKey().GetList(ctx, models.GetListApiKeyRequest{})
+	mustNot(t, err)
+	if list.Count != 1 || len(list.ApiKeys) != 1 || list.ApiKeys[0].Guid != other.Guid {
+		t.Errorf("GetList = %+v, want only ops", list)
+	}
+
+	list, err = strg.ApiKey().GetList(ctx, models.GetListApiKeyRequest{IncludeRevoked: true})
+	mustNot(t, err)
+	if list.Count != 2 || len(list.ApiKeys) != 2 {
+		t.Errorf("GetList with revoked = %+v, want ci and ops", list)
+	}
+
+	_, err = strg.ApiKey().Revoke(ctx, models.ApiKeyPrimaryKey{Guid: uuid.New().String()})
+	mustBe(t, err, storage.ErrNotFound)
+}
+
+func testUser(t *testing.T, strg storage.StorageI) {
+	ctx := context.Background()
+
+	created, err := strg.User().Create(ctx, models.CreateUser{Login: "alisher", Email: "alisher@example.com", Phone: "+998901234567", Role: "reader", PasswordHash: "hash"})
+	mustNot(t, err)
+	if !isUUID(created.Guid) || created.Login != "alisher" || created.Email != "alisher@example.com" || created.Phone != "+998901234567" || created.Role != "reader" || created.PasswordHash != "hash" {
+		t.Fatalf("Create returned %+v", created)
+	}
+
+	for _, login := range []string{"alisher", "alisher@example.com"} {
+		got, err := strg.User().GetByLogin(ctx, models.UserLoginKey{Login: login})
+		mustNot(t, err)
+		if *got != *created {
+			t.Errorf("GetByLogin(%q) = %+v, want %+v", login, got, created)
+		}
+	}
+
+	_, err = strg.User().GetByLogin(ctx, models.UserLoginKey{Login: "bobur"})
+	mustBe(t, err, storage.ErrNotFound)
+
+	_, err = strg.User().Create(ctx, models.CreateUser{Login: "alisher", Email: "other@example.com", Role: "reader", PasswordHash: "hash"})
+	mustBe(t, err, storage.ErrConstraint)
+
+	_, err = strg.User().Create(ctx, models.CreateUser{Login: "bobur_1", Email: "alisher@example.com", Role: "reader", PasswordHash: "hash"})
+	mustBe(t, err, storage.ErrConstraint)
+
+	_, err = strg.User().Create(ctx, models.CreateUser{Login: "bobur_1", Email: "bobur@example.com", Role: "root", PasswordHash: "hash"})
+	mustBe(t, err, storage.ErrConstraint)
+
+	other, err := strg.User().Create(ctx, models.CreateUser{Login: "bobur_1", Email: "bobur@example.com", FullName: "Bobur", Role: "reader", PasswordHash: "hash"})
+	mustNot(t, err)
+
+	updated, err := strg.User().Update(ctx, models.UpdateUser{Guid: created.Guid, Email: "a@example.com", FullName: "Alisher Navoiy"})
+	mustNot(t, err)
+	if updated.Email != "a@example.com" || updated.Phone != "" || updated.FullName != "Alisher Navoiy" || updated.Login != "alisher" || len(updated.UpdatedAt) == 0 {
+		t.Errorf("Update returned %+v", updated)
+	}
+
+	_, err = strg.User().Update(ctx, models.UpdateUser{Guid: created.Guid, Email: other.Email})
+	mustBe(t, err, storage.ErrConstraint)
+
+	updated, err = strg.User().UpdatePassword(ctx, models.UpdateUserPassword{Guid: created.Guid, PasswordHash: "new hash"})
+	mustNot(t, err)
+	if updated.PasswordHash != "new hash" || updated.Email != "a@example.com" || updated.TokenVersion != created.TokenVersion+1 {
+		t.Errorf("UpdatePassword returned %+v", updated)
+	}
+
+	// A new role reaches the tokens on their next refresh, so they stay
+	// valid.
+	tokenVersion := updated.TokenVersion
+	updated, err = strg.User().UpdateRole(ctx, models.UpdateUserRole{Guid: created.Guid, Role: "editor"})
+	mustNot(t, err)
+	if updated.Role != "editor" || updated.TokenVersion != tokenVersion {
+		t.Errorf("UpdateRole returned %+v", updated)
+	}
+
+	_, err = strg.User().UpdateRole(ctx, models.UpdateUserRole{Guid: created.Guid, Role: "root"})
+	mustBe(t, err, storage.ErrConstraint)
+
+	_, err = strg.User().UpdateRole(ctx, models.UpdateUserRole{Guid: uuid.New().String(), Role: "editor"})
+	mustBe(t, err, storage.ErrNotFound)
+
+	list, err := strg.User().GetList(ctx, models.GetListUserRequest{})
+	mustNot(t, err)
+	if list.Count != 2 || len(list.Users) != 2 || list.Users[0].Login != "alisher" || list.Users[1].Login != "bobur_1" || list.Users[0].PasswordHash != "" {
+		t.Errorf("GetList = %+v, want alisher and bobur_1 without hashes", list)
+	}
+
+	list, err = strg.User().GetList(ctx, models.GetListUserRequest{Search: "bob"})
+	mustNot(t, err)
+	if list.Count != 1 || len(list.Users) != 1 || list.Users[0].Guid != other.Guid {
+		t.Errorf("GetList(bob) = %+v, want bobur_1", list)
+	}
+}
+
+func testContext(t *testing.T, strg storage.StorageI) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := strg.Country().GetList(cancelled, models.GetListCountryRequest{})
+	mustBe(t, err, context.Canceled)
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	_, err = strg.Country().Create(expired, models.CreateCountry{Title: "Uzbekistan"})
+	mustBe(t, err, storage.ErrTimeout)
+}
+
+func createCountry(t *testing.T, strg storage.StorageI) *models.Country {
+	t.Helper()
+
+	country, err := strg.Country().Create(context.Background(), models.CreateCountry{Title: "Uzbekistan", Code: "UZ", Continent: "Asia"})
+	mustNot(t, err)
+
+	return country
+}
+
+func createCity(t *testing.T, strg storage.StorageI, countryId, title string) *models.City {
+	t.Helper()
+
+	city, err := strg.City().Create(context.Background(), models.CreateCity{Title: title, CountryId: countryId})
+	mustNot(t, err)
+
+	return city
+}
+
+func createAirport(t *testing.T, strg storage.StorageI, city *models.City, title, code string) *models.Airport {
+	t.Helper()
+
+	airport, err := strg.Airport().Create(context.Background(), models.CreateAirport{
+		Title:     title,
+		CountryId: city.CountryId,
+		CityId:    city.Guid,
+		Code:      code,
+	})
+	mustNot(t, err)
+
+	return airport
+}
+
+func findCity(t *testing.T, strg storage.StorageI, title string) models.City {
+	t.Helper()
+
+	resp, err := strg.City().GetList(context.Background(), models.GetListCityRequest{Search: title})
+	mustNot(t, err)
+
+	for _, city := range resp.Cities {
+		if city.Title == title {
+			return city
+		}
+	}
+
+	t.Fatalf("city %q not found", title)
+	return models.City{}
+}
+
+func mustNot(t *testing.T, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func mustBe(t *testing.T, err, target error) {
+	t.Helper()
+
+	if !errors.Is(err, target) {
+		t.Fatalf("got error %v, want %v", err, target)
+	}
+}
+
+func isUUID(s string) bool {
+	_, err := uuid.Parse(s)
+	return err == nil
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// equalSets compares got and want ignoring order, for lists the storage
+// does not promise to sort.
+func equalSets(t *testing.T, got, want []string) {
+	t.Helper()
+
+	got = append([]string(nil), got...)
+	want = append([]string(nil), want...)
+	sort.Strings(got)
+	sort.Strings(want)
+
+	if !equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}